@@ -26,6 +26,7 @@ type AgentConfig struct {
 	WorklogFilename   string
 	ProjectName       string
 	WorkspaceDir      string
+	AgentRegistryFile string // optional JSON agent registry (AGENT_REGISTRY_FILE)
 	GitHubToken       string
 	GitUserName       string
 	GitUserEmail      string
@@ -131,6 +132,7 @@ func FromEnv() (AgentConfig, error) {
 		WorklogFilename:   "worklog.md",
 		ProjectName:       project,
 		WorkspaceDir:      workspace,
+		AgentRegistryFile: strings.TrimSpace(os.Getenv("AGENT_REGISTRY_FILE")),
 		GitHubToken:       githubToken,
 		GitUserName:       gitUserName,
 		GitUserEmail:      gitUserEmail,
@@ -0,0 +1,351 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	b "dev_agent/internal/brain"
+	"dev_agent/internal/logx"
+
+	t "dev_agent/internal/tools"
+)
+
+// completeFunc requests one assistant message for the conversation so far.
+type completeFunc func(messages []b.ChatMessage, tools []map[string]any) (b.ChatMessage, error)
+
+func brainCompleter(brain *b.LLMBrain) completeFunc {
+	return func(messages []b.ChatMessage, tools []map[string]any) (b.ChatMessage, error) {
+		resp, err := brain.Complete(messages, tools)
+		if err != nil {
+			return b.ChatMessage{}, err
+		}
+		if len(resp.Choices) == 0 {
+			return b.ChatMessage{}, errors.New("LLM response contained no choices")
+		}
+		return resp.Choices[0].Message, nil
+	}
+}
+
+// engine runs the Implement/Review/Fix turn loop and the publish hand-off.
+// Orchestrate and ChatLoop differ only in the observers they register.
+type engine struct {
+	complete   completeFunc
+	handler    publishHandler
+	tools      []map[string]any
+	publish    PublishOptions
+	maxReviews int
+	obs        observerList
+
+	messages       []b.ChatMessage
+	iteration      int
+	totalToolCalls int
+	nextItem       int
+}
+
+func newEngine(complete completeFunc, handler publishHandler, messages []b.ChatMessage, opts RunOptions) *engine {
+	maxReviews := opts.MaxReviews
+	if maxReviews <= 0 {
+		maxReviews = maxIterations
+	}
+	var obs observerList
+	if so := NewStreamObserver(opts.Streamer); so != nil {
+		obs = append(obs, so)
+	}
+	for _, o := range opts.Observers {
+		if o != nil {
+			obs = append(obs, o)
+		}
+	}
+	return &engine{
+		complete:   complete,
+		handler:    handler,
+		tools:      t.GetToolDefinitions(),
+		publish:    opts.Publish,
+		maxReviews: maxReviews,
+		obs:        obs,
+		messages:   messages,
+	}
+}
+
+// Orchestrate runs the workflow headless; progress goes to logx and the
+// configured observers.
+func Orchestrate(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMessage, opts RunOptions) (map[string]any, error) {
+	return newEngine(brainCompleter(brain), handler, messages, opts).run()
+}
+
+// ChatLoop runs the same workflow as Orchestrate with a console transcript
+// on stdout. maxIters overrides opts.MaxReviews when positive.
+func ChatLoop(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMessage, maxIters int, opts RunOptions) (map[string]any, error) {
+	if maxIters > 0 {
+		opts.MaxReviews = maxIters
+	}
+	opts.Observers = append([]Observer{NewConsoleObserver(nil)}, opts.Observers...)
+	return newEngine(brainCompleter(brain), handler, messages, opts).run()
+}
+
+func (e *engine) turn() Turn {
+	return Turn{
+		ID:           fmt.Sprintf("turn_%d", e.iteration),
+		Iteration:    e.iteration,
+		MessageCount: len(e.messages),
+		ToolCalls:    e.totalToolCalls,
+		Messages:     e.messages,
+	}
+}
+
+func (e *engine) newItemID() string {
+	e.nextItem++
+	return fmt.Sprintf("item_%d", e.nextItem)
+}
+
+func (e *engine) run() (map[string]any, error) {
+	var (
+		finalReport map[string]any
+		finished    bool
+		errorState  bool
+		reviewCount int
+	)
+
+	for {
+		e.iteration++
+		logx.Infof("LLM iteration %d", e.iteration)
+		e.obs.TurnStarted(e.turn())
+		choice, err := e.complete(e.messages, e.tools)
+		if err != nil {
+			e.obs.Error("llm.complete", err.Error(), map[string]any{"iteration": e.iteration, "turn_id": e.turn().ID})
+			return nil, err
+		}
+		e.messages = append(e.messages, assistantMessageToDict(choice))
+		e.obs.AssistantMessage(e.turn(), choice)
+
+		if len(choice.ToolCalls) > 0 {
+			turnToolCount := 0
+			reviewCompleted := false
+			stopDueToInstruction := false
+			for _, tc := range choice.ToolCalls {
+				turnToolCount++
+				e.totalToolCalls++
+				ev := &ToolEvent{
+					ItemID:  e.newItemID(),
+					Kind:    "tool_call",
+					Name:    tc.Function.Name,
+					RawArgs: tc.Function.Arguments,
+					Args:    parseToolArgs(tc.Function.Arguments),
+				}
+				e.obs.ToolStarted(e.turn(), ev)
+				htc := t.ToolCall{ID: tc.ID, Type: tc.Type}
+				htc.Function.Name = tc.Function.Name
+				htc.Function.Arguments = tc.Function.Arguments
+				start := time.Now()
+				result := e.handler.Handle(htc)
+				ev.Duration = time.Since(start)
+				e.messages = append(e.messages, b.ChatMessage{Role: "tool", ToolCallID: tc.ID, Content: toJSON(result)})
+				ev.Result = result
+				ev.Status = resultStatus(result)
+				ev.BranchID = eventBranchID(result)
+				ev.Summary = summarizeToolResult(result)
+				e.obs.ToolCompleted(e.turn(), ev)
+
+				if instr, summaryMsg, details := toolInstruction(result); instr != "" {
+					e.obs.Error("tool_instruction", summaryMsg, map[string]any{"instruction": instr})
+					finalReport = buildErrorFinalReport(e.publish.Task, summaryMsg, instr, details)
+					finished = true
+					errorState = true
+					stopDueToInstruction = true
+					break
+				}
+
+				if tc.Function.Name == "execute_agent" && isCriticResult(result) {
+					reviewCompleted = true
+				}
+			}
+			e.obs.TurnCompleted(e.turn(), turnToolCount, false)
+			if stopDueToInstruction {
+				break
+			}
+			if reviewCompleted {
+				reviewCount++
+				logx.Infof("Completed review iteration %d/%d", reviewCount, e.maxReviews)
+				e.obs.Note(e.turn(), fmt.Sprintf("completed review iteration %d/%d", reviewCount, e.maxReviews))
+				if reviewCount >= e.maxReviews {
+					logx.Errorf("Reached review iteration limit without final report.")
+					break
+				}
+			}
+			continue
+		}
+
+		hasFinal := false
+		if fr, ok := ParseFinalReport(choice); ok {
+			finalReport = fr
+			finished = true
+			hasFinal = true
+		} else {
+			logx.Infof("Assistant response was not a final report; continuing.")
+		}
+		e.obs.TurnCompleted(e.turn(), 0, hasFinal)
+		if finished {
+			break
+		}
+	}
+
+	if finished {
+		if errorState {
+			ensureReportDefaults(finalReport, e.publish.Task, statusFinishedWithError, true)
+			e.obs.Finished(finalReport)
+			return finalReport, nil
+		}
+		ensureReportDefaults(finalReport, e.publish.Task, statusCompleted, true)
+		if _, err := e.runPublish(finalReport, true); err != nil {
+			e.obs.Error("publish", err.Error(), nil)
+			return nil, err
+		}
+		e.obs.Finished(finalReport)
+		return finalReport, nil
+	}
+
+	finalReport = map[string]any{
+		"is_finished": false,
+		"status":      statusIterationLimit,
+		"task":        e.publish.Task,
+		"summary":     iterationLimitSummary,
+	}
+	branchID, err := e.runPublish(finalReport, false)
+	if err != nil {
+		e.obs.Error("publish", err.Error(), nil)
+		return nil, err
+	}
+	if branchID != "" {
+		logx.Infof("Workspace published to branch (branch_id=%s) after iteration limit.", branchID)
+		e.obs.Note(e.turn(), fmt.Sprintf("workspace pushed (branch_id=%s)", branchID))
+	}
+	e.obs.Finished(finalReport)
+	return finalReport, nil
+}
+
+// runPublish wraps the publish hand-off in its own turn.
+func (e *engine) runPublish(report map[string]any, success bool) (string, error) {
+	e.iteration++
+	e.obs.TurnStarted(e.turn())
+	branchID, err := e.finalizeBranchPush(report, success)
+	e.totalToolCalls++
+	e.obs.TurnCompleted(e.turn(), 1, false)
+	return branchID, err
+}
+
+// finalizeBranchPush asks codex to commit and push the workspace from the
+// latest branch in the lineage and records its publish report.
+func (e *engine) finalizeBranchPush(report map[string]any, success bool) (string, error) {
+	handler, opts := e.handler, e.publish
+	lineage := handler.BranchRange()
+	parent := lineage["latest_branch_id"]
+	if parent == "" {
+		parent = opts.ParentBranchID
+	}
+	if parent == "" {
+		return "", errors.New("unable to determine parent branch id for publish step")
+	}
+
+	outcome := iterationLimitSummary
+	if success {
+		summary := ""
+		if report != nil {
+			if s, ok := report["summary"].(string); ok && s != "" {
+				summary = s
+			}
+		}
+		if summary != "" {
+			outcome = summary
+		} else {
+			outcome = defaultSuccessSummary
+		}
+	}
+
+	meta := fmt.Sprintf("commit-meta: start_branch=%s latest_branch=%s", lineage["start_branch_id"], lineage["latest_branch_id"])
+	prompt := renderPrompt(tmplPublish, PublishPromptData{
+		Task:         opts.Task,
+		Outcome:      outcome,
+		Meta:         meta,
+		WorkspaceDir: opts.WorkspaceDir,
+	})
+
+	logx.Infof("Finalizing workflow by asking codex to push from branch %s lineage.", parent)
+	execArgs := map[string]any{
+		"agent":            "codex",
+		"prompt":           prompt,
+		"parent_branch_id": parent,
+	}
+	if opts.ProjectName != "" {
+		execArgs["project_name"] = opts.ProjectName
+	}
+	argsBytes, _ := json.Marshal(execArgs)
+	execCall := t.ToolCall{Type: "function"}
+	execCall.Function.Name = "execute_agent"
+	execCall.Function.Arguments = string(argsBytes)
+
+	args := map[string]any{
+		"agent":            "codex",
+		"parent_branch_id": parent,
+	}
+	if opts.ProjectName != "" {
+		args["project_name"] = opts.ProjectName
+	}
+	ev := &ToolEvent{ItemID: e.newItemID(), Kind: "publish", Name: "publish", Args: args}
+	e.obs.ToolStarted(e.turn(), ev)
+	start := time.Now()
+	execResp := handler.Handle(execCall)
+	ev.Duration = time.Since(start)
+
+	data, _ := execResp["data"].(map[string]any)
+	branchID := t.ExtractBranchID(data)
+	if branchID == "" {
+		branchID = t.ExtractBranchID(execResp)
+	}
+	publishSummary := extractBranchOutput(data)
+	if publishSummary == "" {
+		publishSummary = summarizeToolResult(execResp)
+	}
+
+	status := resultStatus(execResp)
+	ev.Result = execResp
+	ev.Status = status
+	ev.BranchID = branchID
+	ev.Summary = publishSummary
+	e.obs.ToolCompleted(e.turn(), ev)
+
+	if status != "success" {
+		return "", fmt.Errorf("publish execute_agent failed: %v", execResp)
+	}
+	if branchID == "" {
+		return "", errors.New("publish execute_agent missing branch id")
+	}
+	if publishSummary == "" {
+		logx.Warningf("Publish response missing required report (repo/branch/commit/tests); continuing without it (branch_id=%s)", branchID)
+		publishSummary = fmt.Sprintf("Publish report unavailable; inspect Pantheon branch %s for push details.", branchID)
+	}
+	if report != nil {
+		report["publish_report"] = publishSummary
+	}
+	if branchStatus := strings.TrimSpace(fmt.Sprintf("%v", data["status"])); branchStatus != "" {
+		switch strings.ToLower(branchStatus) {
+		case "failed":
+			return "", fmt.Errorf("publish branch %s completed with failure status", branchID)
+		}
+	}
+
+	return branchID, nil
+}
+
+// isCriticResult reports whether a successful execute_agent result came from
+// an agent registered with the critic role.
+func isCriticResult(result map[string]any) bool {
+	if status, _ := result["status"].(string); status != "success" {
+		return false
+	}
+	data, _ := result["data"].(map[string]any)
+	role, _ := data["agent_role"].(string)
+	return role == string(t.AgentRoleCritic)
+}
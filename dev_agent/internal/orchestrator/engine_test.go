@@ -0,0 +1,193 @@
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	b "dev_agent/internal/brain"
+	t "dev_agent/internal/tools"
+)
+
+type scriptedCompleter struct {
+	replies []b.ChatMessage
+	calls   int
+}
+
+func (s *scriptedCompleter) complete(_ []b.ChatMessage, _ []map[string]any) (b.ChatMessage, error) {
+	if s.calls >= len(s.replies) {
+		return b.ChatMessage{}, fmt.Errorf("unexpected completion %d", s.calls+1)
+	}
+	reply := s.replies[s.calls]
+	s.calls++
+	return reply, nil
+}
+
+type fakeEngineHandler struct {
+	calls []t.ToolCall
+}
+
+func (f *fakeEngineHandler) BranchRange() map[string]string {
+	return map[string]string{"start_branch_id": "branch-1", "latest_branch_id": fmt.Sprintf("branch-%d", len(f.calls))}
+}
+
+func (f *fakeEngineHandler) Handle(call t.ToolCall) map[string]any {
+	f.calls = append(f.calls, call)
+	id := fmt.Sprintf("branch-%d", len(f.calls))
+	role := t.AgentRoleBuilder
+	if strings.Contains(call.Function.Arguments, `"review_code"`) {
+		role = t.AgentRoleCritic
+	}
+	return map[string]any{
+		"status": "success",
+		"data": map[string]any{
+			"branch_id":  id,
+			"branch":     map[string]any{"id": id, "output": "done " + id},
+			"agent_role": string(role),
+		},
+	}
+}
+
+type recordingObserver struct {
+	NopObserver
+	events []string
+	final  map[string]any
+}
+
+func (r *recordingObserver) TurnStarted(turn Turn) {
+	r.events = append(r.events, "turn_started:"+turn.ID)
+}
+
+func (r *recordingObserver) ToolStarted(_ Turn, ev *ToolEvent) {
+	r.events = append(r.events, "tool_started:"+ev.Kind+":"+ev.Name)
+}
+
+func (r *recordingObserver) ToolCompleted(_ Turn, ev *ToolEvent) {
+	r.events = append(r.events, "tool_completed:"+ev.ItemID+":"+ev.Status+":"+ev.BranchID)
+}
+
+func (r *recordingObserver) TurnCompleted(turn Turn, toolCalls int, hasFinal bool) {
+	r.events = append(r.events, fmt.Sprintf("turn_completed:%s:%d:%v", turn.ID, toolCalls, hasFinal))
+}
+
+func (r *recordingObserver) Finished(report map[string]any) {
+	r.final = report
+}
+
+func agentCall(id, agent string) b.ChatMessage {
+	args, _ := json.Marshal(map[string]any{"agent": agent, "prompt": "do it", "parent_branch_id": "p"})
+	call := b.ToolCall{ID: id, Type: "function"}
+	call.Function.Name = "execute_agent"
+	call.Function.Arguments = string(args)
+	return b.ChatMessage{Role: "assistant", ToolCalls: []b.ToolCall{call}}
+}
+
+func workflowScript() *scriptedCompleter {
+	return &scriptedCompleter{replies: []b.ChatMessage{
+		agentCall("call_1", "codex"),
+		agentCall("call_2", "review_code"),
+		{Role: "assistant", Content: `{"is_finished": true, "summary": "all good"}`},
+	}}
+}
+
+func TestEngineRunsWorkflowAndPublishes(t *testing.T) {
+	script := workflowScript()
+	handler := &fakeEngineHandler{}
+	rec := &recordingObserver{}
+	opts := RunOptions{Publish: PublishOptions{Task: "fix it", WorkspaceDir: "/ws"}, Observers: []Observer{rec}}
+
+	report, err := newEngine(script.complete, handler, nil, opts).run()
+	if err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	if report["status"] != statusCompleted || report["task"] != "fix it" {
+		t.Fatalf("unexpected report %#v", report)
+	}
+	if report["publish_report"] != "done branch-3" {
+		t.Fatalf("expected publish report from publish branch, got %#v", report["publish_report"])
+	}
+	if len(handler.calls) != 3 {
+		t.Fatalf("expected 2 tool calls plus publish, got %d", len(handler.calls))
+	}
+	want := []string{
+		"turn_started:turn_1", "tool_started:tool_call:execute_agent", "tool_completed:item_1:success:branch-1", "turn_completed:turn_1:1:false",
+		"turn_started:turn_2", "tool_started:tool_call:execute_agent", "tool_completed:item_2:success:branch-2", "turn_completed:turn_2:1:false",
+		"turn_started:turn_3", "turn_completed:turn_3:0:true",
+		"turn_started:turn_4", "tool_started:publish:publish", "tool_completed:item_3:success:branch-3", "turn_completed:turn_4:1:false",
+	}
+	if strings.Join(rec.events, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected event sequence:\n%s", strings.Join(rec.events, "\n"))
+	}
+	if rec.final == nil {
+		t.Fatalf("expected Finished to receive the report")
+	}
+}
+
+func TestEngineStopsAtReviewLimit(t *testing.T) {
+	script := &scriptedCompleter{replies: []b.ChatMessage{
+		agentCall("call_1", "review_code"),
+		agentCall("call_2", "review_code"),
+	}}
+	handler := &fakeEngineHandler{}
+	opts := RunOptions{Publish: PublishOptions{Task: "fix it"}, MaxReviews: 2}
+
+	report, err := newEngine(script.complete, handler, nil, opts).run()
+	if err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	if report["status"] != statusIterationLimit {
+		t.Fatalf("expected iteration limit, got %#v", report["status"])
+	}
+	if len(handler.calls) != 3 {
+		t.Fatalf("expected publish after the review limit, got %d calls", len(handler.calls))
+	}
+}
+
+func TestConsoleObserverMatchesInteractiveTranscript(t *testing.T) {
+	var out bytes.Buffer
+	opts := RunOptions{Publish: PublishOptions{Task: "fix it"}, Observers: []Observer{NewConsoleObserver(&out)}}
+	if _, err := newEngine(workflowScript().complete, &fakeEngineHandler{}, nil, opts).run(); err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	got := out.String()
+	for _, needle := range []string{
+		"[iter 1] requesting completion...",
+		"tool> execute_agent ",
+		"tool< {",
+		"note: completed review iteration 1/8",
+		"assistant< final_report",
+		"publish< status=success branch_id=branch-3",
+	} {
+		if !strings.Contains(got, needle) {
+			t.Fatalf("console transcript missing %q:\n%s", needle, got)
+		}
+	}
+}
+
+func TestCheckpointObserverWritesConversation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "checkpoint.json")
+	opts := RunOptions{Publish: PublishOptions{Task: "fix it"}, Observers: []Observer{NewCheckpointObserver(path)}}
+	initial := []b.ChatMessage{{Role: "system", Content: "sys"}}
+	if _, err := newEngine(workflowScript().complete, &fakeEngineHandler{}, initial, opts).run(); err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read checkpoint: %v", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		t.Fatalf("decode checkpoint: %v", err)
+	}
+	if !cp.Finished || cp.Report["status"] != statusCompleted {
+		t.Fatalf("expected finished checkpoint with report, got %+v", cp)
+	}
+	// system + 2x(assistant, tool) + final assistant
+	if len(cp.Messages) != 6 {
+		t.Fatalf("expected 6 messages in checkpoint, got %d", len(cp.Messages))
+	}
+}
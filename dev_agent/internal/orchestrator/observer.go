@@ -0,0 +1,302 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	b "dev_agent/internal/brain"
+	"dev_agent/internal/logx"
+	"dev_agent/internal/streaming"
+)
+
+const consoleToolResultLimit = 2000
+
+// Turn describes the engine state at a turn boundary. Messages is the live
+// conversation; observers must not modify it.
+type Turn struct {
+	ID           string
+	Iteration    int
+	MessageCount int
+	ToolCalls    int // total tool calls issued so far, across all turns
+	Messages     []b.ChatMessage
+}
+
+// ToolEvent describes one tool invocation. Kind is "tool_call" for calls
+// requested by the LLM and "publish" for the engine's own publish step.
+// Result, Status, BranchID, Summary and Duration are set before
+// ToolCompleted fires.
+type ToolEvent struct {
+	ItemID   string
+	Kind     string
+	Name     string
+	RawArgs  string
+	Args     map[string]any
+	Result   map[string]any
+	Status   string
+	BranchID string
+	Summary  string
+	Duration time.Duration
+}
+
+// Observer receives engine events. Callbacks run synchronously on the engine
+// goroutine, in order, so implementations should return quickly.
+type Observer interface {
+	TurnStarted(turn Turn)
+	AssistantMessage(turn Turn, msg b.ChatMessage)
+	ToolStarted(turn Turn, ev *ToolEvent)
+	ToolCompleted(turn Turn, ev *ToolEvent)
+	TurnCompleted(turn Turn, toolCalls int, hasFinal bool)
+	// Note carries human-oriented progress messages (review counts, policy
+	// notices) that have no structured event.
+	Note(turn Turn, message string)
+	Error(scope, message string, extra map[string]any)
+	// Finished fires once with the final report when the run returns
+	// without error.
+	Finished(report map[string]any)
+}
+
+// NopObserver implements Observer with no-ops; embed it to handle only the
+// events you care about.
+type NopObserver struct{}
+
+func (NopObserver) TurnStarted(Turn)                     {}
+func (NopObserver) AssistantMessage(Turn, b.ChatMessage) {}
+func (NopObserver) ToolStarted(Turn, *ToolEvent)         {}
+func (NopObserver) ToolCompleted(Turn, *ToolEvent)       {}
+func (NopObserver) TurnCompleted(Turn, int, bool)        {}
+func (NopObserver) Note(Turn, string)                    {}
+func (NopObserver) Error(string, string, map[string]any) {}
+func (NopObserver) Finished(map[string]any)              {}
+
+type observerList []Observer
+
+func (l observerList) TurnStarted(turn Turn) {
+	for _, o := range l {
+		o.TurnStarted(turn)
+	}
+}
+
+func (l observerList) AssistantMessage(turn Turn, msg b.ChatMessage) {
+	for _, o := range l {
+		o.AssistantMessage(turn, msg)
+	}
+}
+
+func (l observerList) ToolStarted(turn Turn, ev *ToolEvent) {
+	for _, o := range l {
+		o.ToolStarted(turn, ev)
+	}
+}
+
+func (l observerList) ToolCompleted(turn Turn, ev *ToolEvent) {
+	for _, o := range l {
+		o.ToolCompleted(turn, ev)
+	}
+}
+
+func (l observerList) TurnCompleted(turn Turn, toolCalls int, hasFinal bool) {
+	for _, o := range l {
+		o.TurnCompleted(turn, toolCalls, hasFinal)
+	}
+}
+
+func (l observerList) Note(turn Turn, message string) {
+	for _, o := range l {
+		o.Note(turn, message)
+	}
+}
+
+func (l observerList) Error(scope, message string, extra map[string]any) {
+	for _, o := range l {
+		o.Error(scope, message, extra)
+	}
+}
+
+func (l observerList) Finished(report map[string]any) {
+	for _, o := range l {
+		o.Finished(report)
+	}
+}
+
+// ConsoleObserver prints a human-readable transcript, as used by the
+// interactive CLI.
+type ConsoleObserver struct {
+	w io.Writer
+}
+
+func NewConsoleObserver(w io.Writer) *ConsoleObserver {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &ConsoleObserver{w: w}
+}
+
+func (c *ConsoleObserver) TurnStarted(turn Turn) {
+	fmt.Fprintf(c.w, "[iter %d] requesting completion...\n", turn.Iteration)
+}
+
+func (c *ConsoleObserver) AssistantMessage(_ Turn, msg b.ChatMessage) {
+	if msg.Content != "" {
+		fmt.Fprintf(c.w, "assistant> %s\n", msg.Content)
+	}
+}
+
+func (c *ConsoleObserver) ToolStarted(_ Turn, ev *ToolEvent) {
+	if ev.Kind == "publish" {
+		fmt.Fprintf(c.w, "publish> %s\n", toJSON(ev.Args))
+		return
+	}
+	fmt.Fprintf(c.w, "tool> %s %s\n", ev.Name, ev.RawArgs)
+}
+
+func (c *ConsoleObserver) ToolCompleted(_ Turn, ev *ToolEvent) {
+	if ev.Kind == "publish" {
+		fmt.Fprintf(c.w, "publish< status=%s branch_id=%s\n", ev.Status, ev.BranchID)
+		return
+	}
+	js := toJSON(ev.Result)
+	if len(js) > consoleToolResultLimit {
+		js = js[:consoleToolResultLimit]
+	}
+	fmt.Fprintf(c.w, "tool< %s\n", js)
+}
+
+func (c *ConsoleObserver) TurnCompleted(_ Turn, toolCalls int, hasFinal bool) {
+	switch {
+	case hasFinal:
+		fmt.Fprintln(c.w, "assistant< final_report")
+	case toolCalls == 0:
+		fmt.Fprintln(c.w, "assistant< not final yet, continuing...")
+	}
+}
+
+func (c *ConsoleObserver) Note(_ Turn, message string) {
+	fmt.Fprintf(c.w, "note: %s\n", message)
+}
+
+func (c *ConsoleObserver) Error(scope, message string, _ map[string]any) {
+	fmt.Fprintf(c.w, "error: %s: %s\n", scope, message)
+}
+
+func (c *ConsoleObserver) Finished(map[string]any) {}
+
+// StreamObserver forwards engine events to an NDJSON streamer.
+type StreamObserver struct {
+	streamer *streaming.JSONStreamer
+}
+
+// NewStreamObserver returns nil when streaming is disabled so callers can
+// skip registering it.
+func NewStreamObserver(streamer *streaming.JSONStreamer) *StreamObserver {
+	if streamer == nil || !streamer.Enabled() {
+		return nil
+	}
+	return &StreamObserver{streamer: streamer}
+}
+
+func (s *StreamObserver) TurnStarted(turn Turn) {
+	s.streamer.EmitTurnStarted(turn.ID, turn.Iteration, turn.MessageCount, turn.ToolCalls)
+}
+
+func (s *StreamObserver) AssistantMessage(turn Turn, msg b.ChatMessage) {
+	s.streamer.EmitAssistantMessage(turn.ID, msg.Content, len(msg.ToolCalls))
+}
+
+func (s *StreamObserver) ToolStarted(_ Turn, ev *ToolEvent) {
+	args := ev.Args
+	if ev.Kind == "tool_call" {
+		args = sanitizeToolArgs(ev.Name, ev.Args)
+	}
+	s.streamer.EmitItemStarted(ev.ItemID, ev.Kind, ev.Name, args)
+}
+
+func (s *StreamObserver) ToolCompleted(_ Turn, ev *ToolEvent) {
+	s.streamer.EmitItemCompleted(ev.ItemID, ev.Status, ev.Duration, ev.BranchID, ev.Summary)
+}
+
+func (s *StreamObserver) TurnCompleted(turn Turn, toolCalls int, hasFinal bool) {
+	s.streamer.EmitTurnCompleted(turn.ID, turn.Iteration, toolCalls, hasFinal)
+}
+
+func (s *StreamObserver) Note(Turn, string) {}
+
+func (s *StreamObserver) Error(scope, message string, extra map[string]any) {
+	s.streamer.EmitError(scope, message, extra)
+}
+
+func (s *StreamObserver) Finished(map[string]any) {}
+
+// Checkpoint is the document written by CheckpointObserver.
+type Checkpoint struct {
+	UpdatedAt  time.Time       `json:"updated_at"`
+	TurnID     string          `json:"turn_id,omitempty"`
+	Iteration  int             `json:"iteration"`
+	ToolCalls  int             `json:"tool_calls"`
+	Finished   bool            `json:"finished"`
+	Messages   []b.ChatMessage `json:"messages"`
+	Report     map[string]any  `json:"report,omitempty"`
+	LastError  string          `json:"last_error,omitempty"`
+	ErrorScope string          `json:"error_scope,omitempty"`
+}
+
+// CheckpointObserver rewrites a JSON checkpoint of the conversation after
+// every completed turn and once more with the final report, so an
+// interrupted run can be inspected.
+type CheckpointObserver struct {
+	NopObserver
+	path string
+	cp   Checkpoint
+}
+
+func NewCheckpointObserver(path string) *CheckpointObserver {
+	return &CheckpointObserver{path: path}
+}
+
+func (c *CheckpointObserver) TurnCompleted(turn Turn, _ int, _ bool) {
+	c.cp.TurnID = turn.ID
+	c.cp.Iteration = turn.Iteration
+	c.cp.ToolCalls = turn.ToolCalls
+	c.cp.Messages = turn.Messages
+	c.write()
+}
+
+func (c *CheckpointObserver) Error(scope, message string, _ map[string]any) {
+	c.cp.ErrorScope = scope
+	c.cp.LastError = message
+	c.write()
+}
+
+func (c *CheckpointObserver) Finished(report map[string]any) {
+	c.cp.Finished = true
+	c.cp.Report = report
+	c.write()
+}
+
+func (c *CheckpointObserver) write() {
+	c.cp.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(c.cp, "", "  ")
+	if err != nil {
+		logCheckpointError(c.path, err)
+		return
+	}
+	tmp := c.path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		logCheckpointError(c.path, err)
+		return
+	}
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		logCheckpointError(c.path, err)
+		return
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		logCheckpointError(c.path, err)
+	}
+}
+
+func logCheckpointError(path string, err error) {
+	logx.Warningf("Writing checkpoint %s failed: %v", path, err)
+}
@@ -2,123 +2,15 @@ package orchestrator
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"os"
 	"strings"
-	"time"
 
 	b "dev_agent/internal/brain"
-	"dev_agent/internal/logx"
 	"dev_agent/internal/streaming"
 
 	t "dev_agent/internal/tools"
 )
 
-const systemPromptTemplate = `You are a expert software engineer, and a TDD (Test-Drive Development) workflow orchestrator.
-
-### Agents
-- **codex**: Analyze the requirement, Design and Implements solutions and tests. Summarizes work in '%[1]s/worklog.md'.
-- **review_code**: Reviews code for P0/P1 issues. Records findings in '%[1]s/code_review.log'.
-
-### Workflow
-1.  **Implement (codex)**: Implement the solution and matching tests for the user's task.
-2.  **Review (review_code)**: Review the implementation for P0/P1 issues.
-3.  **Fix (codex)**: If issues are found, fix all P0/P1 issues and ensure tests pass.
-4.  Repeat **Review** and **Fix** until 'review_code' agent reports no P0/P1 issues.
-
-### Your Orchestration Rules
-1.  **Single Call Per Turn**: Issue exactly one agent/tool call per assistant response; do not batch tool calls because each subsequent agent needs the prior branch's id to extend the branch lineage correctly.
-2.  **Call Agents**: For each workflow step, the agent is invoked through the 'execute_agent'.
-3.  **Maintain State**: Track branch lineage ('parent_branch_id') and report any tool errors immediately.
-4.  **Local-Only Before Publish**: Implement/Review/Fix phases are strictly local development. You may create/checkout branches and stage/commit locally, but you must **NOT** run 'git push' or create PRs (e.g., via 'gh pr create') in these phases.
-
-### Agent Prompt Templates
-
-Don't go into too much detail. You're just a TDD manager, clearly explain the tasks and let the agent analyze and execute them. So please Use the following prompt, Fill in the correct task and issues.
-Never hard-code absolute filesystem paths; derive locations relative to the repository or the configured workspace root (%[1]s).
-
----
-
-#### Implement (codex)
-
-You are an expert engineer. Your goal is to produce high-quality, verified code based on deep analysis.
-Before you start coding: Read as much as you can, you have unlimited read quotas and available contexts. When you are not sure about something, you must study the code until you figure out.
-
-**User Task**: [The user's original task description - must be passed on exactly as is]
-
-**Instructions**:
-
-1.  **Phase 0: Context Verification (CRITICAL)**
-    * Identify the issue or requirement metioned in the User Task (e.g., GitHub Issue IDs, specific requirement/error messages, requirement doc).
-    * **Abort Condition**: If you cannot verify or locate the specific references (e.g., an Issue ID returns 404, or a mentioned file doesn't exist), you must **STOP IMMEDIATELY**.
-        * Do not proceed to design or code.
-        * Write a "Context Failure Report" to '%[1]s/worklog.md' explaining what was missing.
-        * Inform the user that the task cannot be processed due to missing context.
-
-	Hints: if needed, Use the 'gh' CLI to inspect GitHub issues/PRs just like 'git'; if either tool lacks auth, run '~/.setup-git.sh' to configure both before proceeding.
-
-
-2.  **Phase 1: Analysis & Design** (Only if Phase 0 passes)
-	* Read as much as you can, you have unlimited read quotas and available contexts. When you are not sure about something, you must study the code until you figure out.
-    * **Analyze**:
-        * **For Bugs**: Perform Root Cause Analysis (RCA). Locate the code causing the issue.
-        * **For Features**: Identify all code paths and files that need modification.
-    * **Design**: Outline your solution strategy in '%[1]s/worklog.md'.
-
-3.  **Phase 2: TDD Implementation**
-	* **Test**: Write tests first. For bugs, ensure you have a regression test.
-	* **Code**: Implement the solution according to your design.
-	* **Verify**: Ensure local tests pass.
-
-	* **Git Discipline**: Work locally only. You may create/checkout branches and stage/commit locally, but do **NOT** push, and do **NOT** create PRs (e.g., via 'gh pr create') during this phase.
-
-3.  **Final Step**: Update '%[1]s/worklog.md' with a summary of changes and test results.
-
-Ultrathink! Analyze first, then code. Avoid over-engineering.
----
-
-#### Review (review_code)
-
-**User Task**: [The user's original task description]
-
-**Instructions**:
-1.  **Review Code Changes**: Review the recent modifications and tests to determine if they satisfy the User Task.
-2.  **Scope**: Focus **ONLY** on the changed code and the direct impact of these changes.
-    * **Do NOT** review unrelated legacy code or pre-existing issues unless they are made worse by this change.
-3.  **Report**: Identify and log **P0 (Critical)** or **P1 (Major)** issues to '%[1]s/code_review.log'.
-    * If the code meets the requirements and has no critical/major issues, report "No P0/P1 issues found".
-
-Hints: if needed, Use the 'gh' CLI to inspect GitHub issues/PRs just like 'git'; if either tool lacks auth, run '~/.setup-git.sh' to configure both before proceeding.
-
-Think it hard and
-
----
-
-####  Fix (codex)
-
-Ultrathink! Fix all P0/P1 issues reported in the review.
-
-**Issues to Fix**:
-[List of P0/P1 issues from '%[1]s/code_review.log']
-
-**Original User Task**: [The user's original task description]
-
-**Instructions**:
-1.  **Address Issues**: Systematically fix every P0 and P1 issue listed.
-2.  **Verify**: Ensure existing tests pass and add new tests if the review indicated missing coverage.
-3.  **Update Log**: Append a "Fix Summary" to '%[1]s/worklog.md' explaining what was changed.
-4.  **Git Discipline**: Work locally only. You may create/checkout branches and stage/commit locally, but do **NOT** push, and do **NOT** create PRs (e.g., via 'gh pr create') during this phase.
-
-Hints: if needed, Use the 'gh' CLI to inspect GitHub issues/PRs just like 'git'; if either tool lacks auth, run '~/.setup-git.sh' to configure both before proceeding.
-
-Ultrathink! Analyze first, then code. Avoid over-engineering.
-
-### Completion
-* Stop Condition: Stop when a review_code run reports no P0/P1 issues.
-* Final Output: Reply with JSON only: {"is_finished": true, "task":"<original task>","summary":"<Concise outcome>"}
-`
-
 const (
 	statusCompleted         = "completed"
 	statusIterationLimit    = "iteration_limit"
@@ -148,132 +40,16 @@ type PublishOptions struct {
 type RunOptions struct {
 	Publish  PublishOptions
 	Streamer *streaming.JSONStreamer
-}
-
-func finalizeBranchPush(handler publishHandler, opts PublishOptions, report map[string]any, success bool, emitter *eventEmitter) (string, error) {
-	lineage := handler.BranchRange()
-	parent := lineage["latest_branch_id"]
-	if parent == "" {
-		parent = opts.ParentBranchID
-	}
-	if parent == "" {
-		return "", errors.New("unable to determine parent branch id for publish step")
-	}
-
-	outcome := iterationLimitSummary
-	if success {
-		summary := ""
-		if report != nil {
-			if s, ok := report["summary"].(string); ok && s != "" {
-				summary = s
-			}
-		}
-		if summary != "" {
-			outcome = summary
-		} else {
-			outcome = defaultSuccessSummary
-		}
-	}
-
-	meta := fmt.Sprintf("commit-meta: start_branch=%s latest_branch=%s", lineage["start_branch_id"], lineage["latest_branch_id"])
-	prompt := fmt.Sprintf(`Finalize the task by committing and pushing the current workspace state.
-
-Task: %[1]s
-Outcome: %[2]s
-Meta (include in the commit message if helpful): %[3]s
-
-The worklog is located into '%[4]s/worklog.md'.
-
-Choose an appropriate git branch name for this task, commit the related file changes, and reply with a concise publish report that MUST include: repository URL, pushed Git branch name, commit hash, and pointers to the latest implementation summary/tests (e.g., '%[4]s/worklog.md' and any test artifact).
-
-Publishing rules:
-- Use existing git identity and credentials. If you hit permission/auth issues, run '~/.setup-git.sh' once to configure git and retry. If it still fails, stop and report the failure.
-- Use the original user task and the latest entries in '%[4]s/worklog.md' to determine the target repository; confirm the repository root with 'git rev-parse --show-toplevel' and verify the remote via 'git remote -v'. Do not operate on an unrelated repo.
-- If you cannot confirm a valid git repository (rev-parse/root or remotes are missing), stop immediately, summarize the delivered work (reference '%[4]s/worklog.md' and tests), and exit instead of attempting any git commands.
-- Stage and commit only the files required for this task; exclude logs, review artifacts, and temporary scratch files.
-- Keep branch names kebab-case and describe the task scope.
-- Keep the commit subject <= 72 characters and meaningful.
-- Git push must be fully non-interactive. Rely on existing credentials or the setup script; do not reveal secrets in logs.
-- Do not stage or commit '%[4]s/worklog.md' or '%[4]s/code_review.log'.
-
-Include a short publish report that states the repository URL, branch name, and a concise PR-style summary.`, opts.Task, outcome, meta, opts.WorkspaceDir, opts.WorkspaceDir)
-
-	logx.Infof("Finalizing workflow by asking codex to push from branch %s lineage.", parent)
-	execArgs := map[string]any{
-		"agent":            "codex",
-		"prompt":           prompt,
-		"parent_branch_id": parent,
-	}
-	if opts.ProjectName != "" {
-		execArgs["project_name"] = opts.ProjectName
-	}
-	argsBytes, _ := json.Marshal(execArgs)
-	execCall := t.ToolCall{Type: "function"}
-	execCall.Function.Name = "execute_agent"
-	execCall.Function.Arguments = string(argsBytes)
-
-	var (
-		itemID   string
-		start    time.Time
-		duration time.Duration
-	)
-	if emitter != nil {
-		args := map[string]any{
-			"agent":            "codex",
-			"parent_branch_id": parent,
-		}
-		if opts.ProjectName != "" {
-			args["project_name"] = opts.ProjectName
-		}
-		itemID = emitter.ItemStarted("publish", "publish", args)
-		start = time.Now()
-	}
-
-	execResp := handler.Handle(execCall)
-	if !start.IsZero() {
-		duration = time.Since(start)
-	}
-
-	data, _ := execResp["data"].(map[string]any)
-	branchID := t.ExtractBranchID(data)
-	if branchID == "" {
-		branchID = t.ExtractBranchID(execResp)
-	}
-	publishSummary := extractBranchOutput(data)
-	if publishSummary == "" {
-		publishSummary = summarizeToolResult(execResp)
-	}
-
-	status := resultStatus(execResp)
-	if emitter != nil {
-		emitter.ItemCompleted(itemID, status, duration, branchID, publishSummary)
-	}
-
-	if status != "success" {
-		return "", fmt.Errorf("publish execute_agent failed: %v", execResp)
-	}
-	if branchID == "" {
-		return "", errors.New("publish execute_agent missing branch id")
-	}
-	if publishSummary == "" {
-		logx.Warningf("Publish response missing required report (repo/branch/commit/tests); continuing without it (branch_id=%s)", branchID)
-		publishSummary = fmt.Sprintf("Publish report unavailable; inspect Pantheon branch %s for push details.", branchID)
-	}
-	if report != nil {
-		report["publish_report"] = publishSummary
-	}
-	if branchStatus := strings.TrimSpace(fmt.Sprintf("%v", data["status"])); branchStatus != "" {
-		switch strings.ToLower(branchStatus) {
-		case "failed":
-			return "", fmt.Errorf("publish branch %s completed with failure status", branchID)
-		}
-	}
-
-	return branchID, nil
+	// MaxReviews caps completed critic-role agent runs (review_code by
+	// default) before the workflow stops and publishes; 0 uses maxIterations.
+	MaxReviews int
+	// Observers receive engine events in addition to the NDJSON streamer
+	// (when Streamer is set).
+	Observers []Observer
 }
 
 func BuildInitialMessages(task, projectName, workspaceDir, parentBranchID string) []b.ChatMessage {
-	systemPrompt := fmt.Sprintf(systemPromptTemplate, workspaceDir)
+	systemPrompt := renderPrompt(tmplSystem, SystemPromptData{WorkspaceDir: workspaceDir})
 	userPayload := map[string]any{
 		"task":             task,
 		"parent_branch_id": parentBranchID,
@@ -307,286 +83,6 @@ func ParseFinalReport(msg b.ChatMessage) (map[string]any, bool) {
 	return nil, false
 }
 
-func Orchestrate(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMessage, opts RunOptions) (map[string]any, error) {
-	tools := t.GetToolDefinitions()
-	emitter := newEventEmitter(opts.Streamer)
-	var (
-		finalReport    map[string]any
-		finished       bool
-		errorState     bool
-		reviewCount    int
-		totalToolCalls int
-		lastTurn       int
-	)
-
-	for i := 1; ; i++ {
-		lastTurn = i
-		logx.Infof("LLM iteration %d", i)
-		turnID := fmt.Sprintf("turn_%d", i)
-		if emitter != nil {
-			emitter.TurnStarted(turnID, i, len(messages), totalToolCalls)
-		}
-		resp, err := brain.Complete(messages, tools)
-		if err != nil {
-			if emitter != nil {
-				emitter.EmitError("llm.complete", err.Error(), map[string]any{"iteration": i, "turn_id": turnID})
-			}
-			return nil, err
-		}
-		choice := resp.Choices[0].Message
-		messages = append(messages, assistantMessageToDict(choice))
-		if emitter != nil {
-			emitter.AssistantMessage(turnID, choice.Content, len(choice.ToolCalls))
-		}
-
-		if len(choice.ToolCalls) > 0 {
-			turnToolCount := 0
-			reviewCompleted := false
-			stopDueToInstruction := false
-			for _, tc := range choice.ToolCalls {
-				turnToolCount++
-				totalToolCalls++
-				args := parseToolArgs(tc.Function.Arguments)
-				var itemArgs map[string]any
-				if emitter != nil {
-					itemArgs = sanitizeToolArgs(tc.Function.Name, args)
-				}
-				itemID := ""
-				if emitter != nil {
-					itemID = emitter.ItemStarted("tool_call", tc.Function.Name, itemArgs)
-				}
-				htc := t.ToolCall{ID: tc.ID, Type: tc.Type}
-				htc.Function.Name = tc.Function.Name
-				htc.Function.Arguments = tc.Function.Arguments
-				var start time.Time
-				if emitter != nil {
-					start = time.Now()
-				}
-				result := handler.Handle(htc)
-				var duration time.Duration
-				if emitter != nil {
-					duration = time.Since(start)
-				}
-				toolMsg := b.ChatMessage{Role: "tool", ToolCallID: tc.ID, Content: toJSON(result)}
-				messages = append(messages, toolMsg)
-				if emitter != nil {
-					emitter.ItemCompleted(itemID, resultStatus(result), duration, eventBranchID(result), summarizeToolResult(result))
-				}
-
-				if instr, summaryMsg, details := toolInstruction(result); instr != "" {
-					if emitter != nil {
-						emitter.EmitError("tool_instruction", summaryMsg, map[string]any{"instruction": instr})
-					}
-					finalReport = buildErrorFinalReport(opts.Publish.Task, summaryMsg, instr, details)
-					finished = true
-					errorState = true
-					stopDueToInstruction = true
-					break
-				}
-
-				if tc.Function.Name == "execute_agent" {
-					if agent, _ := args["agent"].(string); agent == "review_code" {
-						if status, _ := result["status"].(string); status == "success" {
-							reviewCompleted = true
-						}
-					}
-				}
-			}
-			if emitter != nil {
-				emitter.TurnCompleted(turnID, i, turnToolCount, false)
-			}
-			if stopDueToInstruction {
-				break
-			}
-			if reviewCompleted {
-				reviewCount++
-				logx.Infof("Completed review iteration %d/%d", reviewCount, maxIterations)
-				if reviewCount >= maxIterations {
-					logx.Errorf("Reached review iteration limit without final report.")
-					break
-				}
-			}
-			continue
-		}
-
-		hasFinal := false
-		if fr, ok := ParseFinalReport(choice); ok {
-			finalReport = fr
-			finished = true
-			hasFinal = true
-		} else {
-			logx.Infof("Assistant response was not a final report; continuing.")
-		}
-		if emitter != nil {
-			emitter.TurnCompleted(turnID, i, 0, hasFinal)
-		}
-		if finished {
-			break
-		}
-	}
-
-	runPublish := func(report map[string]any, success bool) (string, error) {
-		turnNum := lastTurn + 1
-		var turnID string
-		if emitter != nil {
-			turnID = fmt.Sprintf("turn_%d", turnNum)
-			emitter.TurnStarted(turnID, turnNum, len(messages), totalToolCalls)
-		}
-		branchID, err := finalizeBranchPush(handler, opts.Publish, report, success, emitter)
-		totalToolCalls++
-		lastTurn = turnNum
-		if emitter != nil {
-			emitter.TurnCompleted(turnID, turnNum, 1, false)
-		}
-		return branchID, err
-	}
-
-	if finished {
-		if errorState {
-			ensureReportDefaults(finalReport, opts.Publish.Task, statusFinishedWithError, true)
-			return finalReport, nil
-		}
-		ensureReportDefaults(finalReport, opts.Publish.Task, statusCompleted, true)
-		_, err := runPublish(finalReport, true)
-		if err != nil {
-			if emitter != nil {
-				emitter.EmitError("publish", err.Error(), nil)
-			}
-			return nil, err
-		}
-		return finalReport, nil
-	}
-
-	finalReport = map[string]any{
-		"is_finished": false,
-		"status":      statusIterationLimit,
-		"task":        opts.Publish.Task,
-		"summary":     iterationLimitSummary,
-	}
-	branchID, err := runPublish(finalReport, false)
-	if err != nil {
-		if emitter != nil {
-			emitter.EmitError("publish", err.Error(), nil)
-		}
-		return nil, err
-	}
-	if branchID != "" {
-		logx.Infof("Workspace published to branch (branch_id=%s) after iteration limit.", branchID)
-	}
-	return finalReport, nil
-}
-
-func ChatLoop(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMessage, maxIters int, opts RunOptions) (map[string]any, error) {
-	if maxIters <= 0 {
-		maxIters = maxIterations
-	}
-	tools := t.GetToolDefinitions()
-	var (
-		finalReport map[string]any
-		finished    bool
-		errorState  bool
-		reviewCount int
-	)
-
-	for i := 1; ; i++ {
-		fmt.Printf("[iter %d] requesting completion...\n", i)
-		resp, err := brain.Complete(messages, tools)
-		if err != nil {
-			return nil, err
-		}
-		choice := resp.Choices[0].Message
-		if choice.Content != "" {
-			fmt.Printf("assistant> %s\n", choice.Content)
-		}
-		messages = append(messages, assistantMessageToDict(choice))
-
-		if len(choice.ToolCalls) > 0 {
-			reviewCompleted := false
-			stopDueToInstruction := false
-			for _, tc := range choice.ToolCalls {
-				fmt.Printf("tool> %s %s\n", tc.Function.Name, tc.Function.Arguments)
-				var args map[string]any
-				if tc.Function.Arguments != "" {
-					_ = json.Unmarshal([]byte(tc.Function.Arguments), &args)
-				}
-				htc := t.ToolCall{ID: tc.ID, Type: tc.Type}
-				htc.Function.Name = tc.Function.Name
-				htc.Function.Arguments = tc.Function.Arguments
-				result := handler.Handle(htc)
-				js := toJSON(result)
-				if len(js) > 2000 {
-					js = js[:2000]
-				}
-				fmt.Printf("tool< %s\n", js)
-				messages = append(messages, b.ChatMessage{Role: "tool", ToolCallID: tc.ID, Content: toJSON(result)})
-
-				if instr, summaryMsg, details := toolInstruction(result); instr != "" {
-					finalReport = buildErrorFinalReport(opts.Publish.Task, summaryMsg, instr, details)
-					finished = true
-					errorState = true
-					stopDueToInstruction = true
-					break
-				}
-
-				if tc.Function.Name == "execute_agent" {
-					if agent, _ := args["agent"].(string); agent == "review_code" {
-						if status, _ := result["status"].(string); status == "success" {
-							reviewCompleted = true
-						}
-					}
-				}
-			}
-			if stopDueToInstruction {
-				break
-			}
-			if reviewCompleted {
-				reviewCount++
-				fmt.Printf("note: completed review iteration %d/%d\n", reviewCount, maxIters)
-				if reviewCount >= maxIters {
-					logx.Errorf("Reached review iteration limit without final report.")
-					break
-				}
-			}
-			continue
-		}
-		if fr, ok := ParseFinalReport(choice); ok {
-			finalReport = fr
-			finished = true
-			fmt.Println("assistant< final_report")
-			break
-		}
-		fmt.Println("assistant< not final yet, continuing...")
-	}
-
-	if finished {
-		if errorState {
-			ensureReportDefaults(finalReport, opts.Publish.Task, statusFinishedWithError, true)
-			return finalReport, nil
-		}
-		ensureReportDefaults(finalReport, opts.Publish.Task, statusCompleted, true)
-		_, err := finalizeBranchPush(handler, opts.Publish, finalReport, true, nil)
-		if err != nil {
-			return nil, err
-		}
-		return finalReport, nil
-	}
-
-	finalReport = map[string]any{
-		"is_finished": false,
-		"status":      statusIterationLimit,
-		"task":        opts.Publish.Task,
-		"summary":     iterationLimitSummary,
-	}
-	branchID, err := finalizeBranchPush(handler, opts.Publish, finalReport, false, nil)
-	if err != nil {
-		return nil, err
-	}
-	if branchID != "" {
-		fmt.Fprintf(os.Stderr, "info: workspace pushed (branch_id=%s)\n", branchID)
-	}
-	return finalReport, nil
-}
-
 func toJSON(v any) string { b, _ := json.Marshal(v); return string(b) }
 
 func ensureReportDefaults(report map[string]any, task, status string, finished bool) {
@@ -688,63 +184,6 @@ func extractBranchOutput(data map[string]any) string {
 	return ""
 }
 
-type eventEmitter struct {
-	streamer *streaming.JSONStreamer
-	nextItem int
-}
-
-func newEventEmitter(streamer *streaming.JSONStreamer) *eventEmitter {
-	if streamer == nil || !streamer.Enabled() {
-		return nil
-	}
-	return &eventEmitter{streamer: streamer}
-}
-
-func (e *eventEmitter) TurnStarted(turnID string, iteration, messageCount, toolCount int) {
-	if e == nil {
-		return
-	}
-	e.streamer.EmitTurnStarted(turnID, iteration, messageCount, toolCount)
-}
-
-func (e *eventEmitter) AssistantMessage(turnID, preview string, toolCalls int) {
-	if e == nil {
-		return
-	}
-	e.streamer.EmitAssistantMessage(turnID, preview, toolCalls)
-}
-
-func (e *eventEmitter) TurnCompleted(turnID string, iteration, toolCalls int, hasFinal bool) {
-	if e == nil {
-		return
-	}
-	e.streamer.EmitTurnCompleted(turnID, iteration, toolCalls, hasFinal)
-}
-
-func (e *eventEmitter) ItemStarted(kind, name string, args map[string]any) string {
-	if e == nil {
-		return ""
-	}
-	e.nextItem++
-	itemID := fmt.Sprintf("item_%d", e.nextItem)
-	e.streamer.EmitItemStarted(itemID, kind, name, args)
-	return itemID
-}
-
-func (e *eventEmitter) ItemCompleted(itemID, status string, duration time.Duration, branchID, summary string) {
-	if e == nil || itemID == "" {
-		return
-	}
-	e.streamer.EmitItemCompleted(itemID, status, duration, branchID, summary)
-}
-
-func (e *eventEmitter) EmitError(scope, message string, extra map[string]any) {
-	if e == nil {
-		return
-	}
-	e.streamer.EmitError(scope, message, extra)
-}
-
 func parseToolArgs(raw string) map[string]any {
 	if strings.TrimSpace(raw) == "" {
 		return map[string]any{}
@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"embed"
+	"fmt"
+
+	"dev_agent/internal/logx"
+	"dev_agent/internal/prompts"
+)
+
+//go:embed templates/*.tmpl
+var promptFS embed.FS
+
+// Template names. Each file lives in templates/ and can be overridden via
+// ConfigurePrompts.
+const (
+	tmplSystem  = "system.tmpl"
+	tmplPublish = "publish.tmpl"
+)
+
+// SystemPromptData feeds system.tmpl, the orchestrator's system prompt.
+type SystemPromptData struct {
+	WorkspaceDir string
+}
+
+// PublishPromptData feeds publish.tmpl, the final commit-and-push request.
+type PublishPromptData struct {
+	Task         string
+	Outcome      string
+	Meta         string
+	WorkspaceDir string
+}
+
+func promptSamples() map[string]any {
+	return map[string]any{
+		tmplSystem:  SystemPromptData{WorkspaceDir: "/home/pan/workspace"},
+		tmplPublish: PublishPromptData{Task: "task", Outcome: "outcome", Meta: "commit-meta", WorkspaceDir: "/home/pan/workspace"},
+	}
+}
+
+var (
+	defaultPromptSet = prompts.MustLoad(promptFS, promptSamples())
+	activePromptSet  = defaultPromptSet
+)
+
+// ConfigurePrompts validates the templates in dir (overriding the embedded
+// defaults by file name) and makes them active. An empty dir restores the
+// defaults. Call it once at startup, before any workflow runs.
+func ConfigurePrompts(dir string) error {
+	set, err := prompts.Load(promptFS, dir, promptSamples())
+	if err != nil {
+		return err
+	}
+	activePromptSet = set
+	return nil
+}
+
+// PromptVersions reports the source and version of each active template.
+func PromptVersions() map[string]string {
+	return activePromptSet.Versions()
+}
+
+// renderPrompt executes an active template. Templates are validated when
+// loaded, so a render failure falls back to the embedded default rather than
+// sending an empty prompt.
+func renderPrompt(name string, data any) string {
+	out, err := activePromptSet.Render(name, data)
+	if err == nil {
+		return out
+	}
+	logx.Errorf("Rendering prompt template %s failed, using embedded default: %v", name, err)
+	out, err = defaultPromptSet.Render(name, data)
+	if err != nil {
+		panic(fmt.Sprintf("embedded prompt template %s failed to render: %v", name, err))
+	}
+	return out
+}
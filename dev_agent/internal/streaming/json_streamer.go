@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -17,15 +18,18 @@ const (
 	promptPreviewLimit    = 4096
 )
 
-// JSONStreamer emits NDJSON events that mirror the Codex CLI format.
-// When disabled it becomes a no-op, allowing callers to unconditionally
-// call the helpers without littering checks throughout the codebase.
+// JSONStreamer emits the typed NDJSON events of events.go, which mirror the
+// Codex CLI format, and fans them out to its sinks (see sinks.go). When disabled it becomes a no-op,
+// allowing callers to unconditionally call the helpers without littering
+// checks throughout the codebase.
 type JSONStreamer struct {
 	enabled  bool
-	writer   io.Writer
+	sinks    []Sink
 	mu       sync.Mutex
 	sequence int64
 	threadID string
+	agent    string
+	closed   bool
 }
 
 func NewJSONStreamer(enabled bool, w io.Writer) *JSONStreamer {
@@ -35,17 +39,58 @@ func NewJSONStreamer(enabled bool, w io.Writer) *JSONStreamer {
 	if w == nil {
 		w = os.Stdout
 	}
+	return NewSinkStreamer(NewWriterSink(w, Filter{}))
+}
+
+// NewSinkStreamer returns a streamer that writes every event to each sink
+// accepting its type. Without sinks the streamer is disabled.
+func NewSinkStreamer(sinks ...Sink) *JSONStreamer {
+	if len(sinks) == 0 {
+		return &JSONStreamer{}
+	}
 	return &JSONStreamer{
 		enabled:  true,
-		writer:   w,
+		sinks:    sinks,
 		threadID: newThreadID(),
 	}
 }
 
+// Close flushes and closes the sinks; later events are dropped. Call it
+// before the process exits so buffered webhook batches are delivered.
+func (s *JSONStreamer) Close() error {
+	if !s.Enabled() {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	var errs []error
+	for _, sink := range s.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (s *JSONStreamer) Enabled() bool {
 	return s != nil && s.enabled
 }
 
+// SetAgent names the agent in the envelope of later events. Call it before
+// the first event.
+func (s *JSONStreamer) SetAgent(agent string) {
+	if !s.Enabled() {
+		return
+	}
+	s.mu.Lock()
+	s.agent = agent
+	s.mu.Unlock()
+}
+
 func (s *JSONStreamer) ThreadID() string {
 	if s == nil {
 		return ""
@@ -57,26 +102,24 @@ func (s *JSONStreamer) EmitThreadStarted(task, project, parent string, headless
 	if !s.Enabled() {
 		return
 	}
-	payload := map[string]any{
-		"task":             task,
-		"project_name":     project,
-		"parent_branch_id": parent,
-		"headless":         headless,
-	}
-	s.emit("thread.started", payload)
+	s.emit(EventThreadStarted, &ThreadStarted{
+		Task:           task,
+		ProjectName:    project,
+		ParentBranchID: parent,
+		Headless:       headless,
+	})
 }
 
 func (s *JSONStreamer) EmitTurnStarted(turnID string, iteration, messageCount, toolCount int) {
 	if !s.Enabled() {
 		return
 	}
-	payload := map[string]any{
-		"turn_id":       turnID,
-		"iteration":     iteration,
-		"message_count": messageCount,
-		"tool_count":    toolCount,
-	}
-	s.emit("turn.started", payload)
+	s.emit(EventTurnStarted, &TurnStarted{
+		TurnID:       turnID,
+		Iteration:    iteration,
+		MessageCount: messageCount,
+		ToolCount:    toolCount,
+	})
 }
 
 func (s *JSONStreamer) EmitAssistantMessage(turnID, preview string, toolCalls int) {
@@ -84,28 +127,24 @@ func (s *JSONStreamer) EmitAssistantMessage(turnID, preview string, toolCalls in
 		return
 	}
 	snippet := summarize(preview, assistantPreviewLimit)
-	payload := map[string]any{
-		"turn_id":         turnID,
-		"preview":         snippet,
-		"tool_call_count": toolCalls,
-	}
-	if snippet != strings.TrimSpace(preview) {
-		payload["truncated"] = true
-	}
-	s.emit("assistant.message", payload)
+	s.emit(EventAssistantMessage, &AssistantMessage{
+		TurnID:        turnID,
+		Preview:       snippet,
+		ToolCallCount: toolCalls,
+		Truncated:     snippet != strings.TrimSpace(preview),
+	})
 }
 
 func (s *JSONStreamer) EmitTurnCompleted(turnID string, iteration, toolCalls int, hasFinal bool) {
 	if !s.Enabled() {
 		return
 	}
-	payload := map[string]any{
-		"turn_id":          turnID,
-		"iteration":        iteration,
-		"tool_call_count":  toolCalls,
-		"has_final_report": hasFinal,
-	}
-	s.emit("turn.completed", payload)
+	s.emit(EventTurnCompleted, &TurnCompleted{
+		TurnID:         turnID,
+		Iteration:      iteration,
+		ToolCallCount:  toolCalls,
+		HasFinalReport: hasFinal,
+	})
 }
 
 func (s *JSONStreamer) EmitItemStarted(itemID, kind, name string, args map[string]any) {
@@ -115,90 +154,92 @@ func (s *JSONStreamer) EmitItemStarted(itemID, kind, name string, args map[strin
 	if args == nil {
 		args = map[string]any{}
 	}
-	payload := map[string]any{
-		"item_id": itemID,
-		"kind":    kind,
-		"name":    name,
-		"args":    args,
-	}
-	s.emit("item.started", payload)
+	s.emit(EventItemStarted, &ItemStarted{
+		ItemID: itemID,
+		Kind:   kind,
+		Name:   name,
+		Args:   args,
+	})
 }
 
 func (s *JSONStreamer) EmitItemCompleted(itemID, status string, duration time.Duration, branchID, summary string) {
 	if !s.Enabled() {
 		return
 	}
-	payload := map[string]any{
-		"item_id":     itemID,
-		"status":      status,
-		"duration_ms": duration.Milliseconds(),
-	}
-	if branchID != "" {
-		payload["branch_id"] = branchID
-	}
-	if summary != "" {
-		payload["summary"] = summarize(summary, assistantPreviewLimit)
-	}
-	s.emit("item.completed", payload)
+	s.emit(EventItemCompleted, &ItemCompleted{
+		ItemID:     itemID,
+		Status:     status,
+		DurationMS: duration.Milliseconds(),
+		BranchID:   branchID,
+		Summary:    summarize(summary, assistantPreviewLimit),
+	})
 }
 
-func (s *JSONStreamer) EmitThreadCompleted(status, summary string, finalReport map[string]any) {
+// EmitThreadCompleted emits the last event of a run. finalReport is the
+// agent's report: a map or any value that marshals to a JSON object.
+func (s *JSONStreamer) EmitThreadCompleted(status, summary string, finalReport any) {
 	if !s.Enabled() {
 		return
 	}
-	payload := map[string]any{
-		"status": status,
-	}
-	if summary != "" {
-		payload["summary"] = summarize(summary, assistantPreviewLimit)
+	ev := &ThreadCompleted{
+		Status:  status,
+		Summary: summarize(summary, assistantPreviewLimit),
 	}
 	if finalReport != nil {
-		payload["final_report"] = finalReport
-	}
-	s.emit("thread.completed", payload)
+		data, err := json.Marshal(finalReport)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "json_streamer: marshal final report: %v\n", err)
+		} else if string(data) != "null" {
+			ev.FinalReport = data
+		}
+	}
+	s.emit(EventThreadCompleted, ev)
 }
 
+// EmitError reports a failure. The iteration, turn_id, item_id and
+// instruction keys of extra become fields of the event; other keys go to
+// its details.
 func (s *JSONStreamer) EmitError(scope, message string, extra map[string]any) {
 	if !s.Enabled() {
 		return
 	}
-	payload := map[string]any{
-		"scope":   scope,
-		"message": message,
-	}
-	for k, v := range extra {
-		payload[k] = v
-	}
-	s.emit("error", payload)
+	s.emit(EventError, newError(scope, message, extra))
 }
 
-func (s *JSONStreamer) emit(eventType string, payload map[string]any) {
+// emit stamps the envelope of ev and writes it to the sinks that accept
+// eventType.
+func (s *JSONStreamer) emit(eventType string, ev Event) {
 	if !s.Enabled() {
 		return
 	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.sequence++
-
-	envelope := make(map[string]any, len(payload)+4)
-	for k, v := range payload {
-		envelope[k] = v
+	if s.closed {
+		return
 	}
-	envelope["type"] = eventType
-	envelope["timestamp"] = time.Now().UTC().Format(time.RFC3339Nano)
-	envelope["sequence"] = s.sequence
-	envelope["thread_id"] = s.threadID
+	s.sequence++
 
-	data, err := json.Marshal(envelope)
+	*ev.Header() = Envelope{
+		Type:          eventType,
+		SchemaVersion: SchemaVersion,
+		Timestamp:     time.Now().UTC().Format(time.RFC3339Nano),
+		Sequence:      s.sequence,
+		ThreadID:      s.threadID,
+		Agent:         s.agent,
+	}
+	data, err := json.Marshal(ev)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "json_streamer: marshal error: %v\n", err)
 		return
 	}
-	if len(data) == 0 || data[len(data)-1] != '\n' {
-		data = append(data, '\n')
-	}
-	if _, err := s.writer.Write(data); err != nil {
-		fmt.Fprintf(os.Stderr, "json_streamer: write error: %v\n", err)
+	data = append(data, '\n')
+	for _, sink := range s.sinks {
+		if !sink.Accepts(eventType) {
+			continue
+		}
+		if err := sink.Write(data); err != nil {
+			fmt.Fprintf(os.Stderr, "json_streamer: write error: %v\n", err)
+		}
 	}
 }
 
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"dev_agent_v2/internal/batch"
+	cfg "dev_agent_v2/internal/config"
+	o "dev_agent_v2/internal/orchestrator"
+	"dev_agent_v2/internal/streaming"
+	t "dev_agent_v2/internal/tools"
+)
+
+// runBatch implements `dev-agent-v2 batch`. Every manifest entry runs headless
+// with its own NDJSON stream file and report; the exit code is non-zero when
+// any run ends in an error.
+//
+// Recognized per-task options: max_turns, dry_run, dry_run_reviews.
+func runBatch(args []string) int {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	manifest := fs.String("manifest", "", "Path to a JSONL or YAML task manifest (required)")
+	concurrency := fs.Int("concurrency", 2, "Maximum number of tasks running at once")
+	outputDir := fs.String("output-dir", "batch-runs", "Directory for per-task stream files, reports, and summary.json")
+	project := fs.String("project-name", "", "Default project name for entries without project_name")
+	dryRun := fs.Bool("dry-run", false, "Run every task against the simulated Pantheon client")
+	promptDir := fs.String("prompt-dir", "", "Directory of *.tmpl files overriding the embedded prompt templates")
+	_ = fs.Parse(args)
+
+	if strings.TrimSpace(*manifest) == "" {
+		fmt.Fprintln(os.Stderr, "batch: --manifest is required")
+		return 2
+	}
+	if err := o.ConfigurePrompts(*promptDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Prompt template error: %v\n", err)
+		return 1
+	}
+	tasks, err := batch.LoadManifest(*manifest)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "batch: %v\n", err)
+		return 1
+	}
+
+	conf, err := cfg.FromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return 1
+	}
+	if *project != "" {
+		conf.ProjectName = *project
+	}
+	for _, task := range tasks {
+		if task.ProjectName == "" && conf.ProjectName == "" {
+			fmt.Fprintf(os.Stderr, "batch: task %s has no project_name and no default was provided via PROJECT_NAME or --project-name\n", task.ID)
+			return 1
+		}
+	}
+
+	runner := batch.Runner{
+		OutputDir:   *outputDir,
+		Concurrency: *concurrency,
+		Run: func(task batch.Task, stream io.Writer) (map[string]any, error) {
+			taskConf := conf
+			if task.ProjectName != "" {
+				taskConf.ProjectName = task.ProjectName
+			}
+			reviewScript, err := t.ParseDryRunReviewScript(task.OptionString("dry_run_reviews", ""))
+			if err != nil {
+				return nil, err
+			}
+			return runTask(taskConf, runSpec{
+				Task:         task.Task,
+				ParentBranch: task.ParentBranchID,
+				Headless:     true,
+				MaxTurns:     task.OptionInt("max_turns", 0),
+				DryRun:       task.OptionBool("dry_run", *dryRun),
+				ReviewScript: reviewScript,
+				Streamer:     streaming.NewJSONStreamer(true, stream),
+			})
+		},
+	}
+
+	results, err := runner.Execute(tasks)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "batch: %v\n", err)
+		if results == nil {
+			return 1
+		}
+	}
+	batch.WriteSummaryTable(os.Stdout, results)
+	for _, res := range results {
+		if res.Error != "" {
+			return 1
+		}
+	}
+	return 0
+}
@@ -16,17 +16,60 @@ import (
 	t "dev_agent_v2/internal/tools"
 )
 
+// runSpec captures everything needed to execute one task, whether it comes
+// from CLI flags or from a batch manifest entry.
+type runSpec struct {
+	Task         string
+	ParentBranch string
+	Headless     bool
+	MaxTurns     int
+	DryRun       bool
+	ReviewScript []string
+	// Streamer receives the run's events; nil disables streaming. The
+	// caller owns it and closes it after the run.
+	Streamer *streaming.JSONStreamer
+	// CheckpointPath, when set, receives a JSON checkpoint after every turn.
+	CheckpointPath string
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "batch" {
+		os.Exit(runBatch(os.Args[2:]))
+	}
+
 	task := flag.String("task", "", "Task description (should include the issue link or existing PR link)")
 	parent := flag.String("parent-branch-id", "", "Parent branch UUID (required)")
 	project := flag.String("project-name", "", "Optional project name override")
 	headless := flag.Bool("headless", false, "Run in headless mode (no chat prints)")
 	streamJSON := flag.Bool("stream-json", false, "Emit orchestration events as NDJSON to stdout (forces headless mode)")
+	streamSinks := flag.String("stream-sinks", "", "JSON file of NDJSON event sinks (stdout, file, unix, webhook), each with its own event filter")
 	maxTurns := flag.Int("max-turns", 0, "Maximum LLM turns before stopping (0 uses default)")
+	dryRun := flag.Bool("dry-run", false, "Simulate Pantheon branches instead of calling MCP (no branches are created)")
+	dryRunReviews := flag.String("dry-run-reviews", "issues,clean", "Comma-separated review_code outcomes (issues|clean) replayed in --dry-run")
+	checkpoint := flag.String("checkpoint", "", "Write a JSON checkpoint of the conversation to this path after every turn")
+	promptDir := flag.String("prompt-dir", "", "Directory of *.tmpl files overriding the embedded prompt templates")
 	flag.Parse()
 
-	streamEnabled := streamJSON != nil && *streamJSON
-	if streamEnabled {
+	if err := o.ConfigurePrompts(*promptDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Prompt template error: %v\n", err)
+		os.Exit(1)
+	}
+
+	reviewScript, err := t.ParseDryRunReviewScript(*dryRunReviews)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid --dry-run-reviews: %v\n", err)
+		os.Exit(1)
+	}
+
+	sinkConfigs, err := streaming.LoadSinkConfigs(*streamSinks)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--stream-sinks: %v\n", err)
+		os.Exit(1)
+	}
+	// Only events on stdout force headless mode and quiet the logs; with
+	// file, socket or webhook sinks the terminal stays readable.
+	streamStdout := streaming.WritesStdout(*streamJSON, sinkConfigs)
+	if streamStdout {
 		*headless = true
 		logx.SetLevel(logx.Error)
 	}
@@ -52,7 +95,7 @@ func main() {
 	tsk := strings.TrimSpace(*task)
 	if tsk == "" {
 		promptWriter := os.Stdout
-		if streamEnabled {
+		if streamStdout {
 			promptWriter = os.Stderr
 		}
 		fmt.Fprintf(promptWriter, "you> Enter task description: ")
@@ -65,31 +108,84 @@ func main() {
 		}
 	}
 
+	spec := runSpec{
+		Task:           tsk,
+		ParentBranch:   *parent,
+		Headless:       *headless,
+		MaxTurns:       *maxTurns,
+		DryRun:         *dryRun,
+		ReviewScript:   reviewScript,
+		CheckpointPath: strings.TrimSpace(*checkpoint),
+	}
+	if *streamJSON || len(sinkConfigs) > 0 {
+		spec.Streamer, err = streaming.Open(*streamJSON, sinkConfigs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "--stream-sinks: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	report, err := runTask(conf, spec)
+	closeStreamer(spec.Streamer)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Fprintln(os.Stderr, string(out))
+}
+
+// closeStreamer flushes the stream sinks and reports events they dropped.
+func closeStreamer(streamer *streaming.JSONStreamer) {
+	if err := streamer.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Stream sinks: %v\n", err)
+	}
+}
+
+// runTask executes one orchestration run and returns the sanitized final
+// report with branch lineage attached.
+func runTask(conf cfg.AgentConfig, spec runSpec) (map[string]any, error) {
+	agents, err := t.LoadAgentRegistry(conf.AgentRegistryFile)
+	if err != nil {
+		return nil, err
+	}
 	brain := b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
-	mcp := t.NewMCPClient(conf.MCPBaseURL)
-	handler := t.NewToolHandler(mcp, conf.ProjectName, *parent, conf.WorkspaceDir, &t.ToolHandlerTiming{
+	var handler *t.ToolHandler
+	timing := &t.ToolHandlerTiming{
 		PollTimeout: conf.PollTimeout,
 		PollInitial: conf.PollInitial,
 		PollMax:     conf.PollMax,
 		PollBackoff: conf.PollBackoffFactor,
-	})
+	}
+	if spec.DryRun {
+		logx.Warningf("Dry-run mode: Pantheon calls are simulated; no branches will be created.")
+		handler = t.NewToolHandler(t.NewDryRunClient(conf.WorkspaceDir, spec.ReviewScript), conf.ProjectName, spec.ParentBranch, conf.WorkspaceDir, timing)
+	} else {
+		handler = t.NewToolHandler(t.NewMCPClient(conf.MCPBaseURL), conf.ProjectName, spec.ParentBranch, conf.WorkspaceDir, timing)
+	}
+
+	handler.SetAgentRegistry(agents)
 
-	msgs := o.BuildInitialMessages(tsk, conf.ProjectName, conf.WorkspaceDir, *parent)
+	msgs := o.BuildInitialMessages(spec.Task, conf.ProjectName, conf.WorkspaceDir, spec.ParentBranch)
 
-	var streamer *streaming.JSONStreamer
-	if streamEnabled {
-		streamer = streaming.NewJSONStreamer(true, os.Stdout)
-		streamer.EmitThreadStarted(tsk, conf.ProjectName, *parent, *headless)
+	streamer := spec.Streamer
+	if streamer != nil {
+		streamer.SetAgent(streaming.AgentDevV2)
+		streamer.EmitThreadStarted(spec.Task, conf.ProjectName, spec.ParentBranch, spec.Headless)
 	}
 
 	opts := o.RunOptions{
-		Task:     tsk,
+		Task:     spec.Task,
 		Streamer: streamer,
-		MaxTurns: *maxTurns,
+		MaxTurns: spec.MaxTurns,
+	}
+	if spec.CheckpointPath != "" {
+		opts.Observers = append(opts.Observers, o.NewCheckpointObserver(spec.CheckpointPath))
 	}
 
 	var report map[string]any
-	if *headless {
+	if spec.Headless {
 		report, err = o.Orchestrate(brain, handler, msgs, opts)
 	} else {
 		report, err = o.ChatLoop(brain, handler, msgs, 0, opts)
@@ -99,8 +195,7 @@ func main() {
 			streamer.EmitError("cli", err.Error(), nil)
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
-		fmt.Fprintln(os.Stderr, err.Error())
-		os.Exit(1)
+		return nil, err
 	}
 
 	// Attach observed branch range and instructions
@@ -115,12 +210,16 @@ func main() {
 		report["latest_branch_id"] = latest
 	}
 	if _, ok := report["task"]; !ok {
-		report["task"] = tsk
+		report["task"] = spec.Task
 	}
 	sanitizeFinalReport(report)
 	if finalized, ferr := finalizeReportWithBrain(brain, report); ferr == nil && finalized != nil {
 		report = finalized
 	}
+	if spec.DryRun {
+		report["dry_run"] = true
+	}
+	report["prompt_templates"] = o.PromptVersions()
 	sanitizeFinalReport(report)
 
 	if streamer != nil && streamer.Enabled() {
@@ -128,9 +227,7 @@ func main() {
 		summary, _ := report["summary"].(string)
 		streamer.EmitThreadCompleted(status, summary, report)
 	}
-
-	out, _ := json.MarshalIndent(report, "", "  ")
-	fmt.Fprintln(os.Stderr, string(out))
+	return report, nil
 }
 
 func sanitizeFinalReport(report map[string]any) {
@@ -150,6 +247,8 @@ func sanitizeFinalReport(report map[string]any) {
 		"pr_head_branch":   {},
 		"error":            {},
 		"instruction":      {}, // optional; used by FINISHED_WITH_ERROR flows
+		"dry_run":          {},
+		"prompt_templates": {},
 	}
 	for k := range report {
 		if _, ok := allowed[k]; !ok {
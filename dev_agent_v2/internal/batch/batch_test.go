@@ -0,0 +1,170 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseJSONLManifest(t *testing.T) {
+	data := []byte(`# nightly backlog
+{"id":"fix login","task":"Fix login bug","parent_branch_id":"p-1","project_name":"web","dry_run":true}
+
+{"task":"Add docs","parent_branch_id":"p-2","options":{"exploration_id":"exp-9"}}
+`)
+	tasks, err := ParseJSONLManifest(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	}
+	if tasks[0].ID != "fix-login" || tasks[0].ProjectName != "web" || !tasks[0].OptionBool("dry_run", false) {
+		t.Fatalf("unexpected first task: %#v", tasks[0])
+	}
+	if tasks[1].ID != "task-002" || tasks[1].OptionString("exploration_id", "") != "exp-9" {
+		t.Fatalf("unexpected second task: %#v", tasks[1])
+	}
+}
+
+func TestParseJSONLManifestRequiresParent(t *testing.T) {
+	_, err := ParseJSONLManifest([]byte(`{"task":"x"}`))
+	if err == nil || !strings.Contains(err.Error(), "parent_branch_id") {
+		t.Fatalf("expected parent_branch_id error, got %v", err)
+	}
+}
+
+func TestParseYAMLManifest(t *testing.T) {
+	data := []byte(`tasks:
+  - id: first
+    task: "Fix the flaky test: retry once"
+    parent_branch_id: 123e4567-e89b-12d3-a456-426614174000
+    max_turns: 12
+  - task: |
+      Implement feature X.
+      Include tests.
+    parent_branch_id: 'p-2'
+    options:
+      dry_run: yes
+      dry_run_reviews: issues,clean
+`)
+	tasks, err := ParseYAMLManifest(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	}
+	if tasks[0].Task != "Fix the flaky test: retry once" || tasks[0].ParentBranchID != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Fatalf("unexpected first task: %#v", tasks[0])
+	}
+	if got := tasks[0].OptionInt("max_turns", 0); got != 12 {
+		t.Fatalf("expected max_turns=12, got %d", got)
+	}
+	if tasks[1].Task != "Implement feature X.\nInclude tests." {
+		t.Fatalf("unexpected block scalar: %q", tasks[1].Task)
+	}
+	if !tasks[1].OptionBool("dry_run", false) || tasks[1].OptionString("dry_run_reviews", "") != "issues,clean" {
+		t.Fatalf("unexpected options: %#v", tasks[1].Options)
+	}
+}
+
+func TestManifestKeepsNumericScalarsAsText(t *testing.T) {
+	yamlTasks, err := ParseYAMLManifest([]byte(`- id: 001
+  task: 42
+  parent_branch_id: 12345678901234567890
+- id: "..."
+  task: Fix it
+  parent_branch_id: 7
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	jsonTasks, err := ParseJSONLManifest([]byte(`{"id":1,"task":42,"parent_branch_id":12345678901234567890}` + "\n" +
+		`{"id":"...","task":"Fix it","parent_branch_id":7}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, tasks := range map[string][]Task{"yaml": yamlTasks, "jsonl": jsonTasks} {
+		if tasks[0].Task != "42" || tasks[0].ParentBranchID != "12345678901234567890" {
+			t.Fatalf("%s: numeric scalars not kept as text: %#v", name, tasks[0])
+		}
+		if tasks[1].ID != "task-002" || tasks[1].ParentBranchID != "7" {
+			t.Fatalf("%s: unexpected second task: %#v", name, tasks[1])
+		}
+	}
+	if yamlTasks[0].ID != "001" || jsonTasks[0].ID != "1" {
+		t.Fatalf("unexpected ids %q, %q", yamlTasks[0].ID, jsonTasks[0].ID)
+	}
+}
+
+func TestRunnerBoundsConcurrencyAndWritesArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	tasks := []Task{
+		{ID: "a", Task: "A", ParentBranchID: "p"},
+		{ID: "b", Task: "B", ParentBranchID: "p"},
+		{ID: "c", Task: "C", ParentBranchID: "p"},
+		{ID: "d", Task: "D", ParentBranchID: "p"},
+	}
+	var running, peak int32
+	runner := Runner{
+		OutputDir:   dir,
+		Concurrency: 2,
+		Run: func(task Task, stream io.Writer) (map[string]any, error) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			io.WriteString(stream, `{"type":"thread.started"}`+"\n")
+			if task.ID == "c" {
+				return nil, errors.New("boom")
+			}
+			return map[string]any{"status": "completed", "latest_branch_id": "br-" + task.ID}, nil
+		},
+	}
+
+	results, err := runner.Execute(tasks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if peak > 2 {
+		t.Fatalf("expected at most 2 concurrent runs, saw %d", peak)
+	}
+	if len(results) != 4 || results[2].ID != "c" || results[2].Status != "error" || results[2].Error != "boom" {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	if results[0].LatestBranchID != "br-a" {
+		t.Fatalf("expected latest branch br-a, got %q", results[0].LatestBranchID)
+	}
+
+	stream, err := os.ReadFile(filepath.Join(dir, "a.ndjson"))
+	if err != nil || !strings.Contains(string(stream), "thread.started") {
+		t.Fatalf("expected stream file for a, got %q (%v)", stream, err)
+	}
+	var report map[string]any
+	raw, _ := os.ReadFile(filepath.Join(dir, "c.report.json"))
+	if err := json.Unmarshal(raw, &report); err != nil || report["status"] != "error" || report["task"] != "C" {
+		t.Fatalf("unexpected error report: %s (%v)", raw, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "summary.json")); err != nil {
+		t.Fatalf("expected summary.json: %v", err)
+	}
+
+	var buf bytes.Buffer
+	WriteSummaryTable(&buf, results)
+	if !strings.Contains(buf.String(), "4 task(s): completed=3, error=1") {
+		t.Fatalf("unexpected summary table:\n%s", buf.String())
+	}
+}
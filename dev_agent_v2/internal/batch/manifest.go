@@ -0,0 +1,386 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Task is one manifest entry. Keys other than the well-known ones are kept in
+// Options so each binary can interpret its own agent-specific settings.
+type Task struct {
+	ID             string         `json:"id"`
+	Task           string         `json:"task"`
+	ParentBranchID string         `json:"parent_branch_id"`
+	ProjectName    string         `json:"project_name,omitempty"`
+	Options        map[string]any `json:"options,omitempty"`
+}
+
+// OptionString returns a string option, or def when unset.
+func (t Task) OptionString(key, def string) string {
+	v, ok := t.Options[key]
+	if !ok || v == nil {
+		return def
+	}
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return strings.TrimSpace(fmt.Sprintf("%v", v))
+}
+
+// OptionBool returns a boolean option, or def when unset or unparsable.
+func (t Task) OptionBool(key string, def bool) bool {
+	switch v := t.Options[key].(type) {
+	case bool:
+		return v
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+// OptionInt returns an integer option, or def when unset or unparsable.
+func (t Task) OptionInt(key string, def int) int {
+	switch v := t.Options[key].(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n)
+		}
+		if f, err := v.Float64(); err == nil {
+			return int(f)
+		}
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+// LoadManifest reads a JSONL (.jsonl/.ndjson) or YAML (.yaml/.yml) manifest.
+func LoadManifest(path string) ([]Task, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParseYAMLManifest(data)
+	default:
+		return ParseJSONLManifest(data)
+	}
+}
+
+// ParseJSONLManifest parses one JSON object per line. Blank lines and lines
+// starting with '#' are ignored.
+func ParseJSONLManifest(data []byte) ([]Task, error) {
+	var entries []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Numbers stay json.Number so numeric ids keep their text.
+		var entry map[string]any
+		dec := json.NewDecoder(strings.NewReader(line))
+		dec.UseNumber()
+		if err := dec.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("manifest line %d: %w", lineNo, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return tasksFromEntries(entries)
+}
+
+// ParseYAMLManifest parses a YAML manifest made of a sequence of flat
+// mappings, either at the top level or under a `tasks:` key. Values may be
+// plain or quoted scalars, or `|`/`>` block scalars for multi-line tasks.
+// Nested `options:` mappings are flattened into the entry.
+func ParseYAMLManifest(data []byte) ([]Task, error) {
+	entries, err := parseYAMLSequence(string(data))
+	if err != nil {
+		return nil, err
+	}
+	return tasksFromEntries(entries)
+}
+
+func tasksFromEntries(entries []map[string]any) ([]Task, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("manifest contains no tasks")
+	}
+	tasks := make([]Task, 0, len(entries))
+	seen := map[string]bool{}
+	for i, entry := range entries {
+		task := Task{Options: map[string]any{}}
+		for k, v := range entry {
+			switch k {
+			case "id":
+				task.ID = scalarString(v)
+			case "task":
+				task.Task = scalarString(v)
+			case "parent_branch_id":
+				task.ParentBranchID = scalarString(v)
+			case "project_name":
+				task.ProjectName = scalarString(v)
+			case "options":
+				opts, ok := v.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("manifest entry %d: options must be an object", i+1)
+				}
+				for ok, ov := range opts {
+					task.Options[ok] = ov
+				}
+			default:
+				task.Options[k] = v
+			}
+		}
+		if task.Task == "" {
+			return nil, fmt.Errorf("manifest entry %d: task is required", i+1)
+		}
+		if task.ParentBranchID == "" {
+			return nil, fmt.Errorf("manifest entry %d: parent_branch_id is required", i+1)
+		}
+		if task.ID = sanitizeID(task.ID); task.ID == "" {
+			task.ID = fmt.Sprintf("task-%03d", i+1)
+		}
+		if seen[task.ID] {
+			return nil, fmt.Errorf("manifest entry %d: duplicate id %q", i+1, task.ID)
+		}
+		seen[task.ID] = true
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// scalarString returns a scalar manifest value as text. Numbers arrive as
+// json.Number, so `id: 001` stays "001" and long branch ids are not rounded.
+func scalarString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	}
+	return strings.TrimSpace(fmt.Sprintf("%v", v))
+}
+
+var (
+	unsafeIDChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	yamlNumber    = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+)
+
+// sanitizeID keeps ids usable as file names for per-run artifacts.
+func sanitizeID(id string) string {
+	id = unsafeIDChars.ReplaceAllString(strings.TrimSpace(id), "-")
+	return strings.Trim(id, "-.")
+}
+
+type yamlLine struct {
+	no     int
+	indent int
+	text   string
+}
+
+func parseYAMLSequence(src string) ([]map[string]any, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "---" {
+			continue
+		}
+		lines = append(lines, yamlLine{no: i + 1, indent: len(raw) - len(strings.TrimLeft(raw, " ")), text: strings.TrimRight(raw, " \t")})
+	}
+
+	var (
+		entries   []map[string]any
+		current   map[string]any
+		target    map[string]any
+		itemKeyAt = -1
+		nestedAt  = -1
+	)
+	for i := 0; i < len(lines); i++ {
+		ln := lines[i]
+		body := strings.TrimSpace(ln.text)
+		if body == "" || strings.HasPrefix(body, "#") {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimLeft(ln.text, " "), "\t") {
+			return nil, fmt.Errorf("manifest line %d: tabs are not allowed for indentation", ln.no)
+		}
+
+		indent := ln.indent
+		if strings.HasPrefix(body, "- ") || body == "-" {
+			current = map[string]any{}
+			target = current
+			entries = append(entries, current)
+			nestedAt = -1
+			body = strings.TrimSpace(strings.TrimPrefix(body, "-"))
+			indent = ln.indent + 1 + (len(ln.text[ln.indent+1:]) - len(strings.TrimLeft(ln.text[ln.indent+1:], " ")))
+			itemKeyAt = indent
+			if body == "" {
+				continue
+			}
+		} else if current == nil {
+			key, value, ok := splitYAMLKey(body)
+			if ok && key == "tasks" && value == "" {
+				continue
+			}
+			return nil, fmt.Errorf("manifest line %d: expected a sequence of tasks", ln.no)
+		} else if nestedAt >= 0 && indent >= nestedAt {
+			// still inside a nested options mapping
+		} else if indent == itemKeyAt {
+			target = current
+			nestedAt = -1
+		} else {
+			return nil, fmt.Errorf("manifest line %d: unexpected indentation", ln.no)
+		}
+
+		key, value, ok := splitYAMLKey(body)
+		if !ok {
+			return nil, fmt.Errorf("manifest line %d: expected key: value", ln.no)
+		}
+		if value == "" && nestedAt < 0 {
+			// Either a nested mapping (options:) or an empty value.
+			if next := nextContentLine(lines, i+1); next >= 0 && lines[next].indent > indent && !strings.HasPrefix(strings.TrimSpace(lines[next].text), "- ") {
+				nested := map[string]any{}
+				current[key] = nested
+				target = nested
+				nestedAt = lines[next].indent
+				continue
+			}
+		}
+		if value == "|" || value == ">" || value == "|-" || value == ">-" {
+			block, consumed := collectBlockScalar(lines[i+1:], indent, strings.HasPrefix(value, ">"))
+			i += consumed
+			target[key] = block
+			continue
+		}
+		parsed, err := parseYAMLScalar(value)
+		if err != nil {
+			return nil, fmt.Errorf("manifest line %d: %w", ln.no, err)
+		}
+		target[key] = parsed
+	}
+	return entries, nil
+}
+
+func nextContentLine(lines []yamlLine, from int) int {
+	for j := from; j < len(lines); j++ {
+		body := strings.TrimSpace(lines[j].text)
+		if body != "" && !strings.HasPrefix(body, "#") {
+			return j
+		}
+	}
+	return -1
+}
+
+func collectBlockScalar(lines []yamlLine, parentIndent int, folded bool) (string, int) {
+	var parts []string
+	blockIndent := -1
+	consumed := 0
+	for _, ln := range lines {
+		if strings.TrimSpace(ln.text) == "" {
+			parts = append(parts, "")
+			consumed++
+			continue
+		}
+		if ln.indent <= parentIndent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = ln.indent
+		}
+		parts = append(parts, ln.text[minInt(blockIndent, ln.indent):])
+		consumed++
+	}
+	// Trailing blank lines belong to whatever follows the block.
+	for len(parts) > 0 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+		consumed--
+	}
+	sep := "\n"
+	if folded {
+		sep = " "
+	}
+	return strings.Join(parts, sep), consumed
+}
+
+func splitYAMLKey(body string) (string, string, bool) {
+	idx := strings.Index(body, ":")
+	if idx <= 0 {
+		return "", "", false
+	}
+	if idx+1 < len(body) && body[idx+1] != ' ' {
+		return "", "", false
+	}
+	key := strings.Trim(strings.TrimSpace(body[:idx]), `"'`)
+	return key, strings.TrimSpace(body[idx+1:]), true
+}
+
+func parseYAMLScalar(value string) (any, error) {
+	if value == "" {
+		return "", nil
+	}
+	switch value[0] {
+	case '"':
+		var s string
+		end := strings.LastIndex(value, `"`)
+		if end <= 0 {
+			return nil, fmt.Errorf("unterminated quoted string %s", value)
+		}
+		if err := json.Unmarshal([]byte(value[:end+1]), &s); err != nil {
+			return nil, fmt.Errorf("invalid quoted string %s: %w", value, err)
+		}
+		return s, nil
+	case '\'':
+		end := strings.LastIndex(value, "'")
+		if end <= 0 {
+			return nil, fmt.Errorf("unterminated quoted string %s", value)
+		}
+		return strings.ReplaceAll(value[1:end], "''", "'"), nil
+	}
+	if idx := strings.Index(value, " #"); idx >= 0 {
+		value = strings.TrimSpace(value[:idx])
+	}
+	switch strings.ToLower(value) {
+	case "true", "yes":
+		return true, nil
+	case "false", "no":
+		return false, nil
+	case "null", "~":
+		return nil, nil
+	}
+	if yamlNumber.MatchString(value) {
+		return json.Number(value), nil
+	}
+	return value, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
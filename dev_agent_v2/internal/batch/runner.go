@@ -0,0 +1,171 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"dev_agent_v2/internal/logx"
+)
+
+const (
+	defaultConcurrency = 2
+	statusError        = "error"
+	summaryFileName    = "summary.json"
+)
+
+// RunFunc executes a single manifest task. NDJSON events for the run must be
+// written to stream; the returned report is persisted next to it.
+type RunFunc func(task Task, stream io.Writer) (map[string]any, error)
+
+// Runner fans manifest tasks out to RunFunc with a bounded number of
+// concurrent runs. Every run gets its own stream file and report under
+// OutputDir.
+type Runner struct {
+	OutputDir   string
+	Concurrency int
+	Run         RunFunc
+}
+
+// Result summarizes one manifest task.
+type Result struct {
+	ID             string  `json:"id"`
+	Task           string  `json:"task"`
+	Status         string  `json:"status"`
+	Summary        string  `json:"summary,omitempty"`
+	Error          string  `json:"error,omitempty"`
+	LatestBranchID string  `json:"latest_branch_id,omitempty"`
+	DurationSecs   float64 `json:"duration_seconds"`
+	StreamPath     string  `json:"stream_path"`
+	ReportPath     string  `json:"report_path"`
+}
+
+// Execute runs every task and returns the results in manifest order. Failures
+// of individual runs are recorded in their Result; only setup problems (for
+// example an unwritable output directory) are returned as errors.
+func (r Runner) Execute(tasks []Task) ([]Result, error) {
+	if r.Run == nil {
+		return nil, fmt.Errorf("batch runner requires a RunFunc")
+	}
+	dir := strings.TrimSpace(r.OutputDir)
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create output dir: %w", err)
+	}
+	limit := r.Concurrency
+	if limit <= 0 {
+		limit = defaultConcurrency
+	}
+
+	results := make([]Result, len(tasks))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, task Task) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = r.runOne(dir, task)
+		}(i, task)
+	}
+	wg.Wait()
+
+	summary, _ := json.MarshalIndent(results, "", "  ")
+	if err := os.WriteFile(filepath.Join(dir, summaryFileName), summary, 0o644); err != nil {
+		return results, fmt.Errorf("write batch summary: %w", err)
+	}
+	return results, nil
+}
+
+func (r Runner) runOne(dir string, task Task) (res Result) {
+	res = Result{
+		ID:         task.ID,
+		Task:       task.Task,
+		StreamPath: filepath.Join(dir, task.ID+".ndjson"),
+		ReportPath: filepath.Join(dir, task.ID+".report.json"),
+	}
+	start := time.Now()
+	defer func() {
+		res.DurationSecs = time.Since(start).Round(time.Second).Seconds()
+	}()
+
+	logx.Infof("Batch task %s started (parent=%s)", task.ID, task.ParentBranchID)
+	stream, err := os.Create(res.StreamPath)
+	if err != nil {
+		res.Status = statusError
+		res.Error = fmt.Sprintf("create stream file: %v", err)
+		return res
+	}
+	defer stream.Close()
+
+	report, runErr := r.safeRun(task, stream)
+	if report == nil {
+		report = map[string]any{}
+	}
+	if runErr != nil {
+		report["status"] = statusError
+		report["error"] = runErr.Error()
+		if _, ok := report["task"]; !ok {
+			report["task"] = task.Task
+		}
+		res.Error = runErr.Error()
+	}
+	res.Status, _ = report["status"].(string)
+	if res.Status == "" {
+		res.Status = "unknown"
+	}
+	res.Summary, _ = report["summary"].(string)
+	res.LatestBranchID, _ = report["latest_branch_id"].(string)
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	if err := os.WriteFile(res.ReportPath, out, 0o644); err != nil && res.Error == "" {
+		res.Error = fmt.Sprintf("write report: %v", err)
+	}
+	logx.Infof("Batch task %s finished (status=%s)", task.ID, res.Status)
+	return res
+}
+
+// safeRun keeps a panicking run from taking the whole batch down.
+func (r Runner) safeRun(task Task, stream io.Writer) (report map[string]any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("run panicked: %v", p)
+		}
+	}()
+	return r.Run(task, stream)
+}
+
+// WriteSummaryTable prints an aligned table of results plus status totals.
+func WriteSummaryTable(w io.Writer, results []Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tDURATION\tLATEST BRANCH\tREPORT")
+	counts := map[string]int{}
+	var order []string
+	for _, res := range results {
+		branch := res.LatestBranchID
+		if branch == "" {
+			branch = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", res.ID, res.Status, time.Duration(res.DurationSecs*float64(time.Second)), branch, res.ReportPath)
+		if counts[res.Status] == 0 {
+			order = append(order, res.Status)
+		}
+		counts[res.Status]++
+	}
+	tw.Flush()
+
+	parts := make([]string, 0, len(order))
+	for _, status := range order {
+		parts = append(parts, fmt.Sprintf("%s=%d", status, counts[status]))
+	}
+	fmt.Fprintf(w, "\n%d task(s): %s\n", len(results), strings.Join(parts, ", "))
+}
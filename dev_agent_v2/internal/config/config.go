@@ -28,6 +28,7 @@ type AgentConfig struct {
 	WorklogFilename   string
 	ProjectName       string
 	WorkspaceDir      string
+	AgentRegistryFile string // optional JSON agent registry (AGENT_REGISTRY_FILE)
 }
 
 func FromEnv() (AgentConfig, error) {
@@ -116,6 +117,7 @@ func FromEnv() (AgentConfig, error) {
 		WorklogFilename:   "worklog.md",
 		ProjectName:       project,
 		WorkspaceDir:      workspace,
+		AgentRegistryFile: strings.TrimSpace(os.Getenv("AGENT_REGISTRY_FILE")),
 	}, nil
 }
 
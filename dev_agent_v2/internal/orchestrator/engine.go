@@ -0,0 +1,229 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	b "dev_agent_v2/internal/brain"
+	"dev_agent_v2/internal/logx"
+
+	t "dev_agent_v2/internal/tools"
+)
+
+// completeFunc requests one assistant message for the conversation so far.
+type completeFunc func(messages []b.ChatMessage, tools []map[string]any) (b.ChatMessage, error)
+
+func brainCompleter(brain *b.LLMBrain) completeFunc {
+	return func(messages []b.ChatMessage, tools []map[string]any) (b.ChatMessage, error) {
+		resp, err := brain.Complete(messages, tools)
+		if err != nil {
+			return b.ChatMessage{}, err
+		}
+		if len(resp.Choices) == 0 {
+			return b.ChatMessage{}, errors.New("LLM response contained no choices")
+		}
+		return resp.Choices[0].Message, nil
+	}
+}
+
+type toolHandler interface {
+	Handle(t.ToolCall) map[string]any
+}
+
+// engine runs the playbook turn loop. Orchestrate and ChatLoop differ only in
+// the observers they register.
+type engine struct {
+	complete completeFunc
+	handler  toolHandler
+	tools    []map[string]any
+	task     string
+	maxTurns int
+	obs      observerList
+
+	messages       []b.ChatMessage
+	iteration      int
+	totalToolCalls int
+	nextItem       int
+}
+
+func newEngine(complete completeFunc, handler toolHandler, messages []b.ChatMessage, opts RunOptions) *engine {
+	maxTurns := opts.MaxTurns
+	if maxTurns <= 0 {
+		maxTurns = defaultMaxTurns
+	}
+	var obs observerList
+	if so := NewStreamObserver(opts.Streamer); so != nil {
+		obs = append(obs, so)
+	}
+	for _, o := range opts.Observers {
+		if o != nil {
+			obs = append(obs, o)
+		}
+	}
+	return &engine{
+		complete: complete,
+		handler:  handler,
+		tools:    t.GetToolDefinitions(),
+		task:     opts.Task,
+		maxTurns: maxTurns,
+		obs:      obs,
+		messages: messages,
+	}
+}
+
+// Orchestrate runs the workflow headless; progress goes to logx and the
+// configured observers.
+func Orchestrate(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMessage, opts RunOptions) (map[string]any, error) {
+	return newEngine(brainCompleter(brain), handler, messages, opts).run()
+}
+
+// ChatLoop runs the same workflow as Orchestrate with a console transcript
+// on stdout. maxIters overrides opts.MaxTurns when positive.
+func ChatLoop(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMessage, maxIters int, opts RunOptions) (map[string]any, error) {
+	if maxIters > 0 {
+		opts.MaxTurns = maxIters
+	}
+	opts.Observers = append([]Observer{NewConsoleObserver(nil)}, opts.Observers...)
+	return newEngine(brainCompleter(brain), handler, messages, opts).run()
+}
+
+func (e *engine) turn() Turn {
+	return Turn{
+		ID:           fmt.Sprintf("turn_%d", e.iteration),
+		Iteration:    e.iteration,
+		MessageCount: len(e.messages),
+		ToolCalls:    e.totalToolCalls,
+		Messages:     e.messages,
+	}
+}
+
+func (e *engine) newItemID() string {
+	e.nextItem++
+	return fmt.Sprintf("item_%d", e.nextItem)
+}
+
+func (e *engine) run() (map[string]any, error) {
+	var (
+		finalReport           map[string]any
+		finished              bool
+		errorState            bool
+		executedToolCalls     int
+		consecutiveRetryTurns int
+	)
+
+	for e.iteration < e.maxTurns {
+		e.iteration++
+		logx.Infof("LLM iteration %d", e.iteration)
+		turnID := e.turn().ID
+		e.obs.TurnStarted(e.turn())
+		choice, err := e.complete(e.messages, e.tools)
+		if err != nil {
+			e.obs.Error("llm.complete", err.Error(), map[string]any{"iteration": e.iteration, "turn_id": turnID})
+			return nil, err
+		}
+		e.messages = append(e.messages, assistantMessageToDict(choice))
+		e.obs.AssistantMessage(e.turn(), choice)
+
+		if len(choice.ToolCalls) > 0 {
+			turnToolCount := len(choice.ToolCalls)
+			e.totalToolCalls += turnToolCount
+
+			// Enforce "single tool call per turn" in code (not just in the system prompt).
+			if turnToolCount != 1 {
+				msg := fmt.Sprintf("policy violation: expected exactly 1 tool call, got %d. No tool calls executed. Please retry with a single tool call.", turnToolCount)
+				e.obs.Error("tool_policy", msg, map[string]any{"tool_call_count": turnToolCount, "turn_id": turnID})
+				consecutiveRetryTurns++
+				for _, tc := range choice.ToolCalls {
+					synth := map[string]any{
+						"status": "error",
+						"error": map[string]any{
+							"message": msg,
+						},
+					}
+					e.messages = append(e.messages, b.ChatMessage{Role: "tool", ToolCallID: tc.ID, Content: toJSON(synth)})
+				}
+				e.obs.TurnCompleted(e.turn(), turnToolCount, false)
+				if consecutiveRetryTurns >= defaultMaxRetryTurns {
+					finalReport = buildErrorFinalReport(e.task, "Too many policy-violation retries; aborting.", "", map[string]any{"turn_id": turnID})
+					finished = true
+					errorState = true
+					break
+				}
+				continue
+			}
+
+			consecutiveRetryTurns = 0
+			tc := choice.ToolCalls[0]
+			ev := &ToolEvent{
+				ItemID:  e.newItemID(),
+				Kind:    "tool_call",
+				Name:    tc.Function.Name,
+				RawArgs: tc.Function.Arguments,
+				Args:    parseToolArgs(tc.Function.Arguments),
+			}
+			e.obs.ToolStarted(e.turn(), ev)
+			htc := t.ToolCall{ID: tc.ID, Type: tc.Type}
+			htc.Function.Name = tc.Function.Name
+			htc.Function.Arguments = tc.Function.Arguments
+			start := time.Now()
+			result := e.handler.Handle(htc)
+			ev.Duration = time.Since(start)
+			executedToolCalls++
+			e.messages = append(e.messages, b.ChatMessage{Role: "tool", ToolCallID: tc.ID, Content: toJSON(result)})
+			ev.Result = result
+			ev.Status = resultStatus(result)
+			ev.BranchID = eventBranchID(result)
+			ev.Summary = summarizeToolResult(result)
+			e.obs.ToolCompleted(e.turn(), ev)
+
+			if instr, summaryMsg, details := toolInstruction(result); instr != "" {
+				e.obs.Error("tool_instruction", summaryMsg, map[string]any{"instruction": instr})
+				finalReport = buildErrorFinalReport(e.task, summaryMsg, instr, details)
+				finished = true
+				errorState = true
+				e.obs.TurnCompleted(e.turn(), turnToolCount, false)
+				break
+			}
+			e.obs.TurnCompleted(e.turn(), turnToolCount, false)
+			if executedToolCalls >= defaultMaxToolCalls {
+				logx.Errorf("Reached tool-call limit without final report.")
+				e.obs.Note(e.turn(), fmt.Sprintf("reached tool-call limit (%d) without final report", defaultMaxToolCalls))
+				break
+			}
+			continue
+		}
+
+		hasFinal := false
+		if fr, ok := ParseFinalReport(choice); ok {
+			finalReport = fr
+			finished = true
+			hasFinal = true
+		} else {
+			logx.Infof("Assistant response was not a final report; continuing.")
+		}
+		e.obs.TurnCompleted(e.turn(), 0, hasFinal)
+		if finished {
+			break
+		}
+	}
+
+	if finished {
+		if errorState {
+			ensureReportDefaults(finalReport, e.task, statusFinishedWithError, true)
+		} else {
+			ensureReportDefaults(finalReport, e.task, statusCompleted, true)
+		}
+		e.obs.Finished(finalReport)
+		return finalReport, nil
+	}
+
+	finalReport = map[string]any{
+		"is_finished": false,
+		"status":      statusIterationLimit,
+		"task":        e.task,
+		"summary":     iterationLimitSummary,
+	}
+	e.obs.Finished(finalReport)
+	return finalReport, nil
+}
@@ -0,0 +1,191 @@
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	b "dev_agent_v2/internal/brain"
+	t "dev_agent_v2/internal/tools"
+)
+
+type scriptedCompleter struct {
+	replies []b.ChatMessage
+	calls   int
+}
+
+func (s *scriptedCompleter) complete(_ []b.ChatMessage, _ []map[string]any) (b.ChatMessage, error) {
+	if s.calls >= len(s.replies) {
+		return b.ChatMessage{}, fmt.Errorf("unexpected completion %d", s.calls+1)
+	}
+	reply := s.replies[s.calls]
+	s.calls++
+	return reply, nil
+}
+
+type fakeEngineHandler struct {
+	calls []t.ToolCall
+}
+
+func (f *fakeEngineHandler) Handle(call t.ToolCall) map[string]any {
+	f.calls = append(f.calls, call)
+	id := fmt.Sprintf("branch-%d", len(f.calls))
+	return map[string]any{
+		"status": "success",
+		"data":   map[string]any{"branch_id": id, "response": "done " + id},
+	}
+}
+
+type recordingObserver struct {
+	NopObserver
+	events []string
+	final  map[string]any
+}
+
+func (r *recordingObserver) TurnStarted(turn Turn) {
+	r.events = append(r.events, "turn_started:"+turn.ID)
+}
+
+func (r *recordingObserver) ToolCompleted(_ Turn, ev *ToolEvent) {
+	r.events = append(r.events, "tool_completed:"+ev.ItemID+":"+ev.Status+":"+ev.BranchID)
+}
+
+func (r *recordingObserver) TurnCompleted(turn Turn, toolCalls int, hasFinal bool) {
+	r.events = append(r.events, fmt.Sprintf("turn_completed:%s:%d:%v", turn.ID, toolCalls, hasFinal))
+}
+
+func (r *recordingObserver) Error(scope, _ string, _ map[string]any) {
+	r.events = append(r.events, "error:"+scope)
+}
+
+func (r *recordingObserver) Finished(report map[string]any) {
+	r.final = report
+}
+
+func agentCall(id string) b.ToolCall {
+	args, _ := json.Marshal(map[string]any{"agent": "codex", "prompt": "do it", "parent_branch_id": "p"})
+	call := b.ToolCall{ID: id, Type: "function"}
+	call.Function.Name = "execute_agent"
+	call.Function.Arguments = string(args)
+	return call
+}
+
+func assistantCalls(calls ...b.ToolCall) b.ChatMessage {
+	return b.ChatMessage{Role: "assistant", ToolCalls: calls}
+}
+
+func workflowScript() *scriptedCompleter {
+	return &scriptedCompleter{replies: []b.ChatMessage{
+		assistantCalls(agentCall("call_1")),
+		{Role: "assistant", Content: `{"is_finished": true, "summary": "PR opened"}`},
+	}}
+}
+
+func TestEngineRunsUntilFinalReport(t *testing.T) {
+	handler := &fakeEngineHandler{}
+	rec := &recordingObserver{}
+	opts := RunOptions{Task: "fix it", Observers: []Observer{rec}}
+
+	report, err := newEngine(workflowScript().complete, handler, nil, opts).run()
+	if err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	if report["status"] != statusCompleted || report["task"] != "fix it" {
+		t.Fatalf("unexpected report %#v", report)
+	}
+	want := []string{
+		"turn_started:turn_1", "tool_completed:item_1:success:branch-1", "turn_completed:turn_1:1:false",
+		"turn_started:turn_2", "turn_completed:turn_2:0:true",
+	}
+	if strings.Join(rec.events, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected event sequence:\n%s", strings.Join(rec.events, "\n"))
+	}
+	if rec.final == nil {
+		t.Fatalf("expected Finished to receive the report")
+	}
+}
+
+func TestEngineRejectsBatchedToolCallsUntilRetryLimit(t *testing.T) {
+	var replies []b.ChatMessage
+	for i := 0; i < defaultMaxRetryTurns; i++ {
+		replies = append(replies, assistantCalls(agentCall(fmt.Sprintf("a%d", i)), agentCall(fmt.Sprintf("b%d", i))))
+	}
+	handler := &fakeEngineHandler{}
+	rec := &recordingObserver{}
+	opts := RunOptions{Task: "fix it", Observers: []Observer{rec}}
+
+	report, err := newEngine((&scriptedCompleter{replies: replies}).complete, handler, nil, opts).run()
+	if err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	if report["status"] != statusFinishedWithError {
+		t.Fatalf("expected FINISHED_WITH_ERROR, got %#v", report["status"])
+	}
+	if len(handler.calls) != 0 {
+		t.Fatalf("batched tool calls must not execute, got %d", len(handler.calls))
+	}
+	if !strings.Contains(strings.Join(rec.events, "\n"), "error:tool_policy") {
+		t.Fatalf("expected tool_policy error events, got %v", rec.events)
+	}
+}
+
+func TestEngineHonorsMaxTurns(t *testing.T) {
+	script := &scriptedCompleter{replies: []b.ChatMessage{
+		{Role: "assistant", Content: "thinking"},
+		{Role: "assistant", Content: "still thinking"},
+	}}
+	report, err := newEngine(script.complete, &fakeEngineHandler{}, nil, RunOptions{Task: "fix it", MaxTurns: 2}).run()
+	if err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	if report["status"] != statusIterationLimit {
+		t.Fatalf("expected iteration limit, got %#v", report["status"])
+	}
+}
+
+func TestConsoleObserverMatchesInteractiveTranscript(t *testing.T) {
+	var out bytes.Buffer
+	opts := RunOptions{Task: "fix it", Observers: []Observer{NewConsoleObserver(&out)}}
+	if _, err := newEngine(workflowScript().complete, &fakeEngineHandler{}, nil, opts).run(); err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	got := out.String()
+	for _, needle := range []string{
+		"[iter 1] requesting completion...",
+		"tool> execute_agent ",
+		"tool< {",
+		"assistant< final_report",
+	} {
+		if !strings.Contains(got, needle) {
+			t.Fatalf("console transcript missing %q:\n%s", needle, got)
+		}
+	}
+}
+
+func TestCheckpointObserverWritesConversation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	opts := RunOptions{Task: "fix it", Observers: []Observer{NewCheckpointObserver(path)}}
+	initial := []b.ChatMessage{{Role: "system", Content: "sys"}}
+	if _, err := newEngine(workflowScript().complete, &fakeEngineHandler{}, initial, opts).run(); err != nil {
+		t.Fatalf("run returned error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read checkpoint: %v", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		t.Fatalf("decode checkpoint: %v", err)
+	}
+	if !cp.Finished || cp.Report["status"] != statusCompleted {
+		t.Fatalf("expected finished checkpoint with report, got %+v", cp)
+	}
+	// system + assistant + tool + final assistant
+	if len(cp.Messages) != 4 {
+		t.Fatalf("expected 4 messages in checkpoint, got %d", len(cp.Messages))
+	}
+}
@@ -0,0 +1,174 @@
+package streaming
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaDef struct {
+	Required   []string                   `json:"required"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+func loadSchema(t *testing.T) (schemaDef, map[string]schemaDef) {
+	t.Helper()
+	var doc struct {
+		schemaDef
+		Defs map[string]schemaDef `json:"$defs"`
+	}
+	if err := json.Unmarshal(Schema, &doc); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	return doc.schemaDef, doc.Defs
+}
+
+// jsonFields lists the JSON names of v's fields outside embedded structs and
+// the ones that are always present.
+func jsonFields(v any) (all, required []string) {
+	rt := reflect.TypeOf(v)
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.Anonymous {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		all = append(all, name)
+		if opts != "omitempty" {
+			required = append(required, name)
+		}
+	}
+	return all, required
+}
+
+func keys(m map[string]json.RawMessage) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+func sameSet(a, b []string) bool {
+	a, b = append([]string(nil), a...), append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
+
+func TestEventStructsMatchSchema(t *testing.T) {
+	envelope, defs := loadSchema(t)
+	all, required := jsonFields(Envelope{})
+	if !sameSet(all, keys(envelope.Properties)) || !sameSet(required, envelope.Required) {
+		t.Errorf("envelope fields %v (required %v) differ from schema %v (required %v)", all, required, keys(envelope.Properties), envelope.Required)
+	}
+	if !bytes.Contains(Schema, []byte(`"^`+strings.Split(SchemaVersion, ".")[0]+`\\.[0-9]+$"`)) {
+		t.Errorf("schema_version pattern does not match major version of %s", SchemaVersion)
+	}
+	types := []string{EventThreadStarted, EventTurnStarted, EventAssistantMessage, EventTurnCompleted, EventItemStarted, EventItemCompleted, EventThreadCompleted, EventError}
+	if len(defs) != len(types) {
+		t.Errorf("schema defines %d event types, Go defines %d", len(defs), len(types))
+	}
+	for _, typ := range types {
+		def, ok := defs[typ]
+		ev := NewEvent(typ)
+		if !ok || ev == nil {
+			t.Errorf("%s: in schema %v, NewEvent %T", typ, ok, ev)
+			continue
+		}
+		all, required := jsonFields(ev)
+		if !sameSet(all, keys(def.Properties)) || !sameSet(required, def.Required) {
+			t.Errorf("%s: fields %v (required %v) differ from schema %v (required %v)", typ, all, required, keys(def.Properties), def.Required)
+		}
+	}
+}
+
+func TestEmittedEventsAreVersionedAndTyped(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewJSONStreamer(true, &buf)
+	s.SetAgent(AgentVerify)
+	s.EmitThreadStarted("task", "proj", "parent", true)
+	s.EmitTurnStarted("turn_1", 1, 2, 3)
+	s.EmitAssistantMessage("turn_1", strings.Repeat("x", 600), 0)
+	s.EmitTurnCompleted("turn_1", 1, 0, true)
+	s.EmitItemStarted("item_1", "tool_call", "execute_agent", nil)
+	s.EmitItemCompleted("item_1", "success", 1500*time.Millisecond, "b1", "")
+	s.EmitError("llm.complete", "boom", map[string]any{"iteration": 2, "turn_id": "turn_1", "attempt": 3})
+	s.EmitThreadCompleted("completed", "done", map[string]any{"status": "completed"})
+
+	_, defs := loadSchema(t)
+	sc := bufio.NewScanner(&buf)
+	var seq int64
+	for sc.Scan() {
+		seq++
+		var raw map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &raw); err != nil {
+			t.Fatalf("line %d: %v", seq, err)
+		}
+		var env Envelope
+		json.Unmarshal(sc.Bytes(), &env)
+		if env.SchemaVersion != SchemaVersion || env.Sequence != seq || env.Agent != AgentVerify || env.ThreadID != s.ThreadID() {
+			t.Errorf("line %d: unexpected envelope %+v", seq, env)
+		}
+		for _, field := range defs[env.Type].Required {
+			if _, ok := raw[field]; !ok {
+				t.Errorf("%s lacks required field %s", env.Type, field)
+			}
+		}
+		ev := NewEvent(env.Type)
+		if err := json.Unmarshal(sc.Bytes(), ev); err != nil {
+			t.Fatalf("%s: %v", env.Type, err)
+		}
+		switch ev := ev.(type) {
+		case *AssistantMessage:
+			if !ev.Truncated || len(ev.Preview) != assistantPreviewLimit {
+				t.Errorf("long previews must be truncated, got %d chars", len(ev.Preview))
+			}
+		case *Error:
+			if ev.Iteration != 2 || ev.TurnID != "turn_1" || ev.Details["attempt"] != float64(3) {
+				t.Errorf("unexpected error event %+v", ev)
+			}
+		case *ThreadCompleted:
+			if string(ev.FinalReport) != `{"status":"completed"}` {
+				t.Errorf("unexpected final report %s", ev.FinalReport)
+			}
+		}
+	}
+	if seq != 8 {
+		t.Fatalf("expected 8 events, got %d", seq)
+	}
+}
+
+// TestCopiesMatchVerifyAgent keeps the copies of this package in the other
+// modules identical to verify_agent's, which streamclient is built on.
+func TestCopiesMatchVerifyAgent(t *testing.T) {
+	canonical := filepath.Join("..", "..", "..", "verify_agent", "internal", "streaming")
+	if _, err := os.Stat(canonical); err != nil {
+		t.Skipf("verify_agent is not checked out next to this module: %v", err)
+	}
+	files, err := filepath.Glob("*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := filepath.Glob(filepath.Join(canonical, "*"))
+	if len(files) != len(want) {
+		t.Errorf("package has %d files, verify_agent's copy has %d", len(files), len(want))
+	}
+	for _, name := range files {
+		got, _ := os.ReadFile(name)
+		ref, err := os.ReadFile(filepath.Join(canonical, name))
+		if err != nil || !bytes.Equal(got, ref) {
+			t.Errorf("%s differs from verify_agent/internal/streaming/%s; copy the change to every module", name, name)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	DryRunReviewIssues = "issues"
+	DryRunReviewClean  = "clean"
+
+	dryRunBranchPrefix = "dryrun-branch-"
+)
+
+// DefaultDryRunReviewScript reports issues on the first review round and a
+// clean review afterwards, which exercises one full Review→Fix cycle.
+var DefaultDryRunReviewScript = []string{DryRunReviewIssues, DryRunReviewClean}
+
+// DryRunClient simulates Pantheon for --dry-run. Branches are synthetic and
+// complete immediately, agents answer with canned output, and review_code runs
+// follow a scripted sequence of review reports. Nothing leaves the process.
+type DryRunClient struct {
+	mu           sync.Mutex
+	workspaceDir string
+	reviewScript []string
+	reviewRuns   int
+	seq          int
+	branches     map[string]*dryRunBranch
+}
+
+type dryRunBranch struct {
+	id     string
+	parent string
+	agent  string
+	output string
+	files  map[string]string
+}
+
+var _ agentClient = (*DryRunClient)(nil)
+
+// NewDryRunClient returns a simulated client. review_code runs consume the
+// script in order; once it is exhausted the last entry repeats. An empty
+// script falls back to DefaultDryRunReviewScript.
+func NewDryRunClient(workspaceDir string, reviewScript []string) *DryRunClient {
+	script := append([]string(nil), reviewScript...)
+	if len(script) == 0 {
+		script = append(script, DefaultDryRunReviewScript...)
+	}
+	return &DryRunClient{
+		workspaceDir: strings.TrimSpace(workspaceDir),
+		reviewScript: script,
+		branches:     map[string]*dryRunBranch{},
+	}
+}
+
+// ParseDryRunReviewScript parses a comma-separated review script such as
+// "issues,issues,clean".
+func ParseDryRunReviewScript(raw string) ([]string, error) {
+	var script []string
+	for _, part := range strings.Split(raw, ",") {
+		step := stringsTrimLower(part)
+		if step == "" {
+			continue
+		}
+		if step != DryRunReviewIssues && step != DryRunReviewClean {
+			return nil, fmt.Errorf("invalid dry-run review step %q (expected %q or %q)", part, DryRunReviewIssues, DryRunReviewClean)
+		}
+		script = append(script, step)
+	}
+	return script, nil
+}
+
+func (c *DryRunClient) ParallelExplore(projectName, parentBranchID string, prompts []string, agent string, numBranches int) (map[string]any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	branch := &dryRunBranch{
+		id:     fmt.Sprintf("%s%04d", dryRunBranchPrefix, c.seq),
+		parent: parentBranchID,
+		agent:  agent,
+		files:  map[string]string{},
+	}
+	prompt := ""
+	if len(prompts) > 0 {
+		prompt = prompts[0]
+	}
+
+	if agent == reviewCodeAgent {
+		step := c.reviewScript[len(c.reviewScript)-1]
+		if c.reviewRuns < len(c.reviewScript) {
+			step = c.reviewScript[c.reviewRuns]
+		}
+		c.reviewRuns++
+		report := dryRunReviewReport(step, c.reviewRuns)
+		if c.workspaceDir != "" {
+			branch.files[filepath.Join(c.workspaceDir, reviewArtifactName)] = report
+		}
+		branch.output = fmt.Sprintf("[dry-run] review_code round %d finished.\n\n%s", c.reviewRuns, report)
+	} else {
+		branch.output = fmt.Sprintf("[dry-run] Simulated %s run for project %s from parent %s.\nNo changes were made; this branch only exists in the dry-run simulation.\n\nPrompt preview: %s",
+			agent, projectName, parentBranchID, dryRunPreview(prompt))
+	}
+	c.branches[branch.id] = branch
+
+	return map[string]any{
+		"dry_run":  true,
+		"branches": []any{map[string]any{"branch_id": branch.id, "parent_id": parentBranchID, "agent": agent}},
+	}, nil
+}
+
+func (c *DryRunClient) GetBranch(branchID string) (map[string]any, error) {
+	branch, ok := c.branch(branchID)
+	if !ok {
+		return map[string]any{"error": fmt.Sprintf("branch %s not found (dry-run)", branchID)}, nil
+	}
+	return map[string]any{
+		"id":             branch.id,
+		"status":         "succeed",
+		"latest_snap_id": "snap-" + branch.id,
+		"agent":          branch.agent,
+		"dry_run":        true,
+	}, nil
+}
+
+func (c *DryRunClient) BranchReadFile(branchID, filePath string) (map[string]any, error) {
+	branch, ok := c.branch(branchID)
+	if !ok {
+		return nil, fmt.Errorf("404 branch %s not found (dry-run)", branchID)
+	}
+	content, ok := branch.files[filePath]
+	if !ok {
+		return nil, fmt.Errorf("404 file %s not found on branch %s (dry-run)", filePath, branchID)
+	}
+	return map[string]any{"branch_id": branchID, "path": filePath, "content": content}, nil
+}
+
+func (c *DryRunClient) BranchOutput(branchID string, fullOutput bool) (map[string]any, error) {
+	branch, ok := c.branch(branchID)
+	if !ok {
+		return nil, fmt.Errorf("404 branch %s not found (dry-run)", branchID)
+	}
+	return map[string]any{"branch_id": branchID, "output": branch.output}, nil
+}
+
+func (c *DryRunClient) branch(id string) (*dryRunBranch, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	branch, ok := c.branches[strings.TrimSpace(id)]
+	return branch, ok
+}
+
+func dryRunReviewReport(step string, round int) string {
+	if step == DryRunReviewClean {
+		return fmt.Sprintf("# Code Review (dry-run round %d)\n\nNo P0/P1 issues found.\n", round)
+	}
+	return fmt.Sprintf(`# Code Review (dry-run round %d)
+
+## P1: Simulated missing error handling
+- Location: example.go:42
+- Description: The simulated change ignores an error returned by a helper call.
+- Suggested fix: Propagate the error to the caller and add a test covering the failure path.
+`, round)
+}
+
+func dryRunPreview(prompt string) string {
+	prompt = strings.Join(strings.Fields(prompt), " ")
+	if len(prompt) > 200 {
+		return prompt[:200] + "..."
+	}
+	return prompt
+}
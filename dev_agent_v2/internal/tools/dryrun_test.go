@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDryRunClientFollowsReviewScript(t *testing.T) {
+	client := NewDryRunClient("/workspace", nil)
+	handler := NewToolHandler(client, "proj", "parent", "/workspace", nil)
+
+	review := func() map[string]any {
+		t.Helper()
+		res, err := handler.executeAgent(map[string]any{
+			"agent":            "review_code",
+			"prompt":           "review the latest changes",
+			"parent_branch_id": "parent",
+		})
+		if err != nil {
+			t.Fatalf("executeAgent returned error: %v", err)
+		}
+		return res
+	}
+
+	first := review()
+	if report, _ := first["review_report"].(string); !strings.Contains(report, "P1:") {
+		t.Fatalf("expected round 1 to report issues, got %q", report)
+	}
+	second := review()
+	if report, _ := second["review_report"].(string); !strings.Contains(report, "No P0/P1 issues found") {
+		t.Fatalf("expected round 2 to be clean, got %q", report)
+	}
+	third := review()
+	if report, _ := third["review_report"].(string); !strings.Contains(report, "No P0/P1 issues found") {
+		t.Fatalf("expected exhausted script to repeat clean, got %q", report)
+	}
+
+	if got := handler.BranchRange()["latest_branch_id"]; got != "dryrun-branch-0003" {
+		t.Fatalf("expected latest branch dryrun-branch-0003, got %q", got)
+	}
+}
+
+func TestDryRunClientCannedAgentOutput(t *testing.T) {
+	client := NewDryRunClient("/workspace", []string{DryRunReviewClean})
+	handler := NewToolHandler(client, "proj", "parent", "/workspace", nil)
+
+	call := ToolCall{}
+	call.Function.Name = "execute_agent"
+	call.Function.Arguments = `{"agent":"codex","prompt":"implement the feature","parent_branch_id":"parent"}`
+
+	res := handler.Handle(call)
+	if res["status"] != "success" {
+		t.Fatalf("expected success, got %#v", res)
+	}
+	data, _ := res["data"].(map[string]any)
+	if data["branch_id"] != "dryrun-branch-0001" {
+		t.Fatalf("expected synthetic branch id, got %#v", data["branch_id"])
+	}
+	if resp, _ := data["response"].(string); !strings.Contains(resp, "[dry-run] Simulated codex run") {
+		t.Fatalf("expected canned output, got %q", resp)
+	}
+
+	if _, err := client.BranchReadFile("dryrun-branch-0001", "/workspace/code_review.log"); !isNotFoundError(err) {
+		t.Fatalf("expected not-found error for missing artifact, got %v", err)
+	}
+}
+
+func TestParseDryRunReviewScript(t *testing.T) {
+	script, err := ParseDryRunReviewScript(" issues, ISSUES ,clean,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(script, ",") != "issues,issues,clean" {
+		t.Fatalf("unexpected script %v", script)
+	}
+	if _, err := ParseDryRunReviewScript("issues,maybe"); err == nil {
+		t.Fatalf("expected error for invalid step")
+	}
+}
@@ -411,6 +411,8 @@ type branchReadResult struct {
 
 type fakeMCPClient struct {
 	parallelExploreCalls int
+	exploreAgents        []string
+	explorePrompts       []string
 	readResults          []branchReadResult
 	branchReadInputs     []branchReadInput
 	branchOutputInputs   []branchOutputInput
@@ -427,6 +429,8 @@ type branchOutputInput struct {
 
 func (f *fakeMCPClient) ParallelExplore(projectName, parentBranchID string, prompts []string, agent string, numBranches int) (map[string]any, error) {
 	f.parallelExploreCalls++
+	f.exploreAgents = append(f.exploreAgents, agent)
+	f.explorePrompts = append(f.explorePrompts, prompts...)
 	branchID := fmt.Sprintf("branch-%d", f.parallelExploreCalls)
 	return map[string]any{
 		"branch_id": branchID,
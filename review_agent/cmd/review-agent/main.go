@@ -22,14 +22,28 @@ func main() {
 	project := flag.String("project-name", "", "Override project name")
 	headless := flag.Bool("headless", false, "Headless mode (no interactive prompt)")
 	streamJSON := flag.Bool("stream-json", false, "Emit workflow events as NDJSON (implies headless)")
+	streamSinks := flag.String("stream-sinks", "", "JSON file of NDJSON event sinks (stdout, file, unix, webhook), each with its own event filter")
 	skipScout := flag.Bool("skip-scout", true, "Skip the scout change analysis stage")
 	skipTester := flag.Bool("skip-tester", true, "Skip the tester and exchange verification stages")
 	skipConfirmation := flag.Bool("skip-confirmation", false, "Skip the issue confirmation stage")
 	explorationID := flag.String("exploration-id", "", "Optional exploration id for MCP headers")
+	promptDir := flag.String("prompt-dir", "", "Directory of *.tmpl files overriding the embedded prompt templates")
 	flag.Parse()
 
-	streamEnabled := streamJSON != nil && *streamJSON
-	if streamEnabled {
+	if err := prreview.ConfigurePrompts(*promptDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Prompt template error: %v\n", err)
+		os.Exit(1)
+	}
+
+	sinkConfigs, err := streaming.LoadSinkConfigs(*streamSinks)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--stream-sinks: %v\n", err)
+		os.Exit(1)
+	}
+	streamEnabled := *streamJSON || len(sinkConfigs) > 0
+	// Only events on stdout force headless mode and quiet the logs.
+	streamStdout := streaming.WritesStdout(*streamJSON, sinkConfigs)
+	if streamStdout {
 		*headless = true
 		logx.SetLevel(logx.Error)
 	}
@@ -67,10 +81,21 @@ func main() {
 	brain := b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
 	mcp := t.NewMCPClient(conf.MCPBaseURL, *explorationID)
 	handler := t.NewToolHandlerWithConfig(mcp, &conf, *parent)
+	agents, err := t.LoadAgentRegistry(conf.AgentRegistryFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Agent registry error: %v\n", err)
+		os.Exit(1)
+	}
+	handler.SetAgentRegistry(agents)
 
 	var streamer *streaming.JSONStreamer
 	if streamEnabled {
-		streamer = streaming.NewJSONStreamer(true, os.Stdout)
+		streamer, err = streaming.Open(*streamJSON, sinkConfigs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "--stream-sinks: %v\n", err)
+			os.Exit(1)
+		}
+		streamer.SetAgent(streaming.AgentReview)
 		streamer.EmitThreadStarted(tsk, conf.ProjectName, *parent, *headless)
 	}
 
@@ -90,6 +115,7 @@ func main() {
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "init error: %v\n", err)
+		closeStreamer(streamer)
 		os.Exit(1)
 	}
 
@@ -100,6 +126,7 @@ func main() {
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "workflow error: %v\n", err)
+		closeStreamer(streamer)
 		os.Exit(1)
 	}
 
@@ -109,13 +136,23 @@ func main() {
 	}
 	if streamer != nil && streamer.Enabled() && result != nil {
 		streamer.EmitThreadCompleted(status, result.Summary, map[string]any{
-			"task":    result.Task,
-			"status":  result.Status,
-			"summary": result.Summary,
-			"issues":  result.Issues,
+			"task":             result.Task,
+			"status":           result.Status,
+			"summary":          result.Summary,
+			"issues":           result.Issues,
+			"prompt_templates": result.PromptTemplates,
 		})
 	}
 
+	closeStreamer(streamer)
+
 	out, _ := json.MarshalIndent(result, "", "  ")
 	fmt.Fprintln(os.Stderr, string(out))
 }
+
+// closeStreamer flushes the stream sinks and reports events they dropped.
+func closeStreamer(streamer *streaming.JSONStreamer) {
+	if err := streamer.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Stream sinks: %v\n", err)
+	}
+}
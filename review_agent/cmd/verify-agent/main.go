@@ -21,12 +21,26 @@ func main() {
 	project := flag.String("project-name", "", "Override project name")
 	headless := flag.Bool("headless", false, "Headless mode (no interactive prompt)")
 	streamJSON := flag.Bool("stream-json", false, "Emit workflow events as NDJSON (implies headless)")
+	streamSinks := flag.String("stream-sinks", "", "JSON file of NDJSON event sinks (stdout, file, unix, webhook), each with its own event filter")
 	flag.String("code-context", "", "Optional: additional code context")
 	flag.Bool("false-positive", false, "Treat bug as false positive (虚假报警) - agent will try to refute it")
+	promptDir := flag.String("prompt-dir", "", "Directory of *.tmpl files overriding the embedded prompt templates")
 	flag.Parse()
 
-	streamEnabled := streamJSON != nil && *streamJSON
-	if streamEnabled {
+	if err := prreview.ConfigurePrompts(*promptDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Prompt template error: %v\n", err)
+		os.Exit(1)
+	}
+
+	sinkConfigs, err := streaming.LoadSinkConfigs(*streamSinks)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--stream-sinks: %v\n", err)
+		os.Exit(1)
+	}
+	streamEnabled := *streamJSON || len(sinkConfigs) > 0
+	// Only events on stdout force headless mode and quiet the logs.
+	streamStdout := streaming.WritesStdout(*streamJSON, sinkConfigs)
+	if streamStdout {
 		*headless = true
 		logx.SetLevel(logx.Error)
 	}
@@ -63,7 +77,12 @@ func main() {
 
 	var streamer *streaming.JSONStreamer
 	if streamEnabled {
-		streamer = streaming.NewJSONStreamer(true, os.Stdout)
+		streamer, err = streaming.Open(*streamJSON, sinkConfigs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "--stream-sinks: %v\n", err)
+			os.Exit(1)
+		}
+		streamer.SetAgent(streaming.AgentLogicAnalyst)
 		streamer.EmitThreadStarted(bug, conf.ProjectName, *parent, *headless)
 	}
 
@@ -71,6 +90,13 @@ func main() {
 
 	mcp := t.NewMCPClient(conf.MCPBaseURL, "")
 	handler := t.NewToolHandlerWithConfig(mcp, &conf, *parent)
+	agents, err := t.LoadAgentRegistry(conf.AgentRegistryFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Agent registry error: %v\n", err)
+		closeStreamer(streamer)
+		os.Exit(1)
+	}
+	handler.SetAgentRegistry(agents)
 
 	branchID, analysis, err := executeOnce(handler, "codex", prompt, conf.ProjectName, *parent)
 	if err != nil {
@@ -79,6 +105,7 @@ func main() {
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "workflow error: %v\n", err)
+		closeStreamer(streamer)
 		os.Exit(1)
 	}
 
@@ -89,13 +116,22 @@ func main() {
 		})
 	}
 
+	closeStreamer(streamer)
+
 	out := os.Stdout
-	if streamer != nil && streamer.Enabled() {
+	if streamStdout {
 		out = os.Stderr
 	}
 	fmt.Fprintln(out, strings.TrimSpace(analysis))
 }
 
+// closeStreamer flushes the stream sinks and reports events they dropped.
+func closeStreamer(streamer *streaming.JSONStreamer) {
+	if err := streamer.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Stream sinks: %v\n", err)
+	}
+}
+
 func executeOnce(handler *t.ToolHandler, agent, prompt, project, parentBranchID string) (string, string, error) {
 	args := map[string]any{
 		"agent":            agent,
@@ -11,7 +11,7 @@ func TestBuildIssueFinderPromptContainsInstructions(t *testing.T) {
 
 	required := []string{
 		"Task: " + task,
-		universalStudyLine,
+		promptBlock(t, "universal_study"),
 		"SOP (use only when the matching pattern appears in code)",
 		"Proxy flags are untrusted:",
 		"Short-circuit checklist:",
@@ -73,12 +73,12 @@ func TestBuildTesterPromptContainsRoleDirectives(t *testing.T) {
 	prompt := buildTesterPrompt("some task", "some issue", "/workspace/change_analysis.md")
 	requiredPhrases := []string{
 		"TESTER",
-		universalStudyLine,
+		promptBlock(t, "universal_study"),
 		"Simulate a QA engineer",
 		"MUST actually run code",
 		"Do NOT run `cargo test`",
 		"Use ONLY extremely small, targeted test batches",
-		p0p1VerdictGateBlock,
+		promptBlock(t, "p0p1_verdict_gate"),
 		"cargo check --all-targets",
 		"cargo clippy --all-targets",
 		"Do NOT fabricate",
@@ -100,7 +100,7 @@ func TestBuildExchangePromptIncludesSelfPeerAndReviewerGuidance(t *testing.T) {
 		"peer said hello",
 		"YOUR PREVIOUS OPINION",
 		"PEER'S OPINION",
-		p0p1VerdictGateBlock,
+		promptBlock(t, "p0p1_verdict_gate"),
 		"logic analysis",
 		"Do NOT claim you ran tests",
 	}
@@ -118,7 +118,7 @@ func TestBuildExchangePromptProvidesTesterGuidance(t *testing.T) {
 		"peer logic view",
 		"YOUR PREVIOUS OPINION",
 		"PEER'S OPINION",
-		p0p1VerdictGateBlock,
+		promptBlock(t, "p0p1_verdict_gate"),
 		"run code",
 		"real execution evidence",
 	}
@@ -152,7 +152,7 @@ func TestBuildScoutPromptWritesToPath(t *testing.T) {
 	prompt := buildScoutPrompt("task", "/workspace/change_analysis.md")
 	required := []string{
 		"Role: SCOUT",
-		universalStudyLine,
+		promptBlock(t, "universal_study"),
 		"Write the analysis to:",
 		"/workspace/change_analysis.md",
 		"# CHANGE ANALYSIS",
@@ -226,3 +226,12 @@ func TestExtractTranscriptVerdict(t *testing.T) {
 		})
 	}
 }
+
+func promptBlock(t *testing.T, name string) string {
+	t.Helper()
+	out, err := defaultPromptSet.Render(name, nil)
+	if err != nil {
+		t.Fatalf("render prompt block %s: %v", name, err)
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package prreview
+
+import (
+	"embed"
+	"fmt"
+
+	"review_agent/internal/logx"
+	"review_agent/internal/prompts"
+)
+
+//go:embed templates/*.tmpl
+var promptFS embed.FS
+
+// Template names. Each file lives in templates/ and can be overridden via
+// ConfigurePrompts.
+const (
+	tmplIssueFinder       = "issue_finder.tmpl"
+	tmplScout             = "scout.tmpl"
+	tmplHasRealIssue      = "has_real_issue.tmpl"
+	tmplLogicAnalyst      = "logic_analyst.tmpl"
+	tmplTester            = "tester.tmpl"
+	tmplExchange          = "exchange.tmpl"
+	tmplVerdictExtraction = "verdict_extraction.tmpl"
+	tmplAlignment         = "alignment.tmpl"
+)
+
+// IssueFinderPromptData feeds issue_finder.tmpl.
+type IssueFinderPromptData struct {
+	Task               string
+	ChangeAnalysisPath string
+}
+
+// ScoutPromptData feeds scout.tmpl.
+type ScoutPromptData struct {
+	Task       string
+	OutputPath string
+}
+
+// ReportPromptData feeds has_real_issue.tmpl.
+type ReportPromptData struct {
+	ReportText string
+}
+
+// VerificationPromptData feeds the Round 1 role prompts
+// (logic_analyst.tmpl, tester.tmpl).
+type VerificationPromptData struct {
+	Task               string
+	IssueText          string
+	ChangeAnalysisPath string
+}
+
+// ExchangePromptData feeds exchange.tmpl. Role is the raw role name; the
+// template normalizes it.
+type ExchangePromptData struct {
+	Role               string
+	Task               string
+	IssueText          string
+	ChangeAnalysisPath string
+	SelfOpinion        string
+	PeerOpinion        string
+}
+
+// VerdictExtractionPromptData feeds verdict_extraction.tmpl.
+type VerdictExtractionPromptData struct {
+	Agent      string
+	Round      int
+	Transcript string
+}
+
+// AlignmentPromptData feeds alignment.tmpl.
+type AlignmentPromptData struct {
+	IssueText   string
+	TranscriptA string
+	TranscriptB string
+}
+
+func promptSamples() map[string]any {
+	verification := VerificationPromptData{Task: "task", IssueText: "issue", ChangeAnalysisPath: "/workspace/change_analysis.md"}
+	return map[string]any{
+		tmplIssueFinder:       IssueFinderPromptData{Task: "task", ChangeAnalysisPath: "/workspace/change_analysis.md"},
+		tmplScout:             ScoutPromptData{Task: "task", OutputPath: "/workspace/change_analysis.md"},
+		tmplHasRealIssue:      ReportPromptData{ReportText: "report"},
+		tmplLogicAnalyst:      verification,
+		tmplTester:            verification,
+		tmplExchange:          ExchangePromptData{Role: "reviewer", Task: "task", IssueText: "issue", SelfOpinion: "self", PeerOpinion: "peer"},
+		tmplVerdictExtraction: VerdictExtractionPromptData{Agent: "codex", Round: 1, Transcript: "transcript"},
+		tmplAlignment:         AlignmentPromptData{IssueText: "issue", TranscriptA: "a", TranscriptB: "b"},
+	}
+}
+
+var (
+	defaultPromptSet = prompts.MustLoad(promptFS, promptSamples())
+	activePromptSet  = defaultPromptSet
+)
+
+// ConfigurePrompts validates the templates in dir (overriding the embedded
+// defaults by file name) and makes them active. An empty dir restores the
+// defaults. Call it once at startup, before any Runner is used.
+func ConfigurePrompts(dir string) error {
+	set, err := prompts.Load(promptFS, dir, promptSamples())
+	if err != nil {
+		return err
+	}
+	activePromptSet = set
+	return nil
+}
+
+// PromptVersions reports the source and version of each active template.
+func PromptVersions() map[string]string {
+	return activePromptSet.Versions()
+}
+
+// renderPrompt executes an active template. Templates are validated when
+// loaded, so a render failure falls back to the embedded default rather than
+// sending an empty prompt.
+func renderPrompt(name string, data any) string {
+	out, err := activePromptSet.Render(name, data)
+	if err == nil {
+		return out
+	}
+	logx.Errorf("Rendering prompt template %s failed, using embedded default: %v", name, err)
+	out, err = defaultPromptSet.Render(name, data)
+	if err != nil {
+		panic(fmt.Sprintf("embedded prompt template %s failed to render: %v", name, err))
+	}
+	return out
+}
@@ -36,13 +36,14 @@ type Options struct {
 
 // Result captures the high-level outcome plus supporting artifacts.
 type Result struct {
-	Task           string        `json:"task"`
-	Status         string        `json:"status"`
-	Summary        string        `json:"summary"`
-	ReviewerLogs   []ReviewerLog `json:"reviewer_logs"`
-	Issues         []IssueReport `json:"issues"`
-	StartBranchID  string        `json:"start_branch_id,omitempty"`
-	LatestBranchID string        `json:"latest_branch_id,omitempty"`
+	Task            string            `json:"task"`
+	Status          string            `json:"status"`
+	Summary         string            `json:"summary"`
+	ReviewerLogs    []ReviewerLog     `json:"reviewer_logs"`
+	Issues          []IssueReport     `json:"issues"`
+	StartBranchID   string            `json:"start_branch_id,omitempty"`
+	LatestBranchID  string            `json:"latest_branch_id,omitempty"`
+	PromptTemplates map[string]string `json:"prompt_templates,omitempty"`
 }
 
 // ReviewerLog records the raw output from each review_code run.
@@ -127,9 +128,10 @@ func (r *Runner) Run() (*Result, error) {
 	parent := r.opts.ParentBranchID
 
 	result := &Result{
-		Task:         r.opts.Task,
-		ReviewerLogs: []ReviewerLog{},
-		Issues:       []IssueReport{},
+		Task:            r.opts.Task,
+		ReviewerLogs:    []ReviewerLog{},
+		Issues:          []IssueReport{},
+		PromptTemplates: PromptVersions(),
 	}
 
 	scoutBranchID := parent
@@ -0,0 +1,405 @@
+package streaming
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink receives the encoded events of a JSONStreamer. Write gets one NDJSON
+// line, trailing newline included, and is never called concurrently; Close
+// flushes anything buffered.
+type Sink interface {
+	Accepts(eventType string) bool
+	Write(line []byte) error
+	Close() error
+}
+
+// Filter selects the events a sink receives by type. A pattern matches a type
+// exactly, by prefix when it ends in ".*" (e.g. "item.*"), or everything when
+// it is "*". Empty Events accepts every type; Exclude wins over Events.
+type Filter struct {
+	Events  []string `json:"events,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Accepts reports whether the filter lets eventType through.
+func (f Filter) Accepts(eventType string) bool {
+	if matchAny(f.Exclude, eventType) {
+		return false
+	}
+	return len(f.Events) == 0 || matchAny(f.Events, eventType)
+}
+
+func matchAny(patterns []string, eventType string) bool {
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		switch {
+		case p == "*" || p == eventType:
+			return true
+		case strings.HasSuffix(p, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(p, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// writerSink writes events to an io.Writer it does not own, such as stdout.
+type writerSink struct {
+	Filter
+	w io.Writer
+}
+
+// NewWriterSink returns a sink writing to w. Close leaves w open.
+func NewWriterSink(w io.Writer, filter Filter) Sink {
+	return &writerSink{Filter: filter, w: w}
+}
+
+func (s *writerSink) Write(line []byte) error {
+	_, err := s.w.Write(line)
+	return err
+}
+
+func (s *writerSink) Close() error { return nil }
+
+// FileSink appends events to an NDJSON file and rotates it once it would
+// exceed MaxBytes: path becomes path.1, path.1 becomes path.2 and so on, and
+// backups beyond MaxFiles are removed.
+type FileSink struct {
+	Filter
+	path     string
+	maxBytes int64
+	maxFiles int
+	f        *os.File
+	size     int64
+}
+
+// NewFileSink opens path for appending, creating its directory. maxBytes <= 0
+// disables rotation; maxFiles is the number of rotated files kept.
+func NewFileSink(path string, maxBytes int64, maxFiles int, filter Filter) (*FileSink, error) {
+	s := &FileSink{Filter: filter, path: path, maxBytes: maxBytes, maxFiles: maxFiles}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("file sink %s: %w", path, err)
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("file sink %s: %w", s.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("file sink %s: %w", s.path, err)
+	}
+	s.f, s.size = f, info.Size()
+	return nil
+}
+
+func (s *FileSink) Write(line []byte) error {
+	if s.f == nil {
+		return fmt.Errorf("file sink %s is closed", s.path)
+	}
+	if s.maxBytes > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.f.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("file sink %s: %w", s.path, err)
+	}
+	return nil
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.f.Close(); err != nil {
+		return fmt.Errorf("file sink %s: %w", s.path, err)
+	}
+	s.f = nil
+	if s.maxFiles <= 0 {
+		os.Remove(s.path)
+	} else {
+		os.Remove(fmt.Sprintf("%s.%d", s.path, s.maxFiles))
+		for i := s.maxFiles - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", s.path, i), fmt.Sprintf("%s.%d", s.path, i+1))
+		}
+		if err := os.Rename(s.path, s.path+".1"); err != nil {
+			return fmt.Errorf("file sink %s: rotate: %w", s.path, err)
+		}
+	}
+	return s.open()
+}
+
+func (s *FileSink) Close() error {
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+// unixRedialDelay is how long a UnixSink drops events after its listener went
+// away before dialing again.
+const unixRedialDelay = 5 * time.Second
+
+// UnixSink writes events to a Unix domain socket a consumer listens on. It
+// dials lazily and redials after a failure, dropping events meanwhile, so a
+// missing dashboard never stalls the run. A line cut short by a failed write
+// is followed by a newline on the next connection, so a consumer that joins
+// the connections into one stream sees a bad line rather than two lines
+// torn into one.
+type UnixSink struct {
+	Filter
+	path    string
+	timeout time.Duration
+	conn    net.Conn
+	retryAt time.Time
+	dropped int
+	torn    bool
+}
+
+// NewUnixSink returns a sink for the socket at path. The socket need not
+// exist yet.
+func NewUnixSink(path string, filter Filter) *UnixSink {
+	return &UnixSink{Filter: filter, path: path, timeout: 2 * time.Second}
+}
+
+func (s *UnixSink) Write(line []byte) error {
+	if s.conn == nil {
+		if time.Now().Before(s.retryAt) {
+			s.dropped++
+			return nil
+		}
+		conn, err := net.DialTimeout("unix", s.path, s.timeout)
+		if err != nil {
+			s.retryAt = time.Now().Add(unixRedialDelay)
+			s.dropped++
+			return fmt.Errorf("unix sink %s: %w (dropping events for %s)", s.path, err, unixRedialDelay)
+		}
+		s.conn = conn
+	}
+	if s.torn {
+		line = append([]byte("\n"), line...)
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	n, err := s.conn.Write(line)
+	if err != nil {
+		s.conn.Close()
+		s.conn = nil
+		s.retryAt = time.Now().Add(unixRedialDelay)
+		s.dropped++
+		s.torn = s.torn || n > 0
+		return fmt.Errorf("unix sink %s: %w (dropping events for %s)", s.path, err, unixRedialDelay)
+	}
+	s.torn = false
+	return nil
+}
+
+// Close closes the connection and reports any events that were dropped.
+func (s *UnixSink) Close() error {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	if s.dropped > 0 {
+		return fmt.Errorf("unix sink %s dropped %d events", s.path, s.dropped)
+	}
+	return nil
+}
+
+// WebhookConfig configures a WebhookSink. Zero values take the defaults.
+type WebhookConfig struct {
+	URL     string
+	Headers map[string]string
+	// BatchSize events, or whatever arrived within FlushInterval, go out in
+	// one POST (default 50 and 2s).
+	BatchSize     int
+	FlushInterval time.Duration
+	// MaxRetries is the number of extra attempts for a batch that failed
+	// with a network error, 429 or 5xx (default 3; negative disables
+	// retries). Timeout bounds each request (default 10s).
+	MaxRetries int
+	Timeout    time.Duration
+	// QueueSize bounds the events waiting for delivery (default 1024);
+	// events beyond it are dropped.
+	QueueSize int
+}
+
+// WebhookSink POSTs events in NDJSON batches from a background goroutine, so
+// a slow endpoint never stalls the run.
+type WebhookSink struct {
+	Filter
+	cfg     WebhookConfig
+	client  *http.Client
+	backoff time.Duration
+	queue   chan []byte
+	closing chan struct{}
+	done    chan struct{}
+
+	mu      sync.Mutex
+	dropped int
+}
+
+// NewWebhookSink starts the delivery goroutine of a webhook sink.
+func NewWebhookSink(cfg WebhookConfig, filter Filter) *WebhookSink {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 2 * time.Second
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1024
+	}
+	s := &WebhookSink{
+		Filter:  filter,
+		cfg:     cfg,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		backoff: 500 * time.Millisecond,
+		queue:   make(chan []byte, cfg.QueueSize),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *WebhookSink) Write(line []byte) error {
+	select {
+	case s.queue <- append([]byte(nil), line...):
+		return nil
+	default:
+		s.drop(1)
+		return fmt.Errorf("webhook sink %s: queue full, event dropped", s.cfg.URL)
+	}
+}
+
+// Close delivers the queued events, stops the delivery goroutine and reports
+// any events that were dropped. Batches get no retries once Close was called,
+// so it waits at most one request Timeout per queued batch.
+func (s *WebhookSink) Close() error {
+	close(s.closing)
+	close(s.queue)
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dropped > 0 {
+		return fmt.Errorf("webhook sink %s dropped %d events", s.cfg.URL, s.dropped)
+	}
+	return nil
+}
+
+func (s *WebhookSink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+	var batch [][]byte
+	flush := func() {
+		if len(batch) > 0 {
+			s.deliver(batch)
+			batch = nil
+		}
+	}
+	for {
+		select {
+		case line, ok := <-s.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, line)
+			if len(batch) >= s.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// deliver POSTs one batch, retrying transient failures with exponential
+// backoff until Close is called. A batch that still fails is dropped.
+func (s *WebhookSink) deliver(batch [][]byte) {
+	body := bytes.Join(batch, nil)
+	var err error
+	for attempt := 0; attempt <= s.cfg.MaxRetries; attempt++ {
+		if attempt > 0 && !s.sleep(s.backoff<<(attempt-1)) {
+			break
+		}
+		var retry bool
+		if retry, err = s.post(body); err == nil || !retry {
+			break
+		}
+	}
+	if err != nil {
+		s.drop(len(batch))
+		fmt.Fprintf(os.Stderr, "json_streamer: webhook sink %s: dropping %d events: %v\n", s.cfg.URL, len(batch), err)
+	}
+}
+
+// sleep waits d and reports false if Close was called first.
+func (s *WebhookSink) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.closing:
+		return false
+	}
+}
+
+// post sends body once and reports whether a failure is worth retrying.
+func (s *WebhookSink) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %s", resp.Status)
+	default:
+		return false, fmt.Errorf("status %s", resp.Status)
+	}
+}
+
+func (s *WebhookSink) drop(n int) {
+	s.mu.Lock()
+	s.dropped += n
+	s.mu.Unlock()
+}
@@ -0,0 +1,277 @@
+package streaming
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFilterAccepts(t *testing.T) {
+	f := Filter{Events: []string{"item.*", "thread.completed"}, Exclude: []string{"item.started"}}
+	for eventType, want := range map[string]bool{
+		"item.completed":   true,
+		"item.started":     false,
+		"thread.completed": true,
+		"thread.started":   false,
+		"itemized":         false,
+	} {
+		if got := f.Accepts(eventType); got != want {
+			t.Errorf("Accepts(%q) = %v, want %v", eventType, got, want)
+		}
+	}
+	if !(Filter{}).Accepts("error") || (Filter{Exclude: []string{"*"}}).Accepts("error") {
+		t.Fatalf("an empty filter accepts everything and * excludes everything")
+	}
+}
+
+func TestStreamerFansOutPerSinkFilters(t *testing.T) {
+	var all, items strings.Builder
+	s := NewSinkStreamer(NewWriterSink(&all, Filter{}), NewWriterSink(&items, Filter{Events: []string{"item.*"}}))
+	s.EmitThreadStarted("task", "proj", "parent", true)
+	s.EmitItemStarted("item_1", "tool_call", "execute_agent", nil)
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	s.EmitThreadCompleted("completed", "", nil)
+	if n := strings.Count(all.String(), "\n"); n != 2 {
+		t.Fatalf("expected 2 events before Close, got %d:\n%s", n, all.String())
+	}
+	if !strings.Contains(items.String(), `"type":"item.started"`) || strings.Contains(items.String(), "thread.started") {
+		t.Fatalf("unexpected filtered events:\n%s", items.String())
+	}
+}
+
+func TestFileSinkRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "events.ndjson")
+	sink, err := NewFileSink(path, 10, 2, Filter{})
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	for _, line := range []string{"one----\n", "two----\n", "three--\n", "four---\n"} {
+		if err := sink.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	sink.Close()
+	for file, want := range map[string]string{path: "four---\n", path + ".1": "three--\n", path + ".2": "two----\n"} {
+		got, err := os.ReadFile(file)
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q (%v), want %q", filepath.Base(file), got, err, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("backups beyond max_files must be removed")
+	}
+}
+
+func TestUnixSinkDeliversAndSurvivesMissingListener(t *testing.T) {
+	dir, err := os.MkdirTemp("", "sink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "s.sock")
+
+	missing := NewUnixSink(path, Filter{})
+	if err := missing.Write([]byte("lost\n")); err == nil {
+		t.Fatalf("a missing listener must be reported once")
+	}
+	if err := missing.Write([]byte("lost\n")); err != nil {
+		t.Fatalf("events must be dropped quietly until the redial delay passes: %v", err)
+	}
+	if err := missing.Close(); err == nil || !strings.Contains(err.Error(), "dropped 2 events") {
+		t.Fatalf("Close must report the dropped events, got %v", err)
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	got := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		got <- line
+	}()
+	sink := NewUnixSink(path, Filter{})
+	if err := sink.Write([]byte("{\"type\":\"error\"}\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if line := <-got; line != "{\"type\":\"error\"}\n" {
+		t.Fatalf("unexpected line %q", line)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestWebhookSinkBatchesAndRetries(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		bodies  []string
+		failed  bool
+		headers []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !failed {
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		headers = append(headers, r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(WebhookConfig{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer t"}, BatchSize: 2, FlushInterval: time.Hour}, Filter{})
+	sink.backoff = time.Millisecond
+	delivered := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(bodies)
+	}
+	for _, line := range []string{"{\"n\":1}\n", "{\"n\":2}\n", "{\"n\":3}\n"} {
+		if err := sink.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	// Close drops retries, so let the full batch get through first.
+	for deadline := time.Now().Add(5 * time.Second); delivered() == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 2 || bodies[0] != "{\"n\":1}\n{\"n\":2}\n" || bodies[1] != "{\"n\":3}\n" || headers[0] != "Bearer t" {
+		t.Fatalf("expected a retried full batch and a final partial one, got %q %q", bodies, headers)
+	}
+}
+
+func TestWebhookSinkCloseSkipsRetries(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		posts int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		posts++
+		mu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(WebhookConfig{URL: srv.URL, FlushInterval: time.Hour}, Filter{})
+	sink.backoff = time.Hour
+	if err := sink.Write([]byte("{\"n\":1}\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	start := time.Now()
+	if err := sink.Close(); err == nil || !strings.Contains(err.Error(), "dropped 1 events") {
+		t.Fatalf("Close must report the undelivered event, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if posts != 1 || time.Since(start) > 5*time.Second {
+		t.Fatalf("Close must not wait for retries: %d posts in %s", posts, time.Since(start))
+	}
+}
+
+// shortConn accepts only the first half of each write.
+type shortConn struct{ net.Conn }
+
+func (c shortConn) Write(b []byte) (int, error)      { return len(b) / 2, os.ErrDeadlineExceeded }
+func (c shortConn) SetWriteDeadline(time.Time) error { return nil }
+func (c shortConn) Close() error                     { return nil }
+
+func TestUnixSinkResyncsAfterTornLine(t *testing.T) {
+	dir, err := os.MkdirTemp("", "sink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "s.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	got := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		data, _ := io.ReadAll(conn)
+		got <- string(data)
+	}()
+
+	sink := NewUnixSink(path, Filter{})
+	sink.conn = shortConn{}
+	if err := sink.Write([]byte("{\"type\":\"turn.started\"}\n")); err == nil {
+		t.Fatalf("a short write must be reported")
+	}
+	sink.retryAt = time.Time{}
+	if err := sink.Write([]byte("{\"type\":\"error\"}\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	sink.Close()
+	if data := <-got; data != "\n{\"type\":\"error\"}\n" {
+		t.Fatalf("expected a newline before the next whole line, got %q", data)
+	}
+}
+
+func TestLoadSinkConfigsAndOpen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sinks.json")
+	file := map[string]any{"sinks": []map[string]any{
+		{"type": "File", "path": filepath.Join(dir, "events.ndjson"), "max_bytes": 1024, "events": []string{"thread.*"}},
+		{"type": "webhook", "url": "http://127.0.0.1:1/hook", "headers": map[string]string{"Authorization": "Bearer ${SINK_TEST_TOKEN}"}, "max_retries": -1},
+	}}
+	data, _ := json.Marshal(file)
+	os.WriteFile(path, data, 0o644)
+
+	configs, err := LoadSinkConfigs(path)
+	if err != nil || len(configs) != 2 || configs[0].Type != SinkFile || !configs[0].Accepts("thread.started") || configs[0].Accepts("item.started") {
+		t.Fatalf("unexpected configs %+v (%v)", configs, err)
+	}
+	if WritesStdout(false, configs) || !WritesStdout(true, configs) {
+		t.Fatalf("only --stream-json or a stdout sink writes to stdout")
+	}
+	streamer, err := Open(false, configs[:1])
+	if err != nil || !streamer.Enabled() {
+		t.Fatalf("Open: %v", err)
+	}
+	streamer.EmitThreadStarted("task", "proj", "parent", false)
+	streamer.EmitItemStarted("item_1", "tool_call", "execute_agent", nil)
+	streamer.Close()
+	got, _ := os.ReadFile(configs[0].Path)
+	if !strings.Contains(string(got), "thread.started") || strings.Contains(string(got), "item.started") {
+		t.Fatalf("unexpected file contents %s", got)
+	}
+
+	os.WriteFile(path, []byte(`{"sinks": [{"type": "kafka"}]}`), 0o644)
+	if _, err := LoadSinkConfigs(path); err == nil || !strings.Contains(err.Error(), "unknown sink type") {
+		t.Fatalf("expected an unknown sink type error, got %v", err)
+	}
+	if disabled, err := Open(false, nil); err != nil || disabled.Enabled() {
+		t.Fatalf("no sinks must give a disabled streamer")
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	b "review_agent/internal/brain"
+	cfg "review_agent/internal/config"
+	"review_agent/internal/eval"
+	"review_agent/internal/prreview"
+	"review_agent/internal/skills"
+	t "review_agent/internal/tools"
+)
+
+// runEval implements `review-agent eval`: it reviews every case of a labeled
+// dataset, scores the issues against the expected ones and prints precision,
+// recall, false-positive rate, cost and per-stage latency.
+func runEval(args []string) int {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	dataset := fs.String("dataset", "", "JSONL dataset of {id, task, parent_branch_id, project_name, expected_issues} cases (required)")
+	matcherName := fs.String("matcher", "keyword", "How found issues are matched to expected ones: keyword or llm")
+	concurrency := fs.Int("concurrency", 1, "Number of cases reviewed concurrently")
+	output := fs.String("output", "", "Write the JSON report to this file")
+	baselineFile := fs.String("baseline", "", "JSON report of an earlier run to compare against")
+	label := fs.String("label", "", "Name of this run in the report (e.g. the prompt set or revision)")
+	promptDir := fs.String("prompt-dir", "", "Directory of *.tmpl files overriding the embedded prompt templates")
+	skipScout := fs.Bool("skip-scout", true, "Skip the scout change analysis stage")
+	skipTester := fs.Bool("skip-tester", false, "Skip the verify agent and exchange rounds")
+	skipSpecialists := fs.Bool("skip-specialists", false, "Do not add domain specialists to the consensus vote")
+	maxIssues := fs.Int("max-issues", 0, "Maximum number of parsed issues to verify per case (0 = default 5, negative = no cap)")
+	explorationID := fs.String("exploration-id", "", "Optional exploration id for MCP headers")
+	skillNames := fs.String("skills", "", "Comma-separated skill packs to inject into the phases that declare them")
+	skillsDir := fs.String("skills-dir", skills.DefaultDir, "Directory of skill packs (<name>/SKILL.md)")
+	_ = fs.Parse(args)
+
+	if strings.TrimSpace(*dataset) == "" {
+		fmt.Fprintln(os.Stderr, "eval: --dataset is required")
+		return 2
+	}
+	if err := prreview.ConfigurePrompts(*promptDir); err != nil {
+		fmt.Fprintf(os.Stderr, "eval: prompt template error: %v\n", err)
+		return 1
+	}
+	selected := skills.ParseList(*skillNames)
+	if _, err := skills.Load(*skillsDir, selected); err != nil {
+		fmt.Fprintf(os.Stderr, "eval: skills error: %v\n", err)
+		return 1
+	}
+	cases, err := eval.LoadDataset(*dataset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "eval: %v\n", err)
+		return 1
+	}
+	var baseline *eval.Report
+	if *baselineFile != "" {
+		if baseline, err = eval.LoadReport(*baselineFile); err != nil {
+			fmt.Fprintf(os.Stderr, "eval: %v\n", err)
+			return 1
+		}
+	}
+
+	conf, err := cfg.FromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "eval: configuration error: %v\n", err)
+		return 1
+	}
+	agents, err := t.LoadAgentRegistry(conf.AgentRegistryFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "eval: agent registry error: %v\n", err)
+		return 1
+	}
+
+	var matcher eval.Matcher
+	var judge *b.LLMBrain
+	switch *matcherName {
+	case "keyword":
+		matcher = eval.KeywordMatcher{}
+	case "llm":
+		judge = b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
+		matcher = eval.JudgeMatcher{Complete: func(prompt string) (string, error) {
+			resp, err := judge.Complete([]b.ChatMessage{
+				{Role: "system", Content: "Judge whether two code review findings describe the same defect. Reply only with JSON."},
+				{Role: "user", Content: prompt},
+			}, nil)
+			if err != nil {
+				return "", err
+			}
+			if resp == nil || len(resp.Choices) == 0 {
+				return "", fmt.Errorf("LLM response is empty or has no choices")
+			}
+			return resp.Choices[0].Message.Content, nil
+		}}
+	default:
+		fmt.Fprintf(os.Stderr, "eval: unknown --matcher %q (want keyword or llm)\n", *matcherName)
+		return 2
+	}
+
+	// Each case gets its own brain and tool handler so token usage and the
+	// parent branch are attributed to that case alone.
+	run := func(c eval.Case) (*prreview.Result, b.Usage, error) {
+		project := c.ProjectName
+		if project == "" {
+			project = conf.ProjectName
+		}
+		if project == "" {
+			return nil, b.Usage{}, fmt.Errorf("case %s: project name required via project_name, PROJECT_NAME or --project-name", c.ID)
+		}
+		// A fresh skill set per case records only that review's usage.
+		skillSet, err := skills.Load(*skillsDir, selected)
+		if err != nil {
+			return nil, b.Usage{}, err
+		}
+		caseConf := conf
+		caseConf.ProjectName = project
+		brain := b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
+		handler := t.NewToolHandlerWithConfig(t.NewMCPClient(conf.MCPBaseURL, *explorationID), &caseConf, c.ParentBranchID)
+		handler.SetAgentRegistry(agents)
+		runner, err := prreview.NewRunner(brain, handler, nil, prreview.Options{
+			Task:            c.Task,
+			ProjectName:     project,
+			ParentBranchID:  c.ParentBranchID,
+			WorkspaceDir:    conf.WorkspaceDir,
+			SkipScout:       *skipScout,
+			SkipTester:      *skipTester,
+			SkipSpecialists: *skipSpecialists,
+			MaxIssues:       *maxIssues,
+			Skills:          skillSet,
+		})
+		if err != nil {
+			return nil, brain.Usage(), err
+		}
+		result, err := runner.Run()
+		return result, brain.Usage(), err
+	}
+
+	evaluator := eval.Evaluator{Run: run, Matcher: matcher, Concurrency: *concurrency, Label: *label}
+	report, err := evaluator.Evaluate(cases)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "eval: %v\n", err)
+		return 1
+	}
+
+	eval.WriteTable(os.Stdout, report)
+	if judge != nil {
+		usage := judge.Usage()
+		fmt.Printf("judge: %d prompt + %d completion tokens over %d LLM calls (not included in cost)\n", usage.PromptTokens, usage.CompletionTokens, usage.Calls)
+	}
+	if baseline != nil {
+		fmt.Println()
+		eval.Compare(os.Stdout, baseline, report)
+	}
+	if *output != "" {
+		if err := eval.SaveReport(*output, report); err != nil {
+			fmt.Fprintf(os.Stderr, "eval: write report: %v\n", err)
+			return 1
+		}
+	}
+	if report.Metrics.FailedCases == report.Metrics.Cases {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"review_agent/internal/prreview"
+
+	"verify_agent/verifier"
+)
+
+// formalVerifier adapts verify_agent's verifier library to
+// prreview.FormalVerifier.
+type formalVerifier struct {
+	v       *verifier.Verifier
+	project string
+}
+
+// newFormalVerifier builds the verifier from the environment. skillNames
+// enables the same skill packs in the verification tasks as in the review.
+func newFormalVerifier(explorationID, project string, quiet bool, skillsDir string, skillNames []string) (*formalVerifier, error) {
+	conf, err := verifier.ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	v, err := verifier.New(conf, explorationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.UseSkills(skillsDir, skillNames); err != nil {
+		return nil, err
+	}
+	if quiet {
+		verifier.Quiet()
+	}
+	return &formalVerifier{v: v, project: project}, nil
+}
+
+func (f *formalVerifier) VerifyIssue(issueText, codeContext, parentBranchID string) (*prreview.FormalVerification, error) {
+	res, err := f.v.Verify(verifier.Request{
+		Bug:            issueText,
+		ProjectName:    f.project,
+		ParentBranchID: parentBranchID,
+		CodeContext:    codeContext,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := &prreview.FormalVerification{
+		Status:         res.Status,
+		Summary:        res.Summary,
+		LatestBranchID: res.LatestBranchID,
+	}
+	if res.Task1Result != nil {
+		out.Tasks++
+	}
+	if res.Task2Result != nil {
+		out.Tasks++
+	}
+	if task3 := res.Task3Result; task3 != nil {
+		out.Tasks++
+		out.TestStatus = task3.Status
+		out.TestCase = task3.TestCase
+		out.TestExecution = task3.TestExecution
+	}
+	return out, nil
+}
@@ -10,25 +10,79 @@ import (
 
 	b "review_agent/internal/brain"
 	cfg "review_agent/internal/config"
+	"review_agent/internal/export"
+	"review_agent/internal/ghreview"
 	"review_agent/internal/logx"
 	"review_agent/internal/prreview"
+	"review_agent/internal/skills"
 	"review_agent/internal/streaming"
 	t "review_agent/internal/tools"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "suppress" {
+		os.Exit(runSuppress(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "eval" {
+		os.Exit(runEval(os.Args[2:]))
+	}
+
 	task := flag.String("task", "", "PR context / task description")
 	parent := flag.String("parent-branch-id", "", "Branch UUID to fork from (required)")
 	project := flag.String("project-name", "", "Override project name")
 	headless := flag.Bool("headless", false, "Headless mode (no interactive prompt)")
 	streamJSON := flag.Bool("stream-json", false, "Emit workflow events as NDJSON (implies headless)")
+	streamSinks := flag.String("stream-sinks", "", "JSON file of NDJSON event sinks (stdout, file, unix, webhook), each with its own event filter")
 	skipScout := flag.Bool("skip-scout", true, "Skip the scout change analysis stage")
-	skipTester := flag.Bool("skip-tester", true, "Skip the tester and exchange verification stages")
+	skipTester := flag.Bool("skip-tester", false, "Skip the verify agent and exchange rounds; the reviewer alone decides each issue")
+	skipSpecialists := flag.Bool("skip-specialists", false, "Do not add domain specialists selected from the change analysis to the consensus vote")
+	issueParallelism := flag.Int("issue-parallelism", 0, "Number of issues verified concurrently (0 = default)")
+	maxIssues := flag.Int("max-issues", 0, "Maximum number of parsed issues to verify (0 = default 5, negative = no cap)")
+	dropPolicy := flag.String("drop-policy", prreview.DropPolicyRank, "Which issues survive --max-issues: rank (severity, confidence, location diversity), keep-p0 (rank, never drop P0) or order (parser order)")
 	explorationID := flag.String("exploration-id", "", "Optional exploration id for MCP headers")
+	githubReview := flag.Bool("github-review", false, "Post confirmed issues to the pull request as an inline GitHub review")
+	githubPR := flag.String("github-pr", "", "Pull request to review (URL or owner/repo#number); defaults to the PR URL in --task")
+	stateFile := flag.String("state-file", "", "JSON file holding the last reviewed head SHA and its issues; enables incremental reviews")
+	headSHA := flag.String("head-sha", "", "Head commit under review; defaults to the pull request head when --state-file is set")
+	suppressionFile := flag.String("suppression-file", "", "JSON store of known false positives: matching issues are skipped, unanimously rejected ones are added")
+	outputFormat := flag.String("output-format", "json", "Result format written to --output-file: json, sarif or junit")
+	outputFile := flag.String("output-file", "", "Write the result in --output-format to this file (\"-\" for stdout)")
+	promptDir := flag.String("prompt-dir", "", "Directory of *.tmpl files overriding the embedded prompt templates")
+	skillNames := flag.String("skills", "", "Comma-separated skill packs to inject into the phases that declare them (e.g. re2, all or none)")
+	skillsDir := flag.String("skills-dir", skills.DefaultDir, "Directory of skill packs (<name>/SKILL.md)")
+	formalVerify := flag.Bool("formal-verify", false, "Also run verify_agent's formalize/reachability/test pipeline on each issue; its outcome votes in the consensus")
 	flag.Parse()
 
-	streamEnabled := streamJSON != nil && *streamJSON
-	if streamEnabled {
+	if err := prreview.ConfigurePrompts(*promptDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Prompt template error: %v\n", err)
+		os.Exit(1)
+	}
+
+	skillSet, err := skills.Load(*skillsDir, skills.ParseList(*skillNames))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Skills error: %v\n", err)
+		os.Exit(1)
+	}
+
+	format, err := export.ParseFormat(*outputFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	sinkConfigs, err := streaming.LoadSinkConfigs(*streamSinks)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--stream-sinks: %v\n", err)
+		os.Exit(1)
+	}
+	streamEnabled := *streamJSON || len(sinkConfigs) > 0
+	// Only events on stdout force headless mode and quiet the logs.
+	streamStdout := streaming.WritesStdout(*streamJSON, sinkConfigs)
+	if streamStdout && *outputFile == "-" {
+		fmt.Fprintln(os.Stderr, "--output-file - conflicts with streaming events on stdout")
+		os.Exit(1)
+	}
+	if streamStdout {
 		*headless = true
 		logx.SetLevel(logx.Error)
 	}
@@ -66,20 +120,75 @@ func main() {
 	brain := b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
 	mcp := t.NewMCPClient(conf.MCPBaseURL, *explorationID)
 	handler := t.NewToolHandlerWithConfig(mcp, &conf, *parent)
+	agents, err := t.LoadAgentRegistry(conf.AgentRegistryFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Agent registry error: %v\n", err)
+		os.Exit(1)
+	}
+	handler.SetAgentRegistry(agents)
 
 	var streamer *streaming.JSONStreamer
 	if streamEnabled {
-		streamer = streaming.NewJSONStreamer(true, os.Stdout)
+		streamer, err = streaming.Open(*streamJSON, sinkConfigs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "--stream-sinks: %v\n", err)
+			os.Exit(1)
+		}
+		streamer.SetAgent(streaming.AgentReview)
 		streamer.EmitThreadStarted(tsk, conf.ProjectName, *parent, *headless)
 	}
 
+	var baseline *prreview.ReviewState
+	head := strings.TrimSpace(*headSHA)
+	if *stateFile != "" {
+		baseline, err = prreview.LoadReviewState(*stateFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Review state error: %v\n", err)
+			closeStreamer(streamer)
+			os.Exit(1)
+		}
+		if head == "" {
+			head = pullRequestHead(conf, *githubPR, tsk)
+		}
+	}
+
+	var suppressions *prreview.SuppressionStore
+	if *suppressionFile != "" {
+		suppressions, err = prreview.LoadSuppressionStore(*suppressionFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Suppression store error: %v\n", err)
+			closeStreamer(streamer)
+			os.Exit(1)
+		}
+	}
+
+	var formal prreview.FormalVerifier
+	if *formalVerify {
+		fv, err := newFormalVerifier(*explorationID, conf.ProjectName, streamStdout, *skillsDir, skills.ParseList(*skillNames))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Formal verifier error: %v\n", err)
+			closeStreamer(streamer)
+			os.Exit(1)
+		}
+		formal = fv
+	}
+
 	opts := prreview.Options{
-		Task:           tsk,
-		ProjectName:    conf.ProjectName,
-		ParentBranchID: *parent,
-		WorkspaceDir:   conf.WorkspaceDir,
-		SkipScout:      *skipScout,
-		SkipTester:     *skipTester,
+		Task:             tsk,
+		ProjectName:      conf.ProjectName,
+		ParentBranchID:   *parent,
+		WorkspaceDir:     conf.WorkspaceDir,
+		SkipScout:        *skipScout,
+		SkipTester:       *skipTester,
+		IssueParallelism: *issueParallelism,
+		SkipSpecialists:  *skipSpecialists,
+		HeadSHA:          head,
+		Baseline:         baseline,
+		Suppressions:     suppressions,
+		MaxIssues:        *maxIssues,
+		DropPolicy:       *dropPolicy,
+		FormalVerifier:   formal,
+		Skills:           skillSet,
 	}
 	runner, err := prreview.NewRunner(brain, handler, streamer, opts)
 	if err != nil {
@@ -88,6 +197,7 @@ func main() {
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "init error: %v\n", err)
+		closeStreamer(streamer)
 		os.Exit(1)
 	}
 
@@ -98,22 +208,132 @@ func main() {
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "workflow error: %v\n", err)
+		closeStreamer(streamer)
 		os.Exit(1)
 	}
 
+	if *stateFile != "" && result != nil {
+		if head == "" {
+			logx.Warningf("Head SHA unknown; not updating review state %s", *stateFile)
+		} else if err := prreview.SaveReviewState(*stateFile, prreview.NewReviewState(head, result)); err != nil {
+			logx.Warningf("Failed to save review state: %v", err)
+		}
+	}
+
+	if suppressions != nil {
+		if err := suppressions.Save(); err != nil {
+			logx.Warningf("Failed to save suppressions: %v", err)
+		}
+	}
+
+	var publication *ghreview.Publication
+	var publishErr error
+	if *githubReview && result != nil {
+		pub, err := publishReview(conf, *githubPR, result)
+		if err != nil {
+			publishErr = err
+			if streamer != nil && streamer.Enabled() {
+				streamer.EmitError("github", err.Error(), nil)
+			}
+		} else {
+			publication = &pub
+		}
+	}
+
 	status := "completed"
 	if result != nil && result.Status == "clean" {
 		status = "clean"
 	}
 	if streamer != nil && streamer.Enabled() && result != nil {
-		streamer.EmitThreadCompleted(status, result.Summary, map[string]any{
-			"task":    result.Task,
-			"status":  result.Status,
-			"summary": result.Summary,
-			"issues":  result.Issues,
-		})
+		report := map[string]any{
+			"task":             result.Task,
+			"status":           result.Status,
+			"summary":          result.Summary,
+			"issues":           result.Issues,
+			"prompt_templates": result.PromptTemplates,
+			"skills":           result.Skills,
+		}
+		if publication != nil {
+			report["github_review"] = publication
+		}
+		streamer.EmitThreadCompleted(status, result.Summary, report)
 	}
+	closeStreamer(streamer)
 
 	out, _ := json.MarshalIndent(result, "", "  ")
 	fmt.Fprintln(os.Stderr, string(out))
+	if *outputFile != "" && result != nil {
+		if err := writeOutput(*outputFile, format, result, conf.WorkspaceDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Output error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if publishErr != nil {
+		fmt.Fprintf(os.Stderr, "GitHub review error: %v\n", publishErr)
+		os.Exit(1)
+	}
+}
+
+// writeOutput writes result to path ("-" for stdout) in format.
+func writeOutput(path string, format export.Format, result *prreview.Result, workspaceDir string) error {
+	if path == "-" {
+		return export.WriteReview(os.Stdout, format, result, workspaceDir)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := export.WriteReview(f, format, result, workspaceDir); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// publishReview posts result to the pull request named by ref, or to the
+// first pull request URL in the task when ref is empty.
+func publishReview(conf cfg.AgentConfig, ref string, result *prreview.Result) (ghreview.Publication, error) {
+	pr, err := resolvePullRequest(ref, result.Task)
+	if err != nil {
+		return ghreview.Publication{}, err
+	}
+	client := ghreview.NewClient(conf.GitHubAPIURL, conf.GitHubToken)
+	return ghreview.Publish(client, pr, result)
+}
+
+// pullRequestHead looks up the head commit of the pull request under review.
+// It returns "" when the pull request cannot be resolved, which makes the run
+// review the full diff.
+func pullRequestHead(conf cfg.AgentConfig, ref, task string) string {
+	pr, err := resolvePullRequest(ref, task)
+	if err != nil {
+		logx.Warningf("Cannot determine head SHA (%v); pass --head-sha for incremental reviews", err)
+		return ""
+	}
+	info, err := ghreview.NewClient(conf.GitHubAPIURL, conf.GitHubToken).GetPullRequest(pr)
+	if err != nil {
+		logx.Warningf("Cannot fetch head SHA of %s: %v", pr, err)
+		return ""
+	}
+	return info.Head.SHA
+}
+
+// resolvePullRequest parses ref, or finds the first pull request URL in the
+// task when ref is empty.
+func resolvePullRequest(ref, task string) (ghreview.PullRequest, error) {
+	if strings.TrimSpace(ref) != "" {
+		return ghreview.ParsePullRequest(ref)
+	}
+	pr, ok := ghreview.FindPullRequest(task)
+	if !ok {
+		return ghreview.PullRequest{}, fmt.Errorf("no pull request URL in --task; pass --github-pr")
+	}
+	return pr, nil
+}
+
+// closeStreamer flushes the stream sinks and reports events they dropped.
+func closeStreamer(streamer *streaming.JSONStreamer) {
+	if err := streamer.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Stream sinks: %v\n", err)
+	}
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"review_agent/internal/prreview"
+)
+
+// runSuppress implements `review-agent suppress`: it records an issue a human
+// dismissed as a false positive, or lists the suppressions of a project.
+func runSuppress(args []string) int {
+	fs := flag.NewFlagSet("suppress", flag.ExitOnError)
+	file := fs.String("suppression-file", "", "Suppression store to update (required)")
+	project := fs.String("project-name", os.Getenv("PROJECT_NAME"), "Project the suppression applies to (default $PROJECT_NAME)")
+	issue := fs.String("issue", "", "Issue text to dismiss as a false positive")
+	list := fs.Bool("list", false, "List the project's suppressions instead of adding one")
+	_ = fs.Parse(args)
+
+	if strings.TrimSpace(*file) == "" {
+		fmt.Fprintln(os.Stderr, "suppress: --suppression-file is required")
+		return 2
+	}
+	name := strings.TrimSpace(*project)
+	if name == "" {
+		fmt.Fprintln(os.Stderr, "suppress: project name required via PROJECT_NAME or --project-name")
+		return 2
+	}
+	store, err := prreview.LoadSuppressionStore(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "suppress: %v\n", err)
+		return 1
+	}
+
+	if *list {
+		for _, entry := range store.Entries() {
+			if entry.Project != name {
+				continue
+			}
+			fmt.Printf("%s\t%s\thits=%d\t%s\n", entry.Fingerprint, entry.Reason, entry.Hits, firstLine(entry.IssueText))
+		}
+		return 0
+	}
+
+	text := strings.TrimSpace(*issue)
+	if text == "" {
+		fmt.Fprintln(os.Stderr, "suppress: --issue is required")
+		return 2
+	}
+	entry, added := store.Add(name, text, prreview.SuppressionDismissed)
+	if err := store.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "suppress: %v\n", err)
+		return 1
+	}
+	if added {
+		fmt.Printf("Suppressed %s: %s\n", entry.Fingerprint, firstLine(entry.IssueText))
+	} else {
+		fmt.Printf("Already suppressed as %s (%s)\n", entry.Fingerprint, entry.Reason)
+	}
+	return 0
+}
+
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	return text
+}
@@ -1,3 +1,7 @@
 module review_agent
 
 go 1.21
+
+require verify_agent v0.0.0
+
+replace verify_agent => ../verify_agent
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"review_agent/internal/logx"
+	"sync"
 	"time"
 )
 
@@ -36,6 +37,23 @@ type LLMBrain struct {
 	apiVersion string
 	maxRetries int
 	client     *http.Client
+
+	usageMu sync.Mutex
+	usage   Usage
+}
+
+// Usage counts the tokens reported by completed chat completion calls.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	Calls            int `json:"calls"`
+}
+
+// Usage returns the tokens used by this brain so far.
+func (b *LLMBrain) Usage() Usage {
+	b.usageMu.Lock()
+	defer b.usageMu.Unlock()
+	return b.usage
 }
 
 func NewLLMBrain(apiKey, endpoint, deployment, apiVersion string, maxRetries int) *LLMBrain {
@@ -64,6 +82,10 @@ type chatCompletionResponse struct {
 	Choices []struct {
 		Message ChatMessage `json:"message"`
 	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
 }
 
 func (b *LLMBrain) Complete(messages []ChatMessage, tools []map[string]any) (*chatCompletionResponse, error) {
@@ -97,6 +119,11 @@ func (b *LLMBrain) Complete(messages []ChatMessage, tools []map[string]any) (*ch
 				if err := json.Unmarshal(data, &out); err != nil {
 					lastErr = err
 				} else {
+					b.usageMu.Lock()
+					b.usage.PromptTokens += out.Usage.PromptTokens
+					b.usage.CompletionTokens += out.Usage.CompletionTokens
+					b.usage.Calls++
+					b.usageMu.Unlock()
 					return &out, nil
 				}
 			} else {
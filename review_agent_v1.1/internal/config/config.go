@@ -26,7 +26,9 @@ type AgentConfig struct {
 	WorklogFilename   string
 	ProjectName       string
 	WorkspaceDir      string
+	AgentRegistryFile string // optional JSON agent registry (AGENT_REGISTRY_FILE)
 	GitHubToken       string
+	GitHubAPIURL      string // REST API base for PR review publishing (GITHUB_API_URL)
 	GitUserName       string
 	GitUserEmail      string
 }
@@ -109,6 +111,14 @@ func FromEnv() (AgentConfig, error) {
 		return AgentConfig{}, errors.New("GITHUB_TOKEN must be set")
 	}
 
+	githubAPIURL := strings.TrimRight(strings.TrimSpace(os.Getenv("GITHUB_API_URL")), "/")
+	if githubAPIURL == "" {
+		githubAPIURL = "https://api.github.com"
+	}
+	if !(strings.HasPrefix(githubAPIURL, "http://") || strings.HasPrefix(githubAPIURL, "https://")) {
+		return AgentConfig{}, errors.New("GITHUB_API_URL must be a valid HTTP/HTTPS URL")
+	}
+
 	gitUserName := strings.TrimSpace(os.Getenv("GIT_AUTHOR_NAME"))
 	if gitUserName == "" {
 		return AgentConfig{}, errors.New("GIT_AUTHOR_NAME must be set")
@@ -131,7 +141,9 @@ func FromEnv() (AgentConfig, error) {
 		WorklogFilename:   "worklog.md",
 		ProjectName:       project,
 		WorkspaceDir:      workspace,
+		AgentRegistryFile: strings.TrimSpace(os.Getenv("AGENT_REGISTRY_FILE")),
 		GitHubToken:       githubToken,
+		GitHubAPIURL:      githubAPIURL,
 		GitUserName:       gitUserName,
 		GitUserEmail:      gitUserEmail,
 	}, nil
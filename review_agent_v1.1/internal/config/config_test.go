@@ -60,3 +60,30 @@ func TestFromEnv_RespectsLargerPollTimeout(t *testing.T) {
 		t.Fatalf("expected PollTimeout 3h, got %s", conf.PollTimeout)
 	}
 }
+
+func TestFromEnv_GitHubAPIURL(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("GITHUB_API_URL", "")
+
+	conf, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv returned error: %v", err)
+	}
+	if conf.GitHubAPIURL != "https://api.github.com" {
+		t.Fatalf("expected default GitHub API URL, got %q", conf.GitHubAPIURL)
+	}
+
+	t.Setenv("GITHUB_API_URL", "http://127.0.0.1:9999/api/v3/")
+	conf, err = FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv returned error: %v", err)
+	}
+	if conf.GitHubAPIURL != "http://127.0.0.1:9999/api/v3" {
+		t.Fatalf("expected trimmed GitHub API URL, got %q", conf.GitHubAPIURL)
+	}
+
+	t.Setenv("GITHUB_API_URL", "ftp://example.com")
+	if _, err := FromEnv(); err == nil {
+		t.Fatalf("expected invalid GITHUB_API_URL to be rejected")
+	}
+}
@@ -0,0 +1,93 @@
+// Package eval measures review quality over a labeled dataset of pull
+// requests, so prompt and template changes can be compared run to run.
+package eval
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Case is one labeled pull request. A case with no expected issues is a clean
+// PR: every issue the review confirms on it is a false positive.
+type Case struct {
+	ID             string          `json:"id"`
+	Task           string          `json:"task"`
+	ParentBranchID string          `json:"parent_branch_id"`
+	ProjectName    string          `json:"project_name,omitempty"`
+	ExpectedIssues []ExpectedIssue `json:"expected_issues"`
+}
+
+// ExpectedIssue is a ground-truth defect of a case.
+type ExpectedIssue struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	// Keywords must all appear (case-insensitive) in a reported issue for the
+	// keyword matcher to accept it. Without keywords the matcher needs half
+	// of the description's distinctive words.
+	Keywords []string `json:"keywords,omitempty"`
+	// File, when set, must be referenced by the reported issue.
+	File string `json:"file,omitempty"`
+}
+
+// LoadDataset reads a JSONL dataset: one Case per line. Blank lines and lines
+// starting with '#' are ignored.
+func LoadDataset(path string) ([]Case, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read dataset: %w", err)
+	}
+	return ParseDataset(data)
+}
+
+// ParseDataset parses JSONL dataset contents and fills in default IDs.
+func ParseDataset(data []byte) ([]Case, error) {
+	var cases []Case
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	lineNo := 0
+	seen := map[string]bool{}
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var c Case
+		if err := json.Unmarshal([]byte(line), &c); err != nil {
+			return nil, fmt.Errorf("dataset line %d: %w", lineNo, err)
+		}
+		c.ID = strings.TrimSpace(c.ID)
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("case-%03d", len(cases)+1)
+		}
+		if seen[c.ID] {
+			return nil, fmt.Errorf("dataset line %d: duplicate case id %q", lineNo, c.ID)
+		}
+		seen[c.ID] = true
+		if strings.TrimSpace(c.Task) == "" || strings.TrimSpace(c.ParentBranchID) == "" {
+			return nil, fmt.Errorf("dataset line %d: case %s needs task and parent_branch_id", lineNo, c.ID)
+		}
+		for i := range c.ExpectedIssues {
+			issue := &c.ExpectedIssues[i]
+			if strings.TrimSpace(issue.ID) == "" {
+				issue.ID = fmt.Sprintf("%s#%d", c.ID, i+1)
+			}
+			if strings.TrimSpace(issue.Description) == "" && len(issue.Keywords) == 0 {
+				return nil, fmt.Errorf("dataset line %d: expected issue %s needs a description or keywords", lineNo, issue.ID)
+			}
+		}
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(cases) == 0 {
+		return nil, errors.New("dataset contains no cases")
+	}
+	return cases, nil
+}
@@ -0,0 +1,355 @@
+package eval
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	b "review_agent/internal/brain"
+	"review_agent/internal/prreview"
+	"review_agent/internal/skills"
+)
+
+const confirmedStatus = "confirmed"
+
+var stepIndexRe = regexp.MustCompile(`_\d+$`)
+
+// RunFunc reviews one case and returns the result with the LLM tokens it
+// used.
+type RunFunc func(c Case) (*prreview.Result, b.Usage, error)
+
+// Evaluator runs the review pipeline over a dataset and scores it.
+type Evaluator struct {
+	Run         RunFunc
+	Matcher     Matcher
+	Concurrency int
+	// Label names the run in reports, e.g. a prompt-dir or git revision.
+	Label string
+}
+
+// Report is the outcome of one evaluation run. PromptTemplates and Skills
+// record the template and skill versions that produced it, so reports of
+// different prompt sets can be compared with Compare.
+type Report struct {
+	Label           string                `json:"label,omitempty"`
+	GeneratedAt     string                `json:"generated_at"`
+	Matcher         string                `json:"matcher"`
+	PromptTemplates map[string]string     `json:"prompt_templates,omitempty"`
+	Skills          []skills.Usage        `json:"skills,omitempty"`
+	Metrics         Metrics               `json:"metrics"`
+	Cost            Cost                  `json:"cost"`
+	Stages          map[string]StageStats `json:"stages"`
+	Cases           []CaseResult          `json:"cases"`
+}
+
+// Metrics aggregates issue-level outcomes. Confirmed issues are the review's
+// positives; issues the consensus left unresolved are its negatives.
+//
+//   - TruePositives: expected issues matched by a confirmed issue.
+//   - FalsePositives: confirmed issues matching no expected issue.
+//   - FalseNegatives: expected issues no confirmed issue matched.
+//   - TrueNegatives: unresolved issues matching no expected issue.
+//   - RejectedTrueIssues: expected issues found but left unresolved.
+//
+// FalsePositiveRate is FP / (FP + TN): how often the consensus stage lets a
+// spurious issue through. Ratios with a zero denominator are 0.
+type Metrics struct {
+	Cases              int     `json:"cases"`
+	FailedCases        int     `json:"failed_cases"`
+	TruePositives      int     `json:"true_positives"`
+	FalsePositives     int     `json:"false_positives"`
+	FalseNegatives     int     `json:"false_negatives"`
+	TrueNegatives      int     `json:"true_negatives"`
+	RejectedTrueIssues int     `json:"rejected_true_issues"`
+	Precision          float64 `json:"precision"`
+	Recall             float64 `json:"recall"`
+	F1                 float64 `json:"f1"`
+	FalsePositiveRate  float64 `json:"false_positive_rate"`
+	MeanLatencySeconds float64 `json:"mean_latency_seconds"`
+}
+
+// Cost counts what a run spent: LLM tokens of the orchestrating brain and
+// agent runs launched on Pantheon.
+type Cost struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	LLMCalls         int `json:"llm_calls"`
+	AgentRuns        int `json:"agent_runs"`
+}
+
+func (c *Cost) add(o Cost) {
+	c.PromptTokens += o.PromptTokens
+	c.CompletionTokens += o.CompletionTokens
+	c.LLMCalls += o.LLMCalls
+	c.AgentRuns += o.AgentRuns
+}
+
+// StageStats is the latency of one pipeline stage (scout, review,
+// verify_issue, ...). Numbered steps such as verify_issue_2 are folded into
+// their stage.
+type StageStats struct {
+	Runs         int     `json:"runs"`
+	TotalSeconds float64 `json:"total_seconds"`
+	MeanSeconds  float64 `json:"mean_seconds"`
+}
+
+// CaseResult scores one case.
+type CaseResult struct {
+	ID             string                `json:"id"`
+	Status         string                `json:"status"`
+	Error          string                `json:"error,omitempty"`
+	Expected       int                   `json:"expected"`
+	Matches        []IssueMatch          `json:"matches,omitempty"`
+	Missed         []string              `json:"missed,omitempty"`
+	FalsePositives []string              `json:"false_positives,omitempty"`
+	TruePositives  int                   `json:"true_positives"`
+	FalseNegatives int                   `json:"false_negatives"`
+	TrueNegatives  int                   `json:"true_negatives"`
+	RejectedTrue   int                   `json:"rejected_true_issues"`
+	LatencySeconds float64               `json:"latency_seconds"`
+	Stages         map[string]StageStats `json:"stages,omitempty"`
+	Cost           Cost                  `json:"cost"`
+	templates      map[string]string
+	skills         []skills.Usage
+}
+
+// IssueMatch pairs an expected issue with the reported issue that matched it.
+type IssueMatch struct {
+	ExpectedID string `json:"expected_id"`
+	IssueText  string `json:"issue_text"`
+	Status     string `json:"status"`
+}
+
+// Evaluate reviews every case, at most Concurrency at once, and returns the
+// scored report with cases in dataset order. A case whose review fails is
+// recorded as failed and excluded from the issue metrics.
+func (e Evaluator) Evaluate(cases []Case) (*Report, error) {
+	if e.Run == nil || e.Matcher == nil {
+		return nil, fmt.Errorf("evaluator requires a RunFunc and a Matcher")
+	}
+	limit := e.Concurrency
+	if limit <= 0 {
+		limit = 1
+	}
+	results := make([]CaseResult, len(cases))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, c := range cases {
+		wg.Add(1)
+		go func(i int, c Case) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = e.evaluateCase(c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := &Report{
+		Label:       e.Label,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Matcher:     e.Matcher.Name(),
+		Stages:      map[string]StageStats{},
+		Cases:       results,
+	}
+	report.aggregate()
+	return report, nil
+}
+
+func (e Evaluator) evaluateCase(c Case) CaseResult {
+	res := CaseResult{ID: c.ID, Expected: len(c.ExpectedIssues)}
+	start := time.Now()
+	result, usage, err := e.Run(c)
+	res.LatencySeconds = time.Since(start).Seconds()
+	res.Cost = Cost{PromptTokens: usage.PromptTokens, CompletionTokens: usage.CompletionTokens, LLMCalls: usage.Calls}
+	if err != nil {
+		res.Status, res.Error = "error", err.Error()
+		return res
+	}
+	if result == nil {
+		res.Status, res.Error = "error", "review returned no result"
+		return res
+	}
+	res.Status = result.Status
+	res.templates = result.PromptTemplates
+	res.skills = result.Skills
+	res.Stages = stageStats(result.ReviewStatistics)
+	res.Cost.AgentRuns = agentRuns(result)
+
+	// Confirmed issues claim expected issues first; unresolved ones are
+	// then checked against what is left to tell rejected true issues from
+	// true negatives.
+	matched := make([]bool, len(c.ExpectedIssues))
+	var confirmed, unresolved []prreview.IssueReport
+	for _, issue := range result.Issues {
+		if issue.Status == confirmedStatus {
+			confirmed = append(confirmed, issue)
+		} else {
+			unresolved = append(unresolved, issue)
+		}
+	}
+	for _, issue := range confirmed {
+		idx, err := e.firstMatch(c.ExpectedIssues, matched, issue)
+		if err != nil {
+			res.Status, res.Error = "error", err.Error()
+			return res
+		}
+		if idx < 0 {
+			res.FalsePositives = append(res.FalsePositives, firstLine(issue.IssueText))
+			continue
+		}
+		matched[idx] = true
+		res.TruePositives++
+		res.Matches = append(res.Matches, IssueMatch{ExpectedID: c.ExpectedIssues[idx].ID, IssueText: firstLine(issue.IssueText), Status: issue.Status})
+	}
+	rejected := append([]bool(nil), matched...)
+	for _, issue := range unresolved {
+		idx, err := e.firstMatch(c.ExpectedIssues, rejected, issue)
+		if err != nil {
+			res.Status, res.Error = "error", err.Error()
+			return res
+		}
+		if idx < 0 {
+			res.TrueNegatives++
+			continue
+		}
+		rejected[idx] = true
+		res.RejectedTrue++
+	}
+	for i, ok := range matched {
+		if !ok {
+			res.FalseNegatives++
+			res.Missed = append(res.Missed, c.ExpectedIssues[i].ID)
+		}
+	}
+	return res
+}
+
+func (e Evaluator) firstMatch(expected []ExpectedIssue, taken []bool, issue prreview.IssueReport) (int, error) {
+	for i, want := range expected {
+		if taken[i] {
+			continue
+		}
+		ok, err := e.Matcher.Match(want, issue)
+		if err != nil {
+			return -1, fmt.Errorf("match %s: %w", want.ID, err)
+		}
+		if ok {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+func (r *Report) aggregate() {
+	m := &r.Metrics
+	latency := 0.0
+	for _, c := range r.Cases {
+		m.Cases++
+		r.Cost.add(c.Cost)
+		if c.Error != "" {
+			m.FailedCases++
+			continue
+		}
+		if r.PromptTemplates == nil {
+			r.PromptTemplates = c.templates
+		}
+		if r.Skills == nil {
+			r.Skills = c.skills
+		}
+		latency += c.LatencySeconds
+		m.TruePositives += c.TruePositives
+		m.FalsePositives += len(c.FalsePositives)
+		m.FalseNegatives += c.FalseNegatives
+		m.TrueNegatives += c.TrueNegatives
+		m.RejectedTrueIssues += c.RejectedTrue
+		for name, stage := range c.Stages {
+			total := r.Stages[name]
+			total.Runs += stage.Runs
+			total.TotalSeconds += stage.TotalSeconds
+			r.Stages[name] = total
+		}
+	}
+	for name, stage := range r.Stages {
+		stage.MeanSeconds = ratio(stage.TotalSeconds, float64(stage.Runs))
+		r.Stages[name] = stage
+	}
+	m.Precision = ratio(float64(m.TruePositives), float64(m.TruePositives+m.FalsePositives))
+	m.Recall = ratio(float64(m.TruePositives), float64(m.TruePositives+m.FalseNegatives))
+	m.F1 = ratio(2*m.Precision*m.Recall, m.Precision+m.Recall)
+	m.FalsePositiveRate = ratio(float64(m.FalsePositives), float64(m.FalsePositives+m.TrueNegatives))
+	m.MeanLatencySeconds = ratio(latency, float64(m.Cases-m.FailedCases))
+}
+
+// stageStats folds the step timings of one review into per-stage latency.
+func stageStats(stats *prreview.ReviewStatistics) map[string]StageStats {
+	if stats == nil {
+		return nil
+	}
+	out := map[string]StageStats{}
+	for _, step := range stats.StepTimings {
+		d, err := time.ParseDuration(step.Duration)
+		if err != nil {
+			continue
+		}
+		name := stepIndexRe.ReplaceAllString(step.StepName, "")
+		stage := out[name]
+		stage.Runs++
+		stage.TotalSeconds += d.Seconds()
+		stage.MeanSeconds = stage.TotalSeconds / float64(stage.Runs)
+		out[name] = stage
+	}
+	return out
+}
+
+// agentRuns counts the agent runs a review launched: scout, review and
+// resolution checks from the step timings, every voter of every verification
+// round, the formal verification tasks, and the summary report.
+func agentRuns(result *prreview.Result) int {
+	runs := 0
+	if stats := result.ReviewStatistics; stats != nil {
+		for _, step := range stats.StepTimings {
+			switch name := stepIndexRe.ReplaceAllString(step.StepName, ""); name {
+			case "scout", "review", "recheck_issue":
+				runs++
+			}
+		}
+	}
+	for _, issue := range result.Issues {
+		if issue.CarriedFrom != "" {
+			continue
+		}
+		voters := len(issue.Specialists)
+		if issue.Alpha.Agent != "" {
+			voters++
+		}
+		if issue.Beta.Agent != "" {
+			voters++
+		}
+		runs += voters * (issue.ExchangeRounds + 1)
+		if issue.Formal != nil {
+			runs += issue.Formal.Tasks
+		}
+	}
+	if result.SummaryBranchID != "" {
+		runs++
+	}
+	return runs
+}
+
+func ratio(num, den float64) float64 {
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
+
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	return text
+}
@@ -0,0 +1,213 @@
+package eval
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	b "review_agent/internal/brain"
+	"review_agent/internal/prreview"
+)
+
+const datasetFixture = `
+# two labeled PRs and a clean one
+{"id":"lru","task":"add LRU cache","parent_branch_id":"br-1","expected_issues":[{"description":"stale read","keywords":["stale read"],"file":"cache/lru.go"},{"id":"lock","description":"missing unlock","keywords":["unlock"]}]}
+{"task":"add client timeout","parent_branch_id":"br-2","expected_issues":[{"description":"timeout ignored","keywords":["timeout"]}]}
+{"id":"clean","task":"rename field","parent_branch_id":"br-3","expected_issues":[]}
+`
+
+func fakeResults() map[string]*prreview.Result {
+	stats := func(steps ...string) *prreview.ReviewStatistics {
+		s := &prreview.ReviewStatistics{}
+		for _, step := range steps {
+			s.StepTimings = append(s.StepTimings, prreview.StepTiming{StepName: step, Duration: "2s"})
+		}
+		return s
+	}
+	voter := prreview.Transcript{Agent: "codex"}
+	return map[string]*prreview.Result{
+		"lru": {
+			Status:           "issues_found",
+			PromptTemplates:  map[string]string{"review.tmpl": "v1"},
+			ReviewStatistics: stats("review", "verify_issue_1", "verify_issue_2"),
+			SummaryBranchID:  "sum-1",
+			Issues: []prreview.IssueReport{
+				{IssueText: "P1: Stale read after eviction in cache/lru.go:10", Status: "confirmed", Alpha: voter, Beta: voter, ExchangeRounds: 1},
+				{IssueText: "Missing unlock on the error path", Status: "unresolved", Alpha: voter, Beta: voter},
+			},
+		},
+		"case-002": {
+			Status:           "issues_found",
+			PromptTemplates:  map[string]string{"review.tmpl": "v1"},
+			ReviewStatistics: stats("scout", "review", "verify_issue_1"),
+			Issues: []prreview.IssueReport{
+				{IssueText: "Retries are unbounded", Status: "confirmed", Alpha: voter, Beta: voter},
+			},
+		},
+	}
+}
+
+func fakeRun(results map[string]*prreview.Result) RunFunc {
+	return func(c Case) (*prreview.Result, b.Usage, error) {
+		result, ok := results[c.ID]
+		if !ok {
+			return nil, b.Usage{PromptTokens: 10, Calls: 1}, errors.New("pantheon unavailable")
+		}
+		return result, b.Usage{PromptTokens: 100, CompletionTokens: 20, Calls: 2}, nil
+	}
+}
+
+func TestParseDatasetFillsDefaults(t *testing.T) {
+	cases, err := ParseDataset([]byte(datasetFixture))
+	if err != nil {
+		t.Fatalf("ParseDataset error: %v", err)
+	}
+	if len(cases) != 3 || cases[1].ID != "case-002" || cases[0].ExpectedIssues[0].ID != "lru#1" || cases[0].ExpectedIssues[1].ID != "lock" {
+		t.Fatalf("unexpected cases %+v", cases)
+	}
+	if _, err := ParseDataset([]byte(`{"id":"a","task":"t"}`)); err == nil {
+		t.Fatalf("a case without parent_branch_id must be rejected")
+	}
+	if _, err := ParseDataset([]byte("{\"id\":\"a\",\"task\":\"t\",\"parent_branch_id\":\"p\"}\n{\"id\":\"a\",\"task\":\"t\",\"parent_branch_id\":\"p\"}")); err == nil {
+		t.Fatalf("duplicate case ids must be rejected")
+	}
+}
+
+func TestEvaluateScoresIssuesAndCost(t *testing.T) {
+	cases, err := ParseDataset([]byte(datasetFixture))
+	if err != nil {
+		t.Fatalf("ParseDataset error: %v", err)
+	}
+	results := fakeResults()
+	results["clean"] = &prreview.Result{Status: "no_issues"}
+	report, err := Evaluator{Run: fakeRun(results), Matcher: KeywordMatcher{}, Concurrency: 2, Label: "v1"}.Evaluate(cases)
+	if err != nil {
+		t.Fatalf("Evaluate error: %v", err)
+	}
+
+	m := report.Metrics
+	if m.TruePositives != 1 || m.FalsePositives != 1 || m.FalseNegatives != 2 || m.TrueNegatives != 0 || m.RejectedTrueIssues != 1 {
+		t.Fatalf("unexpected counts %+v", m)
+	}
+	if m.Precision != 0.5 || m.Recall != 1.0/3 || m.FalsePositiveRate != 1 {
+		t.Fatalf("unexpected ratios %+v", m)
+	}
+	lru := report.Cases[0]
+	if len(lru.Matches) != 1 || lru.Matches[0].ExpectedID != "lru#1" || len(lru.Missed) != 1 || lru.Missed[0] != "lock" {
+		t.Fatalf("unexpected lru scoring %+v", lru)
+	}
+	if got := report.Cases[1].FalsePositives; len(got) != 1 || got[0] != "Retries are unbounded" {
+		t.Fatalf("the unmatched confirmed issue must be a false positive, got %v", got)
+	}
+	// lru: review + 2 voters x 2 rounds + 2 voters + summary; case-002: scout + review + 2 voters.
+	if report.Cost.AgentRuns != 12 || report.Cost.PromptTokens != 300 || report.Cost.LLMCalls != 6 {
+		t.Fatalf("unexpected cost %+v", report.Cost)
+	}
+	if s := report.Stages["verify_issue"]; s.Runs != 3 || s.MeanSeconds != 2 {
+		t.Fatalf("numbered steps must fold into one stage, got %+v", report.Stages)
+	}
+	if report.PromptTemplates["review.tmpl"] != "v1" || report.Matcher != "keyword" {
+		t.Fatalf("report must record matcher and templates, got %+v", report)
+	}
+}
+
+func TestEvaluateRecordsFailedCases(t *testing.T) {
+	cases, _ := ParseDataset([]byte(datasetFixture))
+	report, err := Evaluator{Run: fakeRun(fakeResults()), Matcher: KeywordMatcher{}}.Evaluate(cases)
+	if err != nil {
+		t.Fatalf("Evaluate error: %v", err)
+	}
+	clean := report.Cases[2]
+	if clean.Status != "error" || !strings.Contains(clean.Error, "pantheon unavailable") || report.Metrics.FailedCases != 1 {
+		t.Fatalf("a failing review must be recorded, got %+v", clean)
+	}
+	if report.Metrics.FalseNegatives != 2 || report.Cost.PromptTokens != 210 {
+		t.Fatalf("failed cases must not count as misses but still cost, got %+v %+v", report.Metrics, report.Cost)
+	}
+
+	var out bytes.Buffer
+	WriteTable(&out, report)
+	for _, want := range []string{"clean", "error: pantheon unavailable", "precision=50.0%", "verify_issue"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("table missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestEvaluateCountsCleanCaseStagesAndRuns(t *testing.T) {
+	cases, _ := ParseDataset([]byte(datasetFixture))
+	results := map[string]*prreview.Result{
+		"clean": {
+			Status: "no_issues",
+			ReviewStatistics: &prreview.ReviewStatistics{StepTimings: []prreview.StepTiming{
+				{StepName: "scout", Duration: "1s"},
+				{StepName: "review", Duration: "3s"},
+			}},
+		},
+	}
+	report, err := Evaluator{Run: fakeRun(results), Matcher: KeywordMatcher{}}.Evaluate(cases[2:])
+	if err != nil {
+		t.Fatalf("Evaluate error: %v", err)
+	}
+	clean := report.Cases[0]
+	if clean.Stages["scout"].Runs != 1 || clean.Stages["review"].TotalSeconds != 3 {
+		t.Fatalf("a clean case must report its stage timings, got %+v", clean.Stages)
+	}
+	if clean.Cost.AgentRuns != 2 || report.Cost.AgentRuns != 2 {
+		t.Fatalf("a clean case must count its scout and review runs, got %+v", report.Cost)
+	}
+	if s := report.Stages["review"]; s.Runs != 1 || s.MeanSeconds != 3 {
+		t.Fatalf("clean case stages must be aggregated, got %+v", report.Stages)
+	}
+}
+
+func TestKeywordMatcherTokenisesDescriptionWithoutKeywords(t *testing.T) {
+	expected := ExpectedIssue{Description: "Unlocked map write in the LRU eviction path", File: "cache/lru.go"}
+	cases := []struct {
+		text string
+		want bool
+	}{
+		{"ISSUE: eviction writes the entries map without holding the lock in cache/lru.go:42", true},
+		{"ISSUE: LRU eviction drops the newest entry in cache/lru.go:42", false},
+		{"ISSUE: retries are unbounded in cache/lru.go:42", false},
+		{"ISSUE: unlocked map write during eviction in api/client.go:9", false},
+	}
+	for _, tc := range cases {
+		ok, err := KeywordMatcher{}.Match(expected, prreview.IssueReport{IssueText: tc.text})
+		if err != nil || ok != tc.want {
+			t.Fatalf("Match(%q) = %v, %v; want %v", tc.text, ok, err, tc.want)
+		}
+	}
+}
+
+func TestJudgeMatcherParsesVerdict(t *testing.T) {
+	var prompt string
+	judge := JudgeMatcher{Complete: func(p string) (string, error) {
+		prompt = p
+		return "Sure.\n```json\n{\"match\": true, \"reason\": \"same defect\"}\n```", nil
+	}}
+	ok, err := judge.Match(ExpectedIssue{Description: "stale read", File: "cache/lru.go"}, prreview.IssueReport{IssueText: "eviction race in cache/lru.go:10"})
+	if err != nil || !ok {
+		t.Fatalf("expected a match, got %v %v", ok, err)
+	}
+	if !strings.Contains(prompt, "stale read") || !strings.Contains(prompt, "eviction race") {
+		t.Fatalf("judge prompt must carry both issues:\n%s", prompt)
+	}
+	ok, err = judge.Match(ExpectedIssue{Description: "stale read", File: "api/client.go"}, prreview.IssueReport{IssueText: "eviction race in cache/lru.go:10"})
+	if err != nil || ok {
+		t.Fatalf("an issue in another file must not reach the judge, got %v %v", ok, err)
+	}
+}
+
+func TestCompareListsDeltasAndChangedTemplates(t *testing.T) {
+	base := &Report{Label: "main", PromptTemplates: map[string]string{"review.tmpl": "v1", "verify.tmpl": "v1"}, Metrics: Metrics{Precision: 0.5}}
+	cur := &Report{PromptTemplates: map[string]string{"review.tmpl": "v2", "verify.tmpl": "v1"}, Metrics: Metrics{Precision: 0.75}}
+	var out bytes.Buffer
+	Compare(&out, base, cur)
+	for _, want := range []string{"Compared with main", "+0.250", "Prompt templates changed: review.tmpl"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("comparison missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"review_agent/internal/prreview"
+)
+
+// Matcher decides whether a reported issue is the expected defect.
+type Matcher interface {
+	Name() string
+	Match(expected ExpectedIssue, found prreview.IssueReport) (bool, error)
+}
+
+// KeywordMatcher accepts a reported issue when every keyword of the expected
+// issue appears in its text and, if the expected issue names a file, the
+// issue references that file. Expected issues without keywords match when at
+// least half of the distinctive words of their description appear.
+type KeywordMatcher struct{}
+
+func (KeywordMatcher) Name() string { return "keyword" }
+
+func (KeywordMatcher) Match(expected ExpectedIssue, found prreview.IssueReport) (bool, error) {
+	text := strings.ToLower(found.IssueText)
+	keywords, need := expected.Keywords, len(expected.Keywords)
+	if len(keywords) == 0 {
+		keywords = descriptionWords(expected.Description)
+		need = (len(keywords) + 1) / 2
+	}
+	for _, kw := range keywords {
+		if kw = strings.ToLower(strings.TrimSpace(kw)); kw == "" || strings.Contains(text, kw) {
+			need--
+		}
+	}
+	return need <= 0 && referencesFile(found, expected.File), nil
+}
+
+var (
+	wordRe           = regexp.MustCompile(`[a-z0-9_.]+`)
+	descriptionStops = map[string]bool{
+		"about": true, "after": true, "also": true, "because": true, "before": true, "being": true,
+		"does": true, "from": true, "have": true, "into": true, "issue": true,
+		"when": true, "where": true, "which": true, "while": true, "will": true, "with": true,
+		"that": true, "their": true, "them": true, "then": true, "there": true, "this": true,
+		"used": true, "uses": true, "using": true, "without": true,
+	}
+)
+
+// descriptionWords returns the distinctive words of a description, or the
+// whole description when it has none.
+func descriptionWords(description string) []string {
+	description = strings.ToLower(strings.TrimSpace(description))
+	var words []string
+	seen := map[string]bool{}
+	for _, w := range wordRe.FindAllString(description, -1) {
+		w = strings.Trim(w, ".")
+		if len(w) < 4 || descriptionStops[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	if len(words) == 0 {
+		return []string{description}
+	}
+	return words
+}
+
+// JudgeMatcher asks an LLM whether the reported issue describes the expected
+// defect. Complete sends one prompt and returns the model's reply.
+type JudgeMatcher struct {
+	Complete func(prompt string) (string, error)
+}
+
+func (JudgeMatcher) Name() string { return "llm" }
+
+func (m JudgeMatcher) Match(expected ExpectedIssue, found prreview.IssueReport) (bool, error) {
+	if !referencesFile(found, expected.File) {
+		return false, nil
+	}
+	want := strings.TrimSpace(expected.Description)
+	if len(expected.Keywords) > 0 {
+		want += "\nKey terms: " + strings.Join(expected.Keywords, ", ")
+	}
+	if expected.File != "" {
+		want += "\nFile: " + expected.File
+	}
+	reply, err := m.Complete(prreview.BuildEvalJudgePrompt(want, found.IssueText))
+	if err != nil {
+		return false, err
+	}
+	var verdict struct {
+		Match  bool   `json:"match"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(jsonObject(reply)), &verdict); err != nil {
+		return false, fmt.Errorf("invalid judge reply %.200q: %w", reply, err)
+	}
+	return verdict.Match, nil
+}
+
+// referencesFile reports whether issue names file; an empty file always
+// matches. Paths are compared by suffix because agents print absolute or
+// workspace-relative paths.
+func referencesFile(issue prreview.IssueReport, file string) bool {
+	file = strings.Trim(strings.TrimSpace(file), "/")
+	if file == "" {
+		return true
+	}
+	for _, ref := range issue.References() {
+		if ref.Path == file || strings.HasSuffix(ref.Path, "/"+file) {
+			return true
+		}
+	}
+	return strings.Contains(issue.IssueText, file)
+}
+
+// jsonObject trims a reply to its outermost JSON object.
+func jsonObject(reply string) string {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return reply
+	}
+	return reply[start : end+1]
+}
@@ -0,0 +1,163 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"review_agent/internal/skills"
+)
+
+// LoadReport reads a report written by SaveReport.
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read report: %w", err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// SaveReport writes report as indented JSON.
+func SaveReport(path string, report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// WriteTable prints per-case scores, the aggregate metrics and per-stage
+// latency.
+func WriteTable(w io.Writer, report *Report) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CASE\tSTATUS\tEXPECTED\tTP\tFP\tFN\tTN\tLATENCY\tTOKENS\tAGENT RUNS")
+	for _, c := range report.Cases {
+		status := c.Status
+		if c.Error != "" {
+			status = "error: " + truncate(c.Error, 40)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%.1fs\t%d\t%d\n", c.ID, status, c.Expected, c.TruePositives,
+			len(c.FalsePositives), c.FalseNegatives, c.TrueNegatives, c.LatencySeconds,
+			c.Cost.PromptTokens+c.Cost.CompletionTokens, c.Cost.AgentRuns)
+	}
+	tw.Flush()
+
+	m := report.Metrics
+	fmt.Fprintf(w, "\n%d case(s), %d failed; matcher=%s", m.Cases, m.FailedCases, report.Matcher)
+	if report.Label != "" {
+		fmt.Fprintf(w, "; label=%s", report.Label)
+	}
+	fmt.Fprintf(w, "\nprecision=%s recall=%s f1=%s false_positive_rate=%s rejected_true_issues=%d\n",
+		pct(m.Precision, m.TruePositives+m.FalsePositives), pct(m.Recall, m.TruePositives+m.FalseNegatives),
+		pct(m.F1, m.TruePositives), pct(m.FalsePositiveRate, m.FalsePositives+m.TrueNegatives), m.RejectedTrueIssues)
+	fmt.Fprintf(w, "cost: %d prompt + %d completion tokens over %d LLM calls, %d agent runs; mean latency %.1fs\n",
+		report.Cost.PromptTokens, report.Cost.CompletionTokens, report.Cost.LLMCalls, report.Cost.AgentRuns, m.MeanLatencySeconds)
+
+	if len(report.Stages) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STAGE\tRUNS\tTOTAL\tMEAN")
+	for _, name := range sortedStages(report.Stages) {
+		s := report.Stages[name]
+		fmt.Fprintf(tw, "%s\t%d\t%.1fs\t%.1fs\n", name, s.Runs, s.TotalSeconds, s.MeanSeconds)
+	}
+	tw.Flush()
+}
+
+// Compare prints how current differs from base: metric and cost deltas, and
+// the prompt templates whose version changed between the two runs.
+func Compare(w io.Writer, base, current *Report) {
+	fmt.Fprintf(w, "Compared with %s (%s):\n", labelOf(base), base.GeneratedAt)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tBASE\tCURRENT\tDELTA")
+	rows := []struct {
+		name       string
+		base, curr float64
+	}{
+		{"precision", base.Metrics.Precision, current.Metrics.Precision},
+		{"recall", base.Metrics.Recall, current.Metrics.Recall},
+		{"f1", base.Metrics.F1, current.Metrics.F1},
+		{"false_positive_rate", base.Metrics.FalsePositiveRate, current.Metrics.FalsePositiveRate},
+		{"mean_latency_seconds", base.Metrics.MeanLatencySeconds, current.Metrics.MeanLatencySeconds},
+		{"tokens", float64(base.Cost.PromptTokens + base.Cost.CompletionTokens), float64(current.Cost.PromptTokens + current.Cost.CompletionTokens)},
+		{"agent_runs", float64(base.Cost.AgentRuns), float64(current.Cost.AgentRuns)},
+	}
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s\t%.3f\t%.3f\t%+.3f\n", row.name, row.base, row.curr, row.curr-row.base)
+	}
+	tw.Flush()
+
+	var changed []string
+	for name, version := range current.PromptTemplates {
+		if base.PromptTemplates[name] != version {
+			changed = append(changed, name)
+		}
+	}
+	for name := range base.PromptTemplates {
+		if _, ok := current.PromptTemplates[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	if skillVersions(base.Skills) != skillVersions(current.Skills) {
+		changed = append(changed, "skills ("+skillVersions(base.Skills)+" → "+skillVersions(current.Skills)+")")
+	}
+	sort.Strings(changed)
+	if len(changed) == 0 {
+		fmt.Fprintln(w, "Prompt templates: unchanged")
+		return
+	}
+	fmt.Fprintf(w, "Prompt templates changed: %s\n", strings.Join(changed, ", "))
+}
+
+// skillVersions renders skill usage as "name@version,..." or "none".
+func skillVersions(used []skills.Usage) string {
+	if len(used) == 0 {
+		return "none"
+	}
+	parts := make([]string, 0, len(used))
+	for _, u := range used {
+		parts = append(parts, u.Name+"@"+u.Version)
+	}
+	return strings.Join(parts, ",")
+}
+
+func sortedStages(stages map[string]StageStats) []string {
+	names := make([]string, 0, len(stages))
+	for name := range stages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func labelOf(r *Report) string {
+	if r.Label != "" {
+		return r.Label
+	}
+	return "baseline"
+}
+
+// pct formats a ratio, or n/a when its denominator was zero.
+func pct(v float64, den int) string {
+	if den == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f%%", v*100)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
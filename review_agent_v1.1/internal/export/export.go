@@ -0,0 +1,75 @@
+// Package export renders review results in formats CI systems consume
+// directly: SARIF 2.1.0 for code-scanning dashboards and JUnit XML for test
+// report viewers.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"review_agent/internal/prreview"
+)
+
+// Format selects the output encoding of a review result.
+type Format string
+
+const (
+	FormatJSON  Format = "json"
+	FormatSARIF Format = "sarif"
+	FormatJUnit Format = "junit"
+)
+
+// ParseFormat validates a --output-format value. Empty selects JSON.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return FormatJSON, nil
+	case FormatJSON, FormatSARIF, FormatJUnit:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (expected json, sarif or junit)", s)
+	}
+}
+
+// WriteReview writes result to w in the given format. sourceRoot is the
+// workspace directory agents report absolute paths under; locations are
+// made relative to it.
+func WriteReview(w io.Writer, format Format, result *prreview.Result, sourceRoot string) error {
+	if result == nil {
+		return fmt.Errorf("no review result to export")
+	}
+	switch format {
+	case FormatJSON, "":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	case FormatSARIF:
+		return writeSARIF(w, result, sourceRoot)
+	case FormatJUnit:
+		return writeJUnit(w, result)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// relativePath strips sourceRoot from an agent-reported path so it matches
+// the repository layout.
+func relativePath(p, sourceRoot string) string {
+	p = path.Clean(strings.TrimSpace(p))
+	root := strings.TrimRight(path.Clean(strings.TrimSpace(sourceRoot)), "/")
+	if root != "" && root != "." && strings.HasPrefix(p, root+"/") {
+		p = p[len(root)+1:]
+	}
+	return strings.TrimPrefix(p, "./")
+}
+
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	return strings.TrimSpace(text)
+}
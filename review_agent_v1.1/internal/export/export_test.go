@@ -0,0 +1,109 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"review_agent/internal/prreview"
+)
+
+func exportTestResult() *prreview.Result {
+	return &prreview.Result{
+		Task:               "Review https://github.com/org/repo/pull/7",
+		Status:             "issues",
+		Summary:            "Identified 2 P0/P1 issues (1 confirmed, 1 unresolved).",
+		HeadSHA:            "def456",
+		IncrementalBaseSHA: "abc123",
+		Issues: []prreview.IssueReport{
+			{
+				IssueText:          "ISSUE 1 (P0): nil map write in Store.Put\nSeverity: P0",
+				Status:             "confirmed",
+				Alpha:              prreview.Transcript{Text: "# VERDICT: CONFIRMED\nAnchor: /workspace/pkg/store.go:12\n"},
+				VerdictExplanation: "Round 1: aligned",
+			},
+			{
+				IssueText: "ISSUE 2 (P1): speculative race in pkg/store.go:11",
+				Status:    "unresolved",
+			},
+		},
+		ResolvedIssues: []prreview.IssueReport{
+			{IssueText: "ISSUE (P1): stale cache in cache/lru.go:40", Status: "resolved", CarriedFrom: "abc123"},
+		},
+		ReviewStatistics: &prreview.ReviewStatistics{TotalDuration: "1m30s"},
+	}
+}
+
+func TestWriteReviewSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReview(&buf, FormatSARIF, exportTestResult(), "/workspace"); err != nil {
+		t.Fatalf("WriteReview returned error: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v\n%s", err, buf.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF envelope %+v", log)
+	}
+	results := log.Runs[0].Results
+	if len(results) != 3 {
+		t.Fatalf("expected one result per issue report, got %d", len(results))
+	}
+
+	confirmed := results[0]
+	if confirmed.RuleID != "P0" || confirmed.Level != "error" || confirmed.BaselineState != "new" {
+		t.Fatalf("unexpected confirmed result %+v", confirmed)
+	}
+	loc := confirmed.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "pkg/store.go" || loc.ArtifactLocation.URIBaseID != "%SRCROOT%" || loc.Region.StartLine != 12 {
+		t.Fatalf("expected the anchor relative to the workspace, got %+v", loc)
+	}
+	if !strings.Contains(confirmed.Message.Text, "Round 1: aligned") {
+		t.Fatalf("message must carry the verdict explanation, got %q", confirmed.Message.Text)
+	}
+	if results[1].RuleID != "P1" || results[1].Level != "note" {
+		t.Fatalf("unresolved issues must be notes, got %+v", results[1])
+	}
+	if results[2].Kind != "pass" || results[2].BaselineState != "absent" {
+		t.Fatalf("resolved issues must be reported as absent passes, got %+v", results[2])
+	}
+}
+
+func TestWriteReviewJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReview(&buf, FormatJUnit, exportTestResult(), "/workspace"); err != nil {
+		t.Fatalf("WriteReview returned error: %v", err)
+	}
+	var doc junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JUnit XML: %v\n%s", err, buf.String())
+	}
+	if doc.Tests != 3 || doc.Failures != 1 || doc.Skipped != 1 || len(doc.Suites) != 1 {
+		t.Fatalf("unexpected totals %+v", doc)
+	}
+	suite := doc.Suites[0]
+	if suite.Time != "90.000" {
+		t.Fatalf("expected the review duration in seconds, got %q", suite.Time)
+	}
+	first := suite.Cases[0]
+	if first.Failure == nil || first.Failure.Type != "P0" || first.ClassName != "review-agent.P0" || first.Line != 12 {
+		t.Fatalf("unexpected confirmed testcase %+v", first)
+	}
+	if suite.Cases[1].Skipped == nil || suite.Cases[2].Failure != nil || suite.Cases[2].Skipped != nil {
+		t.Fatalf("unexpected unresolved/resolved testcases %+v", suite.Cases[1:])
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"": FormatJSON, "SARIF": FormatSARIF, " junit ": FormatJUnit} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Fatalf("ParseFormat(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseFormat("html"); err == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,110 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"review_agent/internal/prreview"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitOutcome `xml:"failure,omitempty"`
+	Skipped   *junitOutcome `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitOutcome struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// writeJUnit emits one testcase per issue report: confirmed issues fail,
+// unresolved ones are skipped, and issues resolved since an incremental base
+// pass.
+func writeJUnit(w io.Writer, result *prreview.Result) error {
+	suite := junitSuite{
+		Name:       "pr-review",
+		Properties: []junitProperty{{Name: "status", Value: result.Status}, {Name: "summary", Value: result.Summary}},
+		Cases:      []junitCase{},
+	}
+	if result.HeadSHA != "" {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "head_sha", Value: result.HeadSHA})
+	}
+	if result.ReviewStatistics != nil {
+		if d, err := time.ParseDuration(result.ReviewStatistics.TotalDuration); err == nil {
+			suite.Time = fmt.Sprintf("%.3f", d.Seconds())
+		}
+	}
+
+	issues := append(append([]prreview.IssueReport{}, result.Issues...), result.ResolvedIssues...)
+	for n, issue := range issues {
+		severity := issue.Severity()
+		if severity == "" {
+			severity = "P1"
+		}
+		tc := junitCase{
+			Name:      fmt.Sprintf("issue %d: %s", n+1, firstLine(issue.IssueText)),
+			ClassName: toolName + "." + severity,
+			SystemOut: strings.TrimSpace(issue.VerdictExplanation),
+		}
+		if refs := issue.References(); len(refs) > 0 {
+			tc.File, tc.Line = refs[0].Path, refs[0].Line
+		}
+		switch issue.Status {
+		case "confirmed":
+			tc.Failure = &junitOutcome{Message: firstLine(issue.IssueText), Type: severity, Body: strings.TrimSpace(issue.IssueText)}
+			suite.Failures++
+		case "resolved":
+		default:
+			tc.Skipped = &junitOutcome{Message: "no consensus between reviewers; not reported"}
+			suite.Skipped++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+
+	doc := junitSuites{Name: toolName, Tests: suite.Tests, Failures: suite.Failures, Skipped: suite.Skipped, Suites: []junitSuite{suite}}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,177 @@
+package export
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"review_agent/internal/prreview"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "review-agent"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool         `json:"tool"`
+	Results    []sarifResult     `json:"results"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	DefaultConfig    sarifConfig  `json:"defaultConfiguration"`
+}
+
+type sarifConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Kind                string            `json:"kind,omitempty"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	BaselineState       string            `json:"baselineState,omitempty"`
+	Properties          map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifRules maps issue severities to rules. Issues whose text names no
+// severity are reported under P1.
+var sarifRules = []sarifRule{
+	{ID: "P0", ShortDescription: sarifMessage{Text: "P0 issue: crash, data loss or security"}, DefaultConfig: sarifConfig{Level: "error"}},
+	{ID: "P1", ShortDescription: sarifMessage{Text: "P1 issue: correctness or regression"}, DefaultConfig: sarifConfig{Level: "warning"}},
+}
+
+// writeSARIF emits one SARIF result per issue report. Confirmed issues carry
+// their severity's level; unresolved ones (no consensus) are notes, and
+// issues resolved since an incremental base are reported with kind "pass".
+func writeSARIF(w io.Writer, result *prreview.Result, sourceRoot string) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: toolName, Rules: sarifRules}},
+		Results: []sarifResult{},
+	}
+	if result.HeadSHA != "" {
+		run.Properties = map[string]string{"headSha": result.HeadSHA}
+	}
+	for _, issue := range result.Issues {
+		run.Results = append(run.Results, sarifIssue(issue, result.IncrementalBaseSHA, sourceRoot))
+	}
+	for _, issue := range result.ResolvedIssues {
+		run.Results = append(run.Results, sarifIssue(issue, result.IncrementalBaseSHA, sourceRoot))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+func sarifIssue(issue prreview.IssueReport, baseSHA string, sourceRoot string) sarifResult {
+	rule := issue.Severity()
+	if rule == "" {
+		rule = "P1"
+	}
+	level := "warning"
+	if rule == "P0" {
+		level = "error"
+	}
+
+	text := strings.TrimSpace(issue.IssueText)
+	if explanation := strings.TrimSpace(issue.VerdictExplanation); explanation != "" {
+		text += "\n\nVerdict: " + explanation
+	}
+	res := sarifResult{
+		RuleID:  rule,
+		Level:   level,
+		Message: sarifMessage{Text: text},
+		PartialFingerprints: map[string]string{
+			"reviewAgentIssue/v1": fingerprint(issue.IssueText),
+		},
+		Properties: map[string]string{"status": issue.Status},
+	}
+	if explanation := strings.TrimSpace(issue.VerdictExplanation); explanation != "" {
+		res.Properties["verdictExplanation"] = explanation
+	}
+
+	switch issue.Status {
+	case "confirmed":
+		if baseSHA != "" {
+			res.BaselineState = "new"
+			if issue.CarriedFrom != "" {
+				res.BaselineState = "unchanged"
+			}
+		}
+	case "resolved":
+		res.Kind = "pass"
+		res.Level = "none"
+		res.BaselineState = "absent"
+	default:
+		res.Level = "note"
+	}
+
+	if refs := issue.References(); len(refs) > 0 {
+		uri := relativePath(refs[0].Path, sourceRoot)
+		artifact := sarifArtifactLocation{URI: uri}
+		if !strings.HasPrefix(uri, "/") {
+			artifact.URIBaseID = "%SRCROOT%"
+		} else {
+			artifact.URI = "file://" + uri
+		}
+		res.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: artifact,
+			Region:           sarifRegion{StartLine: refs[0].Line},
+		}}}
+	}
+	return res
+}
+
+// fingerprint identifies an issue across runs by its headline, so code
+// scanning dashboards can track it while line numbers move.
+func fingerprint(issueText string) string {
+	sum := sha1.Sum([]byte(strings.ToLower(firstLine(issueText))))
+	return hex.EncodeToString(sum[:8])
+}
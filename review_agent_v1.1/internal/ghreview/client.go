@@ -0,0 +1,268 @@
+package ghreview
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const perPage = 100
+
+// PullRequest identifies a GitHub pull request.
+type PullRequest struct {
+	Owner  string
+	Repo   string
+	Number int
+}
+
+func (p PullRequest) String() string {
+	return fmt.Sprintf("%s/%s#%d", p.Owner, p.Repo, p.Number)
+}
+
+var (
+	pullURLRe   = regexp.MustCompile(`https?://[^\s/]+/([\w.-]+)/([\w.-]+)/pull/(\d+)`)
+	pullShortRe = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)#(\d+)$`)
+)
+
+// ParsePullRequest accepts a pull request URL or an owner/repo#number
+// reference.
+func ParsePullRequest(ref string) (PullRequest, error) {
+	ref = strings.TrimSpace(ref)
+	m := pullShortRe.FindStringSubmatch(ref)
+	if m == nil {
+		m = pullURLRe.FindStringSubmatch(ref)
+	}
+	if m == nil {
+		return PullRequest{}, fmt.Errorf("invalid pull request reference %q (expected URL or owner/repo#number)", ref)
+	}
+	number, _ := strconv.Atoi(m[3])
+	return PullRequest{Owner: m[1], Repo: m[2], Number: number}, nil
+}
+
+// FindPullRequest returns the first pull request URL mentioned in text.
+func FindPullRequest(text string) (PullRequest, bool) {
+	m := pullURLRe.FindStringSubmatch(text)
+	if m == nil {
+		return PullRequest{}, false
+	}
+	number, _ := strconv.Atoi(m[3])
+	return PullRequest{Owner: m[1], Repo: m[2], Number: number}, true
+}
+
+// APIError is a non-2xx response from the GitHub API.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GitHub %s %s: HTTP %d: %.300s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// Client is a minimal GitHub REST client covering pull request reviews.
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewClient returns a client for the REST API at baseURL (for example
+// https://api.github.com, or a local fake in tests).
+func NewClient(baseURL, token string) *Client {
+	base := strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if base == "" {
+		base = "https://api.github.com"
+	}
+	return &Client{
+		baseURL: base,
+		token:   strings.TrimSpace(token),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// PullFile is one file entry of a pull request diff.
+type PullFile struct {
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+	Patch    string `json:"patch"`
+}
+
+// ReviewComment is an inline pull request review comment.
+type ReviewComment struct {
+	ID   int64  `json:"id"`
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Body string `json:"body"`
+}
+
+// Review is a submitted pull request review.
+type Review struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// DraftComment is an inline comment submitted with a new review.
+type DraftComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+type createReviewRequest struct {
+	Body     string         `json:"body"`
+	Event    string         `json:"event"`
+	Comments []DraftComment `json:"comments,omitempty"`
+}
+
+// PullRequestInfo is the subset of a pull request the reviewer needs.
+type PullRequestInfo struct {
+	Head struct {
+		SHA string `json:"sha"`
+	} `json:"head"`
+}
+
+// GetPullRequest fetches the pull request, including its head commit.
+func (c *Client) GetPullRequest(pr PullRequest) (PullRequestInfo, error) {
+	var info PullRequestInfo
+	err := c.do(http.MethodGet, fmt.Sprintf("/repos/%s/%s/pulls/%d", pr.Owner, pr.Repo, pr.Number), nil, &info)
+	return info, err
+}
+
+// ListFiles returns every file changed by the pull request.
+func (c *Client) ListFiles(pr PullRequest) ([]PullFile, error) {
+	var all []PullFile
+	err := c.paginate(c.pullPath(pr, "files"), func(data []byte) (int, error) {
+		var page []PullFile
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		all = append(all, page...)
+		return len(page), nil
+	})
+	return all, err
+}
+
+// ListReviewComments returns every inline review comment on the pull request.
+func (c *Client) ListReviewComments(pr PullRequest) ([]ReviewComment, error) {
+	var all []ReviewComment
+	err := c.paginate(c.pullPath(pr, "comments"), func(data []byte) (int, error) {
+		var page []ReviewComment
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		all = append(all, page...)
+		return len(page), nil
+	})
+	return all, err
+}
+
+// ListReviews returns every review submitted on the pull request.
+func (c *Client) ListReviews(pr PullRequest) ([]Review, error) {
+	var all []Review
+	err := c.paginate(c.pullPath(pr, "reviews"), func(data []byte) (int, error) {
+		var page []Review
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		all = append(all, page...)
+		return len(page), nil
+	})
+	return all, err
+}
+
+// CreateReview submits a COMMENT review with the given body and inline comments.
+func (c *Client) CreateReview(pr PullRequest, body string, comments []DraftComment) (Review, error) {
+	var review Review
+	err := c.do(http.MethodPost, c.pullPath(pr, "reviews"), createReviewRequest{
+		Body:     body,
+		Event:    "COMMENT",
+		Comments: comments,
+	}, &review)
+	return review, err
+}
+
+// UpdateReview replaces the body of an existing review.
+func (c *Client) UpdateReview(pr PullRequest, reviewID int64, body string) error {
+	path := c.pullPath(pr, fmt.Sprintf("reviews/%d", reviewID))
+	return c.do(http.MethodPut, path, map[string]string{"body": body}, nil)
+}
+
+// UpdateReviewComment replaces the body of an existing inline comment.
+func (c *Client) UpdateReviewComment(pr PullRequest, commentID int64, body string) error {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/comments/%d", pr.Owner, pr.Repo, commentID)
+	return c.do(http.MethodPatch, path, map[string]string{"body": body}, nil)
+}
+
+func (c *Client) pullPath(pr PullRequest, suffix string) string {
+	return fmt.Sprintf("/repos/%s/%s/pulls/%d/%s", pr.Owner, pr.Repo, pr.Number, suffix)
+}
+
+// paginate fetches path page by page until a page holds fewer than perPage
+// items. add decodes one page and returns its item count.
+func (c *Client) paginate(path string, add func(data []byte) (int, error)) error {
+	for page := 1; ; page++ {
+		var raw json.RawMessage
+		if err := c.do(http.MethodGet, fmt.Sprintf("%s?per_page=%d&page=%d", path, perPage, page), nil, &raw); err != nil {
+			return err
+		}
+		n, err := add(raw)
+		if err != nil {
+			return fmt.Errorf("decode %s: %w", path, err)
+		}
+		if n < perPage {
+			return nil
+		}
+	}
+}
+
+func (c *Client) do(method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("GitHub %s %s: read body: %w", method, path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Body: string(data)}
+	}
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("GitHub %s %s: decode response: %w", method, path, err)
+	}
+	return nil
+}
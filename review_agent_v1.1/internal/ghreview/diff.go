@@ -0,0 +1,77 @@
+package ghreview
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hunkHeaderRe = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// commentableLines returns the new-file line numbers a review comment can be
+// attached to (RIGHT side): added and context lines inside the patch hunks.
+func commentableLines(patch string) map[int]bool {
+	lines := map[int]bool{}
+	next := 0
+	inHunk := false
+	for _, line := range strings.Split(patch, "\n") {
+		if m := hunkHeaderRe.FindStringSubmatch(line); m != nil {
+			next, _ = strconv.Atoi(m[1])
+			inHunk = true
+			continue
+		}
+		if !inHunk {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "-"):
+			// Removed lines exist only on the LEFT side.
+		case strings.HasPrefix(line, "\\"):
+			// "\ No newline at end of file"
+		default:
+			lines[next] = true
+			next++
+		}
+	}
+	return lines
+}
+
+// diffIndex answers whether a file/line pair is part of the pull request diff.
+type diffIndex struct {
+	files map[string]map[int]bool
+}
+
+func newDiffIndex(files []PullFile) *diffIndex {
+	idx := &diffIndex{files: map[string]map[int]bool{}}
+	for _, f := range files {
+		if f.Status == "removed" {
+			continue
+		}
+		idx.files[f.Filename] = commentableLines(f.Patch)
+	}
+	return idx
+}
+
+// resolve maps a file reference from an agent transcript to the diff path.
+// Agents often print workspace-relative or absolute paths, so an unambiguous
+// suffix match on path segments is accepted.
+func (d *diffIndex) resolve(file string, line int) (string, bool) {
+	file = strings.TrimPrefix(path.Clean(strings.TrimSpace(file)), "./")
+	if lines, ok := d.files[file]; ok {
+		return file, lines[line]
+	}
+	match := ""
+	for name := range d.files {
+		if strings.HasSuffix(file, "/"+name) || strings.HasSuffix(name, "/"+file) {
+			if match != "" {
+				return "", false
+			}
+			match = name
+		}
+	}
+	if match == "" {
+		return "", false
+	}
+	return match, d.files[match][line]
+}
@@ -0,0 +1,314 @@
+package ghreview
+
+import (
+	"fmt"
+	"strings"
+
+	"review_agent/internal/logx"
+	"review_agent/internal/prreview"
+)
+
+const (
+	summaryMarker     = "<!-- review-agent:summary -->"
+	issueMarkerPrefix = "<!-- review-agent:issue:"
+	resolvedHeading   = "**review-agent: resolved**"
+)
+
+// Publication reports what Publish changed on the pull request.
+type Publication struct {
+	PullRequest string `json:"pull_request"`
+	ReviewID    int64  `json:"review_id,omitempty"`
+	Created     int    `json:"created"`
+	Updated     int    `json:"updated"`
+	Unanchored  int    `json:"unanchored"`
+	Resolved    int    `json:"resolved,omitempty"`
+}
+
+// inlineIssue groups the confirmed issues that resolve to one diff line.
+type inlineIssue struct {
+	path   string
+	line   int
+	issues []prreview.IssueReport
+}
+
+// keys returns the fingerprints of the grouped issues. The marker carries
+// them instead of the line, so an issue that moved keeps its comment.
+func (i *inlineIssue) keys() []string {
+	keys := make([]string, len(i.issues))
+	for n, issue := range i.issues {
+		keys[n] = issue.Fingerprint()
+	}
+	return keys
+}
+
+// priorComments indexes the markered comments of an earlier run. Each one is
+// claimed by at most one issue group.
+type priorComments struct {
+	byKey   map[string]ReviewComment
+	byPath  map[string][]ReviewComment
+	claimed map[int64]bool
+}
+
+func newPriorComments(existing []ReviewComment) *priorComments {
+	p := &priorComments{byKey: map[string]ReviewComment{}, byPath: map[string][]ReviewComment{}, claimed: map[int64]bool{}}
+	for _, comment := range existing {
+		keys := issueMarkerKeys(comment.Body)
+		for _, key := range keys {
+			p.byKey[commentKey(comment.Path, key)] = comment
+		}
+		if len(keys) > 0 {
+			p.byPath[comment.Path] = append(p.byPath[comment.Path], comment)
+		}
+	}
+	return p
+}
+
+// claim returns the unclaimed earlier comment on the group's file that
+// carries one of its fingerprints or, failing that, describes one of its
+// issues in similar words, since the issue parser rewords issues on every
+// run.
+func (p *priorComments) claim(i *inlineIssue) (ReviewComment, bool) {
+	for _, key := range i.keys() {
+		if comment, ok := p.byKey[commentKey(i.path, key)]; ok && !p.claimed[comment.ID] {
+			p.claimed[comment.ID] = true
+			return comment, true
+		}
+	}
+	for _, comment := range p.byPath[i.path] {
+		if p.claimed[comment.ID] {
+			continue
+		}
+		for _, text := range commentIssueTexts(comment.Body) {
+			for _, issue := range i.issues {
+				if prreview.SameIssue(text, issue.IssueText) {
+					p.claimed[comment.ID] = true
+					return comment, true
+				}
+			}
+		}
+	}
+	return ReviewComment{}, false
+}
+
+func (i *inlineIssue) body() string {
+	var sb strings.Builder
+	sb.WriteString(issueMarkerPrefix + strings.Join(i.keys(), ",") + " -->\n")
+	for n, issue := range i.issues {
+		if n > 0 {
+			sb.WriteString("\n---\n\n")
+		}
+		if issue.Status == "resolved" {
+			sb.WriteString(resolvedHeading + "\n\n~~" + firstLine(issue.IssueText) + "~~\n")
+			continue
+		}
+		sb.WriteString("**review-agent: confirmed P0/P1 issue**\n\n")
+		sb.WriteString(strings.TrimSpace(issue.IssueText))
+		sb.WriteString("\n")
+		if explanation := strings.TrimSpace(issue.VerdictExplanation); explanation != "" {
+			sb.WriteString("\n<sub>" + explanation + "</sub>\n")
+		}
+	}
+	return sb.String()
+}
+
+// Publish submits the confirmed issues of result as one pull request review.
+// Issues that resolve to a line in the diff become inline comments; the rest
+// are listed in the review summary. Comments left by an earlier run are
+// updated in place instead of being posted again.
+func Publish(client *Client, pr PullRequest, result *prreview.Result) (Publication, error) {
+	pub := Publication{PullRequest: pr.String()}
+	if result == nil {
+		return pub, fmt.Errorf("no review result to publish")
+	}
+
+	files, err := client.ListFiles(pr)
+	if err != nil {
+		return pub, err
+	}
+	inline, unanchored := placeIssues(newDiffIndex(files), result.Issues, "confirmed")
+	pub.Unanchored = len(unanchored)
+
+	existing, err := client.ListReviewComments(pr)
+	if err != nil {
+		return pub, err
+	}
+	priors := newPriorComments(existing)
+
+	var drafts []DraftComment
+	for _, issue := range inline {
+		body := issue.body()
+		if prior, ok := priors.claim(issue); ok {
+			if prior.Body != body {
+				if err := client.UpdateReviewComment(pr, prior.ID, body); err != nil {
+					return pub, err
+				}
+			}
+			pub.Updated++
+			continue
+		}
+		drafts = append(drafts, DraftComment{Path: issue.path, Line: issue.line, Side: "RIGHT", Body: body})
+	}
+	pub.Created = len(drafts)
+
+	// Mark comments of issues fixed since the last run instead of leaving
+	// them looking open.
+	resolved, _ := placeIssues(newDiffIndex(files), result.ResolvedIssues, "resolved")
+	for _, issue := range resolved {
+		prior, ok := priors.claim(issue)
+		if !ok || strings.Contains(prior.Body, resolvedHeading) {
+			continue
+		}
+		if err := client.UpdateReviewComment(pr, prior.ID, issue.body()); err != nil {
+			return pub, err
+		}
+		pub.Resolved++
+	}
+
+	reviews, err := client.ListReviews(pr)
+	if err != nil {
+		return pub, err
+	}
+	summary := summaryBody(result, inline, unanchored)
+	for _, review := range reviews {
+		if !strings.Contains(review.Body, summaryMarker) {
+			continue
+		}
+		if err := client.UpdateReview(pr, review.ID, summary); err != nil {
+			return pub, err
+		}
+		pub.ReviewID = review.ID
+		if len(drafts) > 0 {
+			note := fmt.Sprintf("review-agent: %d new inline comment(s). The summary review above has been updated.", len(drafts))
+			if _, err := client.CreateReview(pr, note, drafts); err != nil {
+				return pub, err
+			}
+		}
+		logx.Infof("Updated review %d on %s (%d new, %d updated inline comments)", review.ID, pr, pub.Created, pub.Updated)
+		return pub, nil
+	}
+
+	review, err := client.CreateReview(pr, summary, drafts)
+	if err != nil {
+		return pub, err
+	}
+	pub.ReviewID = review.ID
+	logx.Infof("Submitted review %d on %s (%d new, %d updated inline comments)", review.ID, pr, pub.Created, pub.Updated)
+	return pub, nil
+}
+
+// placeIssues splits the issues with the given status into inline comments
+// and issues that could not be anchored to the diff. Inline comments keep
+// issue order.
+func placeIssues(idx *diffIndex, issues []prreview.IssueReport, status string) ([]*inlineIssue, []prreview.IssueReport) {
+	var inline []*inlineIssue
+	var unanchored []prreview.IssueReport
+	byLocation := map[string]*inlineIssue{}
+	for _, issue := range issues {
+		if issue.Status != status {
+			continue
+		}
+		path, line, ok := locateIssue(idx, issue)
+		if !ok {
+			unanchored = append(unanchored, issue)
+			continue
+		}
+		loc := fmt.Sprintf("%s:%d", path, line)
+		if group, ok := byLocation[loc]; ok {
+			group.issues = append(group.issues, issue)
+			continue
+		}
+		group := &inlineIssue{path: path, line: line, issues: []prreview.IssueReport{issue}}
+		byLocation[loc] = group
+		inline = append(inline, group)
+	}
+	return inline, unanchored
+}
+
+// locateIssue returns the first file:line reference that lies in the diff.
+// The transcripts' Anchor lines are tried before references in the issue text.
+func locateIssue(idx *diffIndex, issue prreview.IssueReport) (string, int, bool) {
+	for _, ref := range issue.References() {
+		if path, ok := idx.resolve(ref.Path, ref.Line); ok {
+			return path, ref.Line, true
+		}
+	}
+	return "", 0, false
+}
+
+func commentKey(path, fingerprint string) string {
+	return path + "\x00" + fingerprint
+}
+
+// issueMarkerKeys returns the issue fingerprints in a comment's marker.
+func issueMarkerKeys(body string) []string {
+	start := strings.Index(body, issueMarkerPrefix)
+	if start < 0 {
+		return nil
+	}
+	rest := body[start+len(issueMarkerPrefix):]
+	end := strings.Index(rest, " -->")
+	if end < 0 {
+		return nil
+	}
+	return strings.Split(rest[:end], ",")
+}
+
+// commentIssueTexts returns the issue texts in a comment body written by
+// inlineIssue.body, without its marker, headings and explanations.
+func commentIssueTexts(body string) []string {
+	var texts []string
+	for _, section := range strings.Split(body, "\n---\n") {
+		var lines []string
+		for _, line := range strings.Split(section, "\n") {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, issueMarkerPrefix) || strings.HasPrefix(trimmed, "**review-agent:") || strings.HasPrefix(trimmed, "<sub>") {
+				continue
+			}
+			lines = append(lines, strings.Trim(trimmed, "~"))
+		}
+		if text := strings.TrimSpace(strings.Join(lines, "\n")); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+func summaryBody(result *prreview.Result, inline []*inlineIssue, unanchored []prreview.IssueReport) string {
+	var sb strings.Builder
+	sb.WriteString(summaryMarker + "\n")
+	sb.WriteString("## review-agent summary\n\n")
+	sb.WriteString(strings.TrimSpace(result.Summary) + "\n")
+
+	if len(inline) > 0 {
+		sb.WriteString("\n### Inline comments\n\n")
+		for _, issue := range inline {
+			for _, report := range issue.issues {
+				sb.WriteString(fmt.Sprintf("- `%s:%d`: %s\n", issue.path, issue.line, firstLine(report.IssueText)))
+			}
+		}
+	}
+	if len(result.ResolvedIssues) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### Resolved since %s\n\n", result.IncrementalBaseSHA))
+		for _, report := range result.ResolvedIssues {
+			sb.WriteString("- ~~" + firstLine(report.IssueText) + "~~\n")
+		}
+	}
+	if len(unanchored) > 0 {
+		sb.WriteString("\n### Confirmed issues outside the diff\n\n")
+		for _, report := range unanchored {
+			sb.WriteString(strings.TrimSpace(report.IssueText) + "\n\n")
+		}
+	}
+	if result.SummaryBranchID != "" {
+		sb.WriteString(fmt.Sprintf("\n<sub>Full report: review_summary.md on branch %s</sub>\n", result.SummaryBranchID))
+	}
+	return sb.String()
+}
+
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	return text
+}
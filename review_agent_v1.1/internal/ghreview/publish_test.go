@@ -0,0 +1,272 @@
+package ghreview
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"review_agent/internal/prreview"
+)
+
+// fakeGitHub is an in-memory stand-in for the pull request review endpoints.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	nextID   int64
+	files    []PullFile
+	comments []ReviewComment
+	reviews  []Review
+	patched  int
+	created  int
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer tok" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	base := "/repos/org/repo/pulls"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == base+"/7/files":
+		writeJSON(w, f.files)
+	case r.Method == http.MethodGet && r.URL.Path == base+"/7/comments":
+		writeJSON(w, f.comments)
+	case r.Method == http.MethodGet && r.URL.Path == base+"/7/reviews":
+		writeJSON(w, f.reviews)
+	case r.Method == http.MethodPost && r.URL.Path == base+"/7/reviews":
+		var req createReviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.nextID++
+		review := Review{ID: f.nextID, Body: req.Body}
+		f.reviews = append(f.reviews, review)
+		for _, c := range req.Comments {
+			f.nextID++
+			f.comments = append(f.comments, ReviewComment{ID: f.nextID, Path: c.Path, Line: c.Line, Body: c.Body})
+		}
+		f.created++
+		writeJSON(w, review)
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, base+"/7/reviews/"):
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		for i := range f.reviews {
+			if r.URL.Path == fmt.Sprintf("%s/7/reviews/%d", base, f.reviews[i].ID) {
+				f.reviews[i].Body = req["body"]
+				writeJSON(w, f.reviews[i])
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, base+"/comments/"):
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		for i := range f.comments {
+			if r.URL.Path == fmt.Sprintf("%s/comments/%d", base, f.comments[i].ID) {
+				f.comments[i].Body = req["body"]
+				f.patched++
+				writeJSON(w, f.comments[i])
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func testResult(explanation string) *prreview.Result {
+	return &prreview.Result{
+		Summary: "Identified 3 P0/P1 issues (2 confirmed, 1 unresolved).",
+		Issues: []prreview.IssueReport{
+			{
+				IssueText:          "ISSUE: nil map write in Store.Put",
+				Status:             "confirmed",
+				Alpha:              prreview.Transcript{Text: "# VERDICT: CONFIRMED\n\nClaim: nil map\nAnchor: /workspace/repo/pkg/store.go:12\n"},
+				VerdictExplanation: explanation,
+			},
+			{
+				IssueText: "ISSUE: config default drifted in cmd/main.go:400",
+				Status:    "confirmed",
+			},
+			{
+				IssueText: "ISSUE: speculative race in pkg/store.go:11",
+				Status:    "unresolved",
+			},
+		},
+	}
+}
+
+func TestPublishPostsInlineCommentsAndUpdatesThemOnRerun(t *testing.T) {
+	fake := &fakeGitHub{files: []PullFile{{
+		Filename: "pkg/store.go",
+		Status:   "modified",
+		Patch:    "@@ -10,3 +10,4 @@ func (s *Store) Put() {\n ctx\n-old\n+new\n+more\n ctx",
+	}}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	client := NewClient(srv.URL+"/", "tok")
+	pr := PullRequest{Owner: "org", Repo: "repo", Number: 7}
+
+	pub, err := Publish(client, pr, testResult("Round 1: aligned"))
+	if err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+	if pub.Created != 1 || pub.Updated != 0 || pub.Unanchored != 1 || pub.ReviewID == 0 {
+		t.Fatalf("unexpected first publication %+v", pub)
+	}
+	if len(fake.comments) != 1 || fake.comments[0].Path != "pkg/store.go" || fake.comments[0].Line != 12 {
+		t.Fatalf("expected one inline comment on pkg/store.go:12, got %+v", fake.comments)
+	}
+	if strings.Contains(fake.comments[0].Body, "speculative race") {
+		t.Fatalf("unresolved issues must not be posted: %q", fake.comments[0].Body)
+	}
+	summary := fake.reviews[0].Body
+	if !strings.Contains(summary, summaryMarker) || !strings.Contains(summary, "config default drifted") {
+		t.Fatalf("summary must list issues outside the diff, got %q", summary)
+	}
+
+	pub, err = Publish(client, pr, testResult("Round 2: aligned"))
+	if err != nil {
+		t.Fatalf("second Publish returned error: %v", err)
+	}
+	if pub.Created != 0 || pub.Updated != 1 || pub.ReviewID != fake.reviews[0].ID {
+		t.Fatalf("unexpected rerun publication %+v", pub)
+	}
+	if fake.created != 1 || len(fake.comments) != 1 || len(fake.reviews) != 1 {
+		t.Fatalf("rerun must not duplicate: reviews=%d comments=%d", len(fake.reviews), len(fake.comments))
+	}
+	if fake.patched != 1 || !strings.Contains(fake.comments[0].Body, "Round 2: aligned") {
+		t.Fatalf("expected the inline comment to be updated in place, got %q", fake.comments[0].Body)
+	}
+
+	fixed := testResult("")
+	fixed.IncrementalBaseSHA = "abc123"
+	fixed.ResolvedIssues = []prreview.IssueReport{fixed.Issues[0]}
+	fixed.ResolvedIssues[0].Status = "resolved"
+	fixed.Issues = fixed.Issues[1:]
+	pub, err = Publish(client, pr, fixed)
+	if err != nil {
+		t.Fatalf("third Publish returned error: %v", err)
+	}
+	if pub.Resolved != 1 || !strings.Contains(fake.comments[0].Body, resolvedHeading) {
+		t.Fatalf("expected the fixed issue's comment to be marked resolved, got %+v %q", pub, fake.comments[0].Body)
+	}
+	if !strings.Contains(fake.reviews[0].Body, "Resolved since abc123") {
+		t.Fatalf("summary must list resolved issues, got %q", fake.reviews[0].Body)
+	}
+}
+
+func TestPublishFollowsIssueThatMovedLines(t *testing.T) {
+	fake := &fakeGitHub{files: []PullFile{
+		{Filename: "pkg/store.go", Status: "modified", Patch: "@@ -10,3 +10,4 @@ func (s *Store) Put() {\n ctx\n-old\n+new\n+more\n ctx"},
+		{Filename: "pkg/cache.go", Status: "modified", Patch: "@@ -1,2 +1,3 @@\n ctx\n+new\n ctx"},
+	}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	client := NewClient(srv.URL+"/", "tok")
+	pr := PullRequest{Owner: "org", Repo: "repo", Number: 7}
+	at := func(anchor string) *prreview.Result {
+		return &prreview.Result{Issues: []prreview.IssueReport{{
+			IssueText: "ISSUE: nil map write in Store.Put",
+			Status:    "confirmed",
+			Alpha:     prreview.Transcript{Text: "Anchor: " + anchor},
+		}}}
+	}
+
+	if _, err := Publish(client, pr, at("pkg/store.go:12")); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+	pub, err := Publish(client, pr, at("pkg/store.go:13"))
+	if err != nil {
+		t.Fatalf("second Publish returned error: %v", err)
+	}
+	if pub.Created != 0 || pub.Updated != 1 || len(fake.comments) != 1 {
+		t.Fatalf("a moved issue must keep its comment, got %+v comments=%+v", pub, fake.comments)
+	}
+	pub, err = Publish(client, pr, at("pkg/cache.go:2"))
+	if err != nil {
+		t.Fatalf("third Publish returned error: %v", err)
+	}
+	if pub.Created != 1 || len(fake.comments) != 2 || fake.comments[1].Path != "pkg/cache.go" {
+		t.Fatalf("the same text on another file is a new comment, got %+v comments=%+v", pub, fake.comments)
+	}
+}
+
+func TestPublishUpdatesRewordedIssueComment(t *testing.T) {
+	fake := &fakeGitHub{files: []PullFile{{
+		Filename: "pkg/store.go",
+		Status:   "modified",
+		Patch:    "@@ -10,3 +10,4 @@ func (s *Store) Put() {\n ctx\n-old\n+new\n+more\n ctx",
+	}}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	client := NewClient(srv.URL+"/", "tok")
+	pr := PullRequest{Owner: "org", Repo: "repo", Number: 7}
+	worded := func(text string) *prreview.Result {
+		return &prreview.Result{Issues: []prreview.IssueReport{{
+			IssueText: text,
+			Status:    "confirmed",
+			Alpha:     prreview.Transcript{Text: "Anchor: pkg/store.go:12"},
+		}}}
+	}
+	first := "ISSUE: Store.Put writes to a nil map when the cache is cold, panicking the request"
+	second := "ISSUE: Store.Put writes into a nil map while the cache is cold, crashing the request"
+	if (prreview.IssueReport{IssueText: first}).Fingerprint() == (prreview.IssueReport{IssueText: second}).Fingerprint() {
+		t.Fatalf("the rewording must change the fingerprint for this test to mean anything")
+	}
+
+	if _, err := Publish(client, pr, worded(first)); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+	pub, err := Publish(client, pr, worded(second))
+	if err != nil {
+		t.Fatalf("second Publish returned error: %v", err)
+	}
+	if pub.Created != 0 || pub.Updated != 1 || len(fake.comments) != 1 {
+		t.Fatalf("a reworded issue must update its comment, got %+v comments=%+v", pub, fake.comments)
+	}
+	if !strings.Contains(fake.comments[0].Body, "crashing the request") {
+		t.Fatalf("expected the new wording in the comment, got %q", fake.comments[0].Body)
+	}
+}
+
+func TestCommentableLinesTracksRightSide(t *testing.T) {
+	patch := "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n@@ -20,2 +20,3 @@\n x\n+y\n z\n\\ No newline at end of file"
+	got := commentableLines(patch)
+	for _, line := range []int{1, 2, 3, 20, 21, 22} {
+		if !got[line] {
+			t.Fatalf("expected line %d to be commentable, got %v", line, got)
+		}
+	}
+	if got[4] || got[23] {
+		t.Fatalf("unexpected commentable lines %v", got)
+	}
+}
+
+func TestParsePullRequest(t *testing.T) {
+	for _, ref := range []string{"https://github.com/org/repo/pull/7", "org/repo#7", "https://github.com/org/repo/pull/7/files"} {
+		pr, err := ParsePullRequest(ref)
+		if err != nil || pr != (PullRequest{Owner: "org", Repo: "repo", Number: 7}) {
+			t.Fatalf("ParsePullRequest(%q) = %+v, %v", ref, pr, err)
+		}
+	}
+	if _, err := ParsePullRequest("org/repo"); err == nil {
+		t.Fatalf("expected an error for a reference without a number")
+	}
+	if pr, ok := FindPullRequest("Review https://github.com/org/repo/pull/7 please"); !ok || pr.Number != 7 {
+		t.Fatalf("FindPullRequest = %+v, %v", pr, ok)
+	}
+}
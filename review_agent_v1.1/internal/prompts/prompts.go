@@ -0,0 +1,189 @@
+// Package prompts loads agent prompt templates written in text/template.
+//
+// Templates ship embedded in the binary and can be replaced at startup by
+// same-named files from an override directory (--prompt-dir). Every template
+// is validated against a typed sample value before it is activated, so a
+// broken override fails fast instead of mid-run.
+package prompts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	templateExt    = ".tmpl"
+	sourceEmbedded = "embedded"
+	versionLength  = 12
+)
+
+// TemplateInfo describes where a template came from and which revision of
+// its text is in use.
+type TemplateInfo struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	Version string `json:"version"`
+}
+
+// Set is a parsed, validated collection of prompt templates.
+type Set struct {
+	root  *template.Template
+	infos map[string]TemplateInfo
+}
+
+// Load parses every *.tmpl file in embedded and overlays same-named files
+// from overrideDir (when non-empty). Override files that do not replace an
+// embedded template are rejected to catch typos. Each template listed in
+// samples is executed once against its sample data; templates without a
+// sample only need to parse (e.g. files holding shared {{define}} blocks).
+//
+// A single trailing newline at the end of a file is dropped so editors that
+// append one do not change the rendered prompt.
+func Load(embedded fs.FS, overrideDir string, samples map[string]any) (*Set, error) {
+	sources := map[string]string{}
+	texts := map[string]string{}
+
+	entries, err := fs.Glob(embedded, "templates/*"+templateExt)
+	if err != nil {
+		return nil, fmt.Errorf("list embedded templates: %w", err)
+	}
+	for _, entry := range entries {
+		raw, err := fs.ReadFile(embedded, entry)
+		if err != nil {
+			return nil, fmt.Errorf("read embedded template %s: %w", entry, err)
+		}
+		name := path.Base(entry)
+		texts[name] = trimFinalNewline(string(raw))
+		sources[name] = sourceEmbedded
+	}
+
+	if dir := strings.TrimSpace(overrideDir); dir != "" {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("prompt dir: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("prompt dir %s is not a directory", dir)
+		}
+		overrides, err := filepath.Glob(filepath.Join(dir, "*"+templateExt))
+		if err != nil {
+			return nil, fmt.Errorf("list prompt overrides: %w", err)
+		}
+		for _, file := range overrides {
+			name := filepath.Base(file)
+			if _, ok := texts[name]; !ok {
+				return nil, fmt.Errorf("prompt override %s does not match any known template (known: %s)", file, strings.Join(sortedKeys(texts), ", "))
+			}
+			raw, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("read prompt override %s: %w", file, err)
+			}
+			texts[name] = trimFinalNewline(string(raw))
+			sources[name] = file
+		}
+	}
+
+	root := template.New("prompts").Funcs(funcMap)
+	set := &Set{root: root, infos: map[string]TemplateInfo{}}
+	for _, name := range sortedKeys(texts) {
+		if _, err := root.New(name).Parse(texts[name]); err != nil {
+			return nil, fmt.Errorf("parse template %s (%s): %w", name, sources[name], err)
+		}
+		sum := sha256.Sum256([]byte(texts[name]))
+		set.infos[name] = TemplateInfo{
+			Name:    name,
+			Source:  sources[name],
+			Version: hex.EncodeToString(sum[:])[:versionLength],
+		}
+	}
+
+	for _, name := range sortedKeys(samples) {
+		if root.Lookup(name) == nil {
+			return nil, fmt.Errorf("template %s is missing", name)
+		}
+		if err := root.ExecuteTemplate(io.Discard, name, samples[name]); err != nil {
+			return nil, fmt.Errorf("validate template %s (%s): %w", name, sources[name], err)
+		}
+	}
+	return set, nil
+}
+
+// MustLoad is Load for the embedded defaults, which are covered by tests.
+func MustLoad(embedded fs.FS, samples map[string]any) *Set {
+	set, err := Load(embedded, "", samples)
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
+
+// Render executes the named template with data.
+func (s *Set) Render(name string, data any) (string, error) {
+	var sb strings.Builder
+	if err := s.root.ExecuteTemplate(&sb, name, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// Info returns the source and version of every loaded template.
+func (s *Set) Info() []TemplateInfo {
+	out := make([]TemplateInfo, 0, len(s.infos))
+	for _, name := range sortedKeys(s.infos) {
+		out = append(out, s.infos[name])
+	}
+	return out
+}
+
+// Versions maps template names to "<source>@<version>" for reports. Override
+// sources are reduced to "override" so reports do not leak local paths.
+func (s *Set) Versions() map[string]string {
+	out := make(map[string]string, len(s.infos))
+	for name, info := range s.infos {
+		source := info.Source
+		if source != sourceEmbedded {
+			source = "override"
+		}
+		out[name] = source + "@" + info.Version
+	}
+	return out
+}
+
+var funcMap = template.FuncMap{
+	// truncate cuts s to n bytes and appends "..." when it was longer.
+	"truncate": func(s string, n int) string {
+		if len(s) <= n {
+			return s
+		}
+		return s[:n] + "..."
+	},
+	"add":   func(a, b int) int { return a + b },
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+func trimFinalNewline(s string) string {
+	if strings.HasSuffix(s, "\r\n") {
+		return s[:len(s)-2]
+	}
+	return strings.TrimSuffix(s, "\n")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
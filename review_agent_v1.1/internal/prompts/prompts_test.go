@@ -0,0 +1,81 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type greetingData struct {
+	Name string
+}
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"templates/blocks.tmpl":   {Data: []byte(`{{define "sig"}}-- bot{{end}}` + "\n")},
+		"templates/greeting.tmpl": {Data: []byte("Hello {{.Name}}\n{{template \"sig\"}}\n")},
+	}
+}
+
+var testSamples = map[string]any{"greeting.tmpl": greetingData{Name: "x"}}
+
+func TestLoadEmbeddedDefaults(t *testing.T) {
+	set, err := Load(testFS(), "", testSamples)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	got, err := set.Render("greeting.tmpl", greetingData{Name: "Ada"})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if got != "Hello Ada\n-- bot" {
+		t.Fatalf("unexpected render %q", got)
+	}
+	if v := set.Versions()["greeting.tmpl"]; !strings.HasPrefix(v, "embedded@") {
+		t.Fatalf("expected embedded version, got %q", v)
+	}
+}
+
+func TestLoadOverrideReplacesTemplate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "greeting.tmpl"), []byte("Hi {{.Name}}!\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	base, _ := Load(testFS(), "", testSamples)
+	set, err := Load(testFS(), dir, testSamples)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	got, _ := set.Render("greeting.tmpl", greetingData{Name: "Ada"})
+	if got != "Hi Ada!" {
+		t.Fatalf("unexpected render %q", got)
+	}
+	version := set.Versions()["greeting.tmpl"]
+	if !strings.HasPrefix(version, "override@") || version == base.Versions()["greeting.tmpl"] {
+		t.Fatalf("expected a new override version, got %q", version)
+	}
+	if set.Versions()["blocks.tmpl"] != base.Versions()["blocks.tmpl"] {
+		t.Fatalf("untouched template should keep its embedded version")
+	}
+}
+
+func TestLoadRejectsInvalidOverrides(t *testing.T) {
+	cases := map[string]string{
+		"unknown.tmpl":  "anything",
+		"greeting.tmpl": "Hello {{.Missing}}",
+	}
+	for file, body := range cases {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(testFS(), dir, testSamples); err == nil {
+			t.Fatalf("expected error for override %s", file)
+		}
+	}
+	if _, err := Load(testFS(), filepath.Join(t.TempDir(), "missing"), testSamples); err == nil {
+		t.Fatalf("expected error for missing prompt dir")
+	}
+}
@@ -0,0 +1,139 @@
+package prreview
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"review_agent/internal/logx"
+)
+
+// Statuses reported by verify_agent's formal pipeline.
+const (
+	FormalBugConfirmed   = "bug_confirmed"
+	FormalBugWrong       = "bug_wrong"
+	FormalCannotDisprove = "cannot_disprove"
+	FormalError          = "error"
+)
+
+// formalAgent labels the formal verification wherever it appears next to the
+// voters' transcripts.
+const formalAgent = "formal_verifier"
+
+// FormalVerifier hands one issue to verify_agent's formal pipeline
+// (formalize → reachability → test generation). codeContext is the PR task;
+// parentBranchID is the branch the issue was found on.
+type FormalVerifier interface {
+	VerifyIssue(issueText, codeContext, parentBranchID string) (*FormalVerification, error)
+}
+
+// FormalVerification is the formal pipeline's outcome for one issue.
+type FormalVerification struct {
+	// Status is bug_confirmed, bug_wrong, cannot_disprove or error.
+	Status  string `json:"status"`
+	Summary string `json:"summary,omitempty"`
+	// TestStatus is the generated test's outcome: BUG_CONFIRMED, BUG_REFUTED
+	// or TEST_INCONCLUSIVE. Empty when the pipeline stopped before Task 3.
+	TestStatus    string `json:"test_status,omitempty"`
+	TestCase      string `json:"test_case,omitempty"`
+	TestExecution string `json:"test_execution,omitempty"`
+	// Tasks is the number of pipeline tasks (agent runs) that ran.
+	Tasks          int    `json:"tasks,omitempty"`
+	LatestBranchID string `json:"latest_branch_id,omitempty"`
+	Duration       string `json:"duration,omitempty"`
+}
+
+// vote maps the formal outcome onto the consensus. Only a test that
+// reproduced the bug confirms; a claim found invalid, unreachable or refuted
+// rejects. cannot_disprove, an inconclusive test and errors abstain, so they
+// neither block nor carry a consensus.
+func (f *FormalVerification) vote() string {
+	if f == nil {
+		return ""
+	}
+	switch f.Status {
+	case FormalBugConfirmed:
+		if f.TestStatus == "BUG_CONFIRMED" {
+			return "confirmed"
+		}
+	case FormalBugWrong:
+		return "rejected"
+	}
+	return ""
+}
+
+// transcript presents the formal outcome to the voters in exchange rounds.
+func (f *FormalVerification) transcript() Transcript {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Formal verification status: %s", f.Status)
+	if f.TestStatus != "" {
+		fmt.Fprintf(&sb, " (generated test: %s)", f.TestStatus)
+	}
+	if f.Summary != "" {
+		fmt.Fprintf(&sb, "\n%s", f.Summary)
+	}
+	if f.TestCase != "" {
+		fmt.Fprintf(&sb, "\n\n## Test Case\n%s", f.TestCase)
+	}
+	if f.TestExecution != "" {
+		fmt.Fprintf(&sb, "\n\n## Test Execution\n%s", f.TestExecution)
+	}
+	return Transcript{Agent: formalAgent, BranchID: f.LatestBranchID, Text: sb.String(), Verdict: f.vote()}
+}
+
+// runFormalVerification verifies issueText with the configured FormalVerifier.
+// It returns nil when formal verification is disabled; a failing pipeline is
+// recorded as an error outcome so the consensus proceeds without it.
+func (r *Runner) runFormalVerification(issueText string, parentBranchID string) *FormalVerification {
+	if r.opts.FormalVerifier == nil {
+		return nil
+	}
+	start := time.Now()
+	itemID := r.events.ToolStarted("formal_verification", formalAgent, map[string]any{"parent_branch_id": parentBranchID})
+	formal, err := r.opts.FormalVerifier.VerifyIssue(issueText, r.opts.Task, parentBranchID)
+	if err == nil && formal == nil {
+		err = fmt.Errorf("formal verifier returned no result")
+	}
+	if err != nil {
+		logx.Warningf("Formal verification failed; continuing without it. err=%v", err)
+		formal = &FormalVerification{Status: FormalError, Summary: err.Error()}
+	}
+	formal.Duration = time.Since(start).String()
+	status := "success"
+	if formal.Status == FormalError {
+		status = "error"
+	}
+	r.events.ToolCompleted(itemID, status, time.Since(start), formal.LatestBranchID, formal.Status)
+	return formal
+}
+
+// formalPeers returns the formal outcome as an extra peer opinion for the
+// exchange rounds, or nothing when there is no usable outcome.
+func formalPeers(formal *FormalVerification) []Transcript {
+	if formal == nil || formal.Status == FormalError {
+		return nil
+	}
+	return []Transcript{formal.transcript()}
+}
+
+// withFormalVote folds the formal vote into the voters' unanimous verdict:
+// an abstaining pipeline leaves it unchanged, and a contradicting one breaks
+// the consensus.
+func withFormalVote(verdict string, formal *FormalVerification) string {
+	vote := formal.vote()
+	if vote == "" || vote == verdict {
+		return verdict
+	}
+	return ""
+}
+
+// formalNote summarizes the formal outcome for a verdict explanation.
+func formalNote(formal *FormalVerification) string {
+	if formal == nil {
+		return ""
+	}
+	if formal.TestStatus != "" {
+		return fmt.Sprintf(" Formal verification: %s (test %s).", formal.Status, formal.TestStatus)
+	}
+	return fmt.Sprintf(" Formal verification: %s.", formal.Status)
+}
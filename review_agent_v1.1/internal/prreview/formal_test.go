@@ -0,0 +1,141 @@
+package prreview
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fakeFormalVerifier struct {
+	mu      sync.Mutex
+	result  *FormalVerification
+	err     error
+	parents []string
+}
+
+func (f *fakeFormalVerifier) VerifyIssue(issueText, codeContext, parentBranchID string) (*FormalVerification, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.parents = append(f.parents, parentBranchID)
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := *f.result
+	return &out, nil
+}
+
+func newFormalTestRunner(t *testing.T, client *fakeAgentClient, formal *fakeFormalVerifier, opts Options) *Runner {
+	t.Helper()
+	opts.FormalVerifier = formal
+	runner := newAlignmentTestRunner(t, client, opts)
+	runner.alignmentOverride = func(issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
+		return alignmentVerdict{Agree: true, Explanation: "same defect"}, nil
+	}
+	return runner
+}
+
+func TestConfirmIssueAttachesConfirmingFormalVerification(t *testing.T) {
+	client := newFakeAgentClient([]string{confirmedA}, []string{confirmedA})
+	formal := &fakeFormalVerifier{result: &FormalVerification{Status: FormalBugConfirmed, TestStatus: "BUG_CONFIRMED", TestCase: "func TestRace(t *testing.T) {}", Tasks: 3}}
+	runner := newFormalTestRunner(t, client, formal, Options{})
+
+	report, err := runner.confirmIssue("ISSUE: example", "discovery_branch", "")
+	if err != nil {
+		t.Fatalf("confirmIssue error: %v", err)
+	}
+	if report.Status != commentConfirmed || report.Formal == nil || report.Formal.TestCase == "" {
+		t.Fatalf("expected a confirmed issue carrying the generated test, got %+v", report)
+	}
+	if !strings.Contains(report.VerdictExplanation, "Formal verification: bug_confirmed (test BUG_CONFIRMED)") {
+		t.Fatalf("explanation must mention the formal outcome, got %q", report.VerdictExplanation)
+	}
+	if len(formal.parents) != 1 || formal.parents[0] != "discovery_branch" {
+		t.Fatalf("formal verification must run once from the issue's branch, got %v", formal.parents)
+	}
+}
+
+func TestConfirmIssueFormalRefutationBlocksConsensus(t *testing.T) {
+	client := newFakeAgentClient([]string{confirmedA}, []string{confirmedA})
+	formal := &fakeFormalVerifier{result: &FormalVerification{Status: FormalBugWrong, Summary: "Bug state is unreachable: guarded by mu", Tasks: 2}}
+	runner := newFormalTestRunner(t, client, formal, Options{})
+
+	report, err := runner.confirmIssue("ISSUE: example", "start", "")
+	if err != nil {
+		t.Fatalf("confirmIssue error: %v", err)
+	}
+	if report.Status != commentUnresolved || !strings.Contains(report.VerdictExplanation, "formal verdict contradicts the voters") || !strings.Contains(report.VerdictExplanation, "Formal verification: bug_wrong") {
+		t.Fatalf("a formal refutation must keep the issue unresolved, got %q: %q", report.Status, report.VerdictExplanation)
+	}
+	if report.ExchangeRounds != 0 {
+		t.Fatalf("expected no exchange round after the formal refutation, got %d", report.ExchangeRounds)
+	}
+	for _, call := range client.snapshot() {
+		if call.classifiedRound > 1 {
+			t.Fatalf("no exchange round may run once the formal verdict contradicts a unanimous vote:\n%s", call.prompt)
+		}
+	}
+	if rejectedByConsensus(report) {
+		t.Fatalf("an issue the voters confirmed must not be suppressed")
+	}
+}
+
+func TestConfirmIssueShowsFormalOutcomeInExchangeRounds(t *testing.T) {
+	client := newFakeAgentClient([]string{confirmedA}, []string{rejected})
+	formal := &fakeFormalVerifier{result: &FormalVerification{Status: FormalBugWrong, Summary: "Bug state is unreachable: guarded by mu", Tasks: 2}}
+	runner := newFormalTestRunner(t, client, formal, Options{})
+
+	report, err := runner.confirmIssue("ISSUE: example", "start", "")
+	if err != nil {
+		t.Fatalf("confirmIssue error: %v", err)
+	}
+	if report.Status != commentUnresolved {
+		t.Fatalf("a split vote must stay unresolved, got %q: %q", report.Status, report.VerdictExplanation)
+	}
+	exchanges := 0
+	for _, call := range client.snapshot() {
+		if call.classifiedRound > 1 {
+			exchanges++
+			if !strings.Contains(call.prompt, "Formal verification status: bug_wrong") || !strings.Contains(call.prompt, "guarded by mu") {
+				t.Fatalf("exchange rounds must show the formal outcome to the voters:\n%s", call.prompt)
+			}
+		}
+	}
+	if exchanges == 0 {
+		t.Fatalf("expected exchange rounds for a split vote")
+	}
+}
+
+func TestConfirmIssueIgnoresFailedOrInconclusiveFormalVerification(t *testing.T) {
+	for name, formal := range map[string]*fakeFormalVerifier{
+		"error":        {err: errors.New("mcp unavailable")},
+		"inconclusive": {result: &FormalVerification{Status: FormalBugConfirmed, TestStatus: "TEST_INCONCLUSIVE"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := newFakeAgentClient([]string{confirmedA}, []string{confirmedA})
+			runner := newFormalTestRunner(t, client, formal, Options{})
+
+			report, err := runner.confirmIssue("ISSUE: example", "start", "")
+			if err != nil {
+				t.Fatalf("confirmIssue error: %v", err)
+			}
+			if report.Status != commentConfirmed || report.ExchangeRounds != 0 || report.Formal == nil {
+				t.Fatalf("an abstaining formal verification must not change the consensus, got %+v", report)
+			}
+		})
+	}
+}
+
+func TestConfirmIssueSkipTesterHonoursFormalRefutation(t *testing.T) {
+	client := newFakeAgentClient([]string{confirmedA}, nil)
+	formal := &fakeFormalVerifier{result: &FormalVerification{Status: FormalBugWrong}}
+	runner := newFormalTestRunner(t, client, formal, Options{SkipTester: true})
+
+	report, err := runner.confirmIssue("ISSUE: example", "start", "")
+	if err != nil {
+		t.Fatalf("confirmIssue error: %v", err)
+	}
+	if report.Status != commentUnresolved || !strings.Contains(report.VerdictExplanation, "formal verification refuted it") {
+		t.Fatalf("expected the refutation to override the lone reviewer, got %q: %q", report.Status, report.VerdictExplanation)
+	}
+}
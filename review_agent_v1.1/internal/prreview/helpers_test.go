@@ -7,22 +7,22 @@ import (
 
 func TestBuildIssueFinderPromptContainsInstructions(t *testing.T) {
 	task := "https://github.com/org/repo/pull/42"
-	got := buildIssueFinderPrompt(task, "/workspace/change_analysis.md")
+	got := buildIssueFinderPrompt(task, "/workspace/change_analysis.md", "")
 
 	required := []string{
 		"Task: " + task,
-		universalStudyLine,
+		promptBlock(t, "universal_study"),
 		"Change Analysis at:",
 		"Review the code changes against the base branch",
 		"git merge-base HEAD BASE_BRANCH",
 		"git diff MERGE_BASE_SHA",
-		"Analyze the code changes and identify critical P0/P1 issues only.",
+		"Step 3: Systematic Bug Detection",
 		"FINAL RESPONSE:",
 		"critical P0/P1 issue report",
 		"Do not include non-critical issues or general commentary.",
 		"cargo check --all-targets",
 		"cargo clippy --all-targets",
-		p0p1FocusBlock,
+		promptBlock(t, "p0p1_focus"),
 	}
 
 	for _, req := range required {
@@ -53,12 +53,12 @@ func TestBuildReviewerPromptContainsRoleDirectives(t *testing.T) {
 	prompt := buildLogicAnalystPrompt("some task", "some issue", "/workspace/change_analysis.md")
 	requiredPhrases := []string{
 		"REVIEWER",
-		universalStudyLine,
+		promptBlock(t, "universal_study"),
 		"Simulate a group of senior programmers",
-		"Chesterton's Fence",
+		"ENCOURAGE CONFIRMATION OF VALID ISSUES",
 		"VERDICT",
 		"Change Analysis at:",
-		p0p1VerdictGateBlock,
+		promptBlock(t, "p0p1_verdict_gate"),
 		"cargo check --all-targets",
 		"cargo clippy --all-targets",
 	}
@@ -73,12 +73,12 @@ func TestBuildTesterPromptContainsRoleDirectives(t *testing.T) {
 	prompt := buildTesterPrompt("some task", "some issue", "/workspace/change_analysis.md")
 	requiredPhrases := []string{
 		"TESTER",
-		universalStudyLine,
+		promptBlock(t, "universal_study"),
 		"Simulate a QA engineer",
 		"MUST actually run code",
 		"Do NOT run `cargo test`",
 		"Use ONLY extremely small, targeted test batches",
-		p0p1VerdictGateBlock,
+		promptBlock(t, "p0p1_verdict_gate"),
 		"cargo check --all-targets",
 		"cargo clippy --all-targets",
 		"Do NOT fabricate",
@@ -100,7 +100,7 @@ func TestBuildExchangePromptIncludesSelfPeerAndReviewerGuidance(t *testing.T) {
 		"peer said hello",
 		"YOUR PREVIOUS OPINION",
 		"PEER'S OPINION",
-		p0p1VerdictGateBlock,
+		promptBlock(t, "p0p1_verdict_gate"),
 		"logic analysis",
 		"Do NOT claim you ran tests",
 	}
@@ -118,7 +118,7 @@ func TestBuildExchangePromptProvidesTesterGuidance(t *testing.T) {
 		"peer logic view",
 		"YOUR PREVIOUS OPINION",
 		"PEER'S OPINION",
-		p0p1VerdictGateBlock,
+		promptBlock(t, "p0p1_verdict_gate"),
 		"run code",
 		"real execution evidence",
 	}
@@ -152,11 +152,11 @@ func TestBuildScoutPromptWritesToPath(t *testing.T) {
 	prompt := buildScoutPrompt("task", "/workspace/change_analysis.md")
 	required := []string{
 		"Role: SCOUT",
-		universalStudyLine,
+		promptBlock(t, "universal_study"),
 		"Write the analysis to:",
 		"/workspace/change_analysis.md",
 		"# CHANGE ANALYSIS",
-		"High-Risk Areas (ranked)",
+		"High-Risk Areas (ranked by severity)",
 		"Before -> After",
 		"git merge-base HEAD BASE_BRANCH",
 		"git diff --name-status MERGE_BASE_SHA",
@@ -226,3 +226,12 @@ func TestExtractTranscriptVerdict(t *testing.T) {
 		})
 	}
 }
+
+func promptBlock(t *testing.T, name string) string {
+	t.Helper()
+	out, err := defaultPromptSet.Render(name, nil)
+	if err != nil {
+		t.Fatalf("render prompt block %s: %v", name, err)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package prreview
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	anchorLineRe = regexp.MustCompile(`(?im)^\s*anchor\s*:\s*(.+)$`)
+	fileLineRe   = regexp.MustCompile("([\\w./-]+\\.[\\w]+):(\\d+)")
+	severityRe   = regexp.MustCompile(`\bP([01])\b`)
+)
+
+// FileRef is a file:line reference found in an issue or its transcripts.
+type FileRef struct {
+	Path string
+	Line int
+}
+
+// References returns the file:line references of the issue in priority
+// order: the Anchor lines of the reviewer, verifier and specialist
+// transcripts first, then references in the issue text. Duplicates are
+// dropped.
+func (i IssueReport) References() []FileRef {
+	var candidates []string
+	transcripts := append([]Transcript{i.Alpha, i.Beta}, i.Specialists...)
+	for _, transcript := range transcripts {
+		for _, m := range anchorLineRe.FindAllStringSubmatch(transcript.Text, -1) {
+			candidates = append(candidates, m[1])
+		}
+	}
+	candidates = append(candidates, i.IssueText)
+
+	var refs []FileRef
+	seen := map[FileRef]bool{}
+	for _, text := range candidates {
+		for _, m := range fileLineRe.FindAllStringSubmatch(text, -1) {
+			line, err := strconv.Atoi(m[2])
+			if err != nil || line <= 0 {
+				continue
+			}
+			ref := FileRef{Path: m[1], Line: line}
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// Fingerprint identifies the issue across runs by the symbol it names and its
+// normalized text, so line shifts and renumbering keep it stable.
+func (i IssueReport) Fingerprint() string {
+	sum := sha1.Sum([]byte(issueSymbol(i.IssueText) + "\x00" + normalizeIssueText(i.IssueText)))
+	return hex.EncodeToString(sum[:6])
+}
+
+// Severity returns the parser's priority, else "P0" or "P1" as first stated
+// in the issue text, or "" when neither names one.
+func (i IssueReport) Severity() string {
+	if i.Priority == "P0" || i.Priority == "P1" {
+		return i.Priority
+	}
+	m := severityRe.FindStringSubmatch(strings.ToUpper(i.IssueText))
+	if m == nil {
+		return ""
+	}
+	return "P" + m[1]
+}
@@ -0,0 +1,185 @@
+package prreview
+
+import (
+	"embed"
+	"fmt"
+
+	"review_agent/internal/logx"
+	"review_agent/internal/prompts"
+)
+
+//go:embed templates/*.tmpl
+var promptFS embed.FS
+
+// Template names. Each file lives in templates/ and can be overridden via
+// ConfigurePrompts.
+const (
+	tmplIssueFinder   = "issue_finder.tmpl"
+	tmplScout         = "scout.tmpl"
+	tmplHasRealIssue  = "has_real_issue.tmpl"
+	tmplLogicAnalyst  = "logic_analyst.tmpl"
+	tmplTester        = "tester.tmpl"
+	tmplExchange      = "exchange.tmpl"
+	tmplVerifyAgent   = "verify_agent.tmpl"
+	tmplSpecialist    = "specialist.tmpl"
+	tmplResolution    = "resolution_check.tmpl"
+	tmplAlignment     = "alignment.tmpl"
+	tmplIssueParser   = "issue_parser.tmpl"
+	tmplSummaryReport = "summary_report.tmpl"
+	tmplEvalJudge     = "eval_judge.tmpl"
+)
+
+// IssueFinderPromptData feeds issue_finder.tmpl. SinceSHA, when set, scopes
+// the review to the commits after it.
+type IssueFinderPromptData struct {
+	Task               string
+	ChangeAnalysisPath string
+	SinceSHA           string
+}
+
+// ResolutionCheckPromptData feeds resolution_check.tmpl.
+type ResolutionCheckPromptData struct {
+	Task               string
+	IssueText          string
+	SinceSHA           string
+	ChangeAnalysisPath string
+}
+
+// ScoutPromptData feeds scout.tmpl.
+type ScoutPromptData struct {
+	Task       string
+	OutputPath string
+}
+
+// ReportPromptData feeds prompts that only need a review report
+// (has_real_issue.tmpl, issue_parser.tmpl).
+type ReportPromptData struct {
+	ReportText string
+}
+
+// VerificationPromptData feeds the Round 1 role prompts
+// (logic_analyst.tmpl, tester.tmpl).
+type VerificationPromptData struct {
+	Task               string
+	IssueText          string
+	ChangeAnalysisPath string
+}
+
+// ExchangePromptData feeds exchange.tmpl. Role is the raw role name; the
+// template normalizes it. Title and Focus are set for domain specialists.
+type ExchangePromptData struct {
+	Role               string
+	Task               string
+	IssueText          string
+	ChangeAnalysisPath string
+	SelfOpinion        string
+	PeerOpinion        string
+	Title              string
+	Focus              []string
+}
+
+// VerifyAgentPromptData feeds verify_agent.tmpl.
+type VerifyAgentPromptData struct {
+	Task               string
+	IssueText          string
+	ChangeAnalysisPath string
+	ReviewerOpinion    string
+}
+
+// SpecialistPromptData feeds specialist.tmpl.
+type SpecialistPromptData struct {
+	Task               string
+	IssueText          string
+	ChangeAnalysisPath string
+	Title              string
+	Focus              []string
+}
+
+// AlignmentPromptData feeds alignment.tmpl.
+type AlignmentPromptData struct {
+	IssueText   string
+	TranscriptA string
+	TranscriptB string
+}
+
+// EvalJudgePromptData feeds eval_judge.tmpl.
+type EvalJudgePromptData struct {
+	Expected string
+	Found    string
+}
+
+// SummaryReportPromptData feeds summary_report.tmpl.
+type SummaryReportPromptData struct {
+	Task            string
+	Result          *Result
+	OutputPath      string
+	ConfirmedCount  int
+	UnresolvedCount int
+}
+
+func promptSamples() map[string]any {
+	verification := VerificationPromptData{Task: "task", IssueText: "issue", ChangeAnalysisPath: "/workspace/change_analysis.md"}
+	sampleResult := &Result{
+		Status:       statusIssues,
+		ReviewerLogs: []ReviewerLog{{BranchID: "branch", Report: "report"}},
+		Issues:       []IssueReport{{IssueText: "issue", Status: commentConfirmed}},
+		ReviewStatistics: &ReviewStatistics{
+			AbnormalSteps:   []AbnormalStep{{StepName: "step"}},
+			StepTimings:     []StepTiming{{StepName: "step"}},
+			IssueStatistics: map[string]IssueStatistic{"issue": {}},
+		},
+	}
+	return map[string]any{
+		tmplIssueFinder:   IssueFinderPromptData{Task: "task", ChangeAnalysisPath: "/workspace/change_analysis.md"},
+		tmplScout:         ScoutPromptData{Task: "task", OutputPath: "/workspace/change_analysis.md"},
+		tmplHasRealIssue:  ReportPromptData{ReportText: "report"},
+		tmplLogicAnalyst:  verification,
+		tmplTester:        verification,
+		tmplExchange:      ExchangePromptData{Role: "reviewer", Task: "task", IssueText: "issue", SelfOpinion: "self", PeerOpinion: "peer"},
+		tmplVerifyAgent:   VerifyAgentPromptData{Task: "task", IssueText: "issue", ReviewerOpinion: "opinion"},
+		tmplResolution:    ResolutionCheckPromptData{Task: "task", IssueText: "issue", SinceSHA: "abc123"},
+		tmplSpecialist:    SpecialistPromptData{Task: "task", IssueText: "issue", Title: "Specialist", Focus: []string{"focus"}},
+		tmplAlignment:     AlignmentPromptData{IssueText: "issue", TranscriptA: "a", TranscriptB: "b"},
+		tmplIssueParser:   ReportPromptData{ReportText: "report"},
+		tmplSummaryReport: SummaryReportPromptData{Task: "task", Result: sampleResult, OutputPath: "/workspace/review_summary.md"},
+		tmplEvalJudge:     EvalJudgePromptData{Expected: "expected", Found: "found"},
+	}
+}
+
+var (
+	defaultPromptSet = prompts.MustLoad(promptFS, promptSamples())
+	activePromptSet  = defaultPromptSet
+)
+
+// ConfigurePrompts validates the templates in dir (overriding the embedded
+// defaults by file name) and makes them active. An empty dir restores the
+// defaults. Call it once at startup, before any Runner is used.
+func ConfigurePrompts(dir string) error {
+	set, err := prompts.Load(promptFS, dir, promptSamples())
+	if err != nil {
+		return err
+	}
+	activePromptSet = set
+	return nil
+}
+
+// PromptVersions reports the source and version of each active template.
+func PromptVersions() map[string]string {
+	return activePromptSet.Versions()
+}
+
+// renderPrompt executes an active template. Templates are validated when
+// loaded, so a render failure falls back to the embedded default rather than
+// sending an empty prompt.
+func renderPrompt(name string, data any) string {
+	out, err := activePromptSet.Render(name, data)
+	if err == nil {
+		return out
+	}
+	logx.Errorf("Rendering prompt template %s failed, using embedded default: %v", name, err)
+	out, err = defaultPromptSet.Render(name, data)
+	if err != nil {
+		panic(fmt.Sprintf("embedded prompt template %s failed to render: %v", name, err))
+	}
+	return out
+}
@@ -0,0 +1,169 @@
+package prreview
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Drop policies decide which parsed issues are verified when there are more
+// than Options.MaxIssues.
+const (
+	// DropPolicyRank verifies the best-ranked issues and drops the rest.
+	DropPolicyRank = "rank"
+	// DropPolicyKeepP0 ranks like DropPolicyRank but never drops a P0 issue,
+	// even past the cap.
+	DropPolicyKeepP0 = "keep-p0"
+	// DropPolicyOrder keeps the parser's order and drops the tail.
+	DropPolicyOrder = "order"
+)
+
+// parsedIssue is one issue split out of the review report.
+type parsedIssue struct {
+	Text string
+	// Priority is "P0", "P1" or "" when the parser gave none.
+	Priority string
+	// Confidence is "high", "medium" or "low".
+	Confidence string
+	// index is the position in the parser's output.
+	index int
+}
+
+// DroppedIssue is a parsed issue that was not verified because of the cap.
+type DroppedIssue struct {
+	IssueText  string `json:"issue_text"`
+	Priority   string `json:"priority,omitempty"`
+	Confidence string `json:"confidence,omitempty"`
+	// Rank is the issue's 1-based position after ranking.
+	Rank   int    `json:"rank"`
+	Reason string `json:"reason"`
+}
+
+func normalizeDropPolicy(policy string) (string, error) {
+	switch p := strings.ToLower(strings.TrimSpace(policy)); p {
+	case "":
+		return DropPolicyRank, nil
+	case DropPolicyRank, DropPolicyKeepP0, DropPolicyOrder:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown drop policy %q (expected rank, keep-p0 or order)", policy)
+	}
+}
+
+func newParsedIssue(text, priority, confidence string, index int) parsedIssue {
+	issue := parsedIssue{Text: strings.TrimSpace(text), index: index}
+	issue.Priority = strings.ToUpper(strings.TrimSpace(priority))
+	if issue.Priority != "P0" && issue.Priority != "P1" {
+		issue.Priority = IssueReport{IssueText: text}.Severity()
+	}
+	switch c := strings.ToLower(strings.TrimSpace(confidence)); c {
+	case "high", "low":
+		issue.Confidence = c
+	default:
+		issue.Confidence = "medium"
+	}
+	return issue
+}
+
+func severityRank(priority string) int {
+	switch priority {
+	case "P0":
+		return 0
+	case "P1":
+		return 1
+	default:
+		return 2
+	}
+}
+
+func confidenceRank(confidence string) int {
+	switch confidence {
+	case "high":
+		return 0
+	case "low":
+		return 2
+	default:
+		return 1
+	}
+}
+
+// issueFile is the file an issue names first, used for location diversity.
+func issueFile(text string) string {
+	if refs := (IssueReport{IssueText: text}).References(); len(refs) > 0 {
+		return refs[0].Path
+	}
+	return ""
+}
+
+// rankIssues orders issues by severity (P0 first), then confidence, then
+// location diversity: among otherwise equal issues, one in a file not yet
+// ranked comes before another in an already ranked file. Parser order breaks
+// the remaining ties.
+func rankIssues(issues []parsedIssue) []parsedIssue {
+	remaining := append([]parsedIssue(nil), issues...)
+	sort.SliceStable(remaining, func(i, j int) bool { return remaining[i].index < remaining[j].index })
+	files := make(map[int]string, len(remaining))
+	for _, issue := range remaining {
+		files[issue.index] = issueFile(issue.Text)
+	}
+
+	ranked := make([]parsedIssue, 0, len(remaining))
+	seen := map[string]bool{}
+	for len(remaining) > 0 {
+		best := 0
+		for i := 1; i < len(remaining); i++ {
+			if rankBefore(remaining[i], remaining[best], files, seen) {
+				best = i
+			}
+		}
+		pick := remaining[best]
+		ranked = append(ranked, pick)
+		if f := files[pick.index]; f != "" {
+			seen[f] = true
+		}
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return ranked
+}
+
+func rankBefore(a, b parsedIssue, files map[int]string, seen map[string]bool) bool {
+	if sa, sb := severityRank(a.Priority), severityRank(b.Priority); sa != sb {
+		return sa < sb
+	}
+	if ca, cb := confidenceRank(a.Confidence), confidenceRank(b.Confidence); ca != cb {
+		return ca < cb
+	}
+	if ra, rb := seen[files[a.index]], seen[files[b.index]]; ra != rb {
+		return !ra
+	}
+	return a.index < b.index
+}
+
+// capIssues applies the drop policy and returns the issues to verify, in
+// verification order, and the ones left out.
+func capIssues(issues []parsedIssue, limit int, policy string) ([]parsedIssue, []DroppedIssue) {
+	ordered := issues
+	if policy != DropPolicyOrder {
+		ordered = rankIssues(issues)
+	}
+	if limit <= 0 || len(ordered) <= limit {
+		return ordered, nil
+	}
+
+	var kept []parsedIssue
+	var dropped []DroppedIssue
+	for rank, issue := range ordered {
+		if len(kept) < limit || (policy == DropPolicyKeepP0 && issue.Priority == "P0") {
+			kept = append(kept, issue)
+			continue
+		}
+		dropped = append(dropped, DroppedIssue{
+			IssueText:  issue.Text,
+			Priority:   issue.Priority,
+			Confidence: issue.Confidence,
+			Rank:       rank + 1,
+			Reason:     fmt.Sprintf("over the cap of %d issues (drop policy %s)", limit, policy),
+		})
+	}
+	return kept, dropped
+}
@@ -0,0 +1,80 @@
+package prreview
+
+import (
+	"strings"
+	"testing"
+)
+
+func rankFixture() []parsedIssue {
+	return []parsedIssue{
+		newParsedIssue("P1: stale read in cache/lru.go:10", "P1", "high", 0),
+		newParsedIssue("P1: second stale read in cache/lru.go:40", "P1", "high", 1),
+		newParsedIssue("P1: missing timeout in api/client.go:22", "P1", "high", 2),
+		newParsedIssue("speculative overflow in api/page.go:8", "P1", "low", 3),
+		newParsedIssue("nil map write in pkg/store.go:12", "P0", "medium", 4),
+		newParsedIssue("P0 data loss in pkg/wal.go:90", "", "", 5),
+	}
+}
+
+func issueTexts(issues []parsedIssue) string {
+	var parts []string
+	for _, issue := range issues {
+		parts = append(parts, strings.SplitN(issue.Text, " in ", 2)[1])
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestRankIssuesOrdersBySeverityConfidenceAndDiversity(t *testing.T) {
+	ranked := rankIssues(rankFixture())
+	want := "pkg/store.go:12,pkg/wal.go:90,cache/lru.go:10,api/client.go:22,cache/lru.go:40,api/page.go:8"
+	if got := issueTexts(ranked); got != want {
+		t.Fatalf("unexpected ranking\n got %s\nwant %s", got, want)
+	}
+	if ranked[1].Priority != "P0" || ranked[1].Confidence != "medium" {
+		t.Fatalf("priority must fall back to the issue text, got %+v", ranked[1])
+	}
+}
+
+func TestCapIssuesPolicies(t *testing.T) {
+	kept, dropped := capIssues(rankFixture(), 3, DropPolicyRank)
+	if got := issueTexts(kept); got != "pkg/store.go:12,pkg/wal.go:90,cache/lru.go:10" {
+		t.Fatalf("rank policy kept %s", got)
+	}
+	if len(dropped) != 3 || dropped[0].Rank != 4 || !strings.Contains(dropped[0].IssueText, "api/client.go") {
+		t.Fatalf("every dropped issue must be listed with its rank, got %+v", dropped)
+	}
+
+	kept, dropped = capIssues(rankFixture(), 1, DropPolicyKeepP0)
+	if len(kept) != 2 || kept[1].Priority != "P0" || len(dropped) != 4 {
+		t.Fatalf("keep-p0 must keep every P0 past the cap, kept %d dropped %d", len(kept), len(dropped))
+	}
+
+	kept, dropped = capIssues(rankFixture(), 2, DropPolicyOrder)
+	if got := issueTexts(kept); got != "cache/lru.go:10,cache/lru.go:40" || len(dropped) != 4 {
+		t.Fatalf("order policy must keep the parser's head, kept %s", got)
+	}
+
+	if kept, dropped = capIssues(rankFixture(), -1, DropPolicyRank); len(kept) != 6 || dropped != nil {
+		t.Fatalf("a negative cap must keep everything")
+	}
+}
+
+func TestRunListsDroppedIssues(t *testing.T) {
+	client := newFakeAgentClient([]string{rejected}, []string{rejected})
+	runner := newAlignmentTestRunner(t, client, Options{SkipScout: true, MaxIssues: 1})
+	runner.hasRealIssueOverride = func(string) (bool, error) { return true, nil }
+	runner.parseIssuesOverride = func(string) ([]string, error) {
+		return []string{"P1: missing timeout in api/client.go:22", "P0: nil map write in pkg/store.go:12"}, nil
+	}
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Priority != "P0" {
+		t.Fatalf("expected the P0 issue to be verified first, got %#v", result.Issues)
+	}
+	if len(result.DroppedIssues) != 1 || result.DroppedIssues[0].Priority != "P1" || !strings.Contains(result.Summary, "1 lower-ranked issues were not verified") {
+		t.Fatalf("expected the dropped P1 to be listed, got %+v / %q", result.DroppedIssues, result.Summary)
+	}
+}
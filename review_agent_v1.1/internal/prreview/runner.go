@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	b "review_agent/internal/brain"
 	"review_agent/internal/logx"
+	"review_agent/internal/skills"
 	"review_agent/internal/streaming"
 	t "review_agent/internal/tools"
 )
@@ -20,6 +22,17 @@ const (
 	statusIssues      = "issues_found"
 	commentConfirmed  = "confirmed"
 	commentUnresolved = "unresolved"
+	commentResolved   = "resolved"
+
+	// maxVerificationRounds bounds the reviewer-vs-verify-agent consensus
+	// loop: Round 1 plus up to two exchange rounds.
+	maxVerificationRounds = 3
+	// maxReportedIssues is the default cap on how many parsed issues are
+	// verified and reported.
+	maxReportedIssues = 5
+	// defaultIssueParallelism is the number of issues verified at once when
+	// Options.IssueParallelism is unset.
+	defaultIssueParallelism = 2
 )
 
 // Options configures the PR review workflow.
@@ -29,7 +42,45 @@ type Options struct {
 	ParentBranchID string
 	WorkspaceDir   string
 	SkipScout      bool
-	SkipTester     bool
+	// SkipTester skips the verify agent and exchange rounds; the reviewer's
+	// Round 1 verdict alone decides each issue.
+	SkipTester bool
+	// IssueParallelism bounds how many issues are verified concurrently;
+	// 0 uses defaultIssueParallelism.
+	IssueParallelism int
+	// SkipSpecialists disables the domain specialists that the change
+	// analysis would otherwise add to the consensus vote.
+	SkipSpecialists bool
+	// HeadSHA is the PR head commit under review. Together with Baseline it
+	// enables incremental reviews.
+	HeadSHA string
+	// Baseline is the state saved by the previous run. When it names a
+	// different head commit, only the commits since then are reviewed and
+	// its confirmed issues are re-checked for resolution.
+	Baseline *ReviewState
+	// Suppressions holds known false positives. Matching parsed issues are
+	// skipped before verification, and issues every voter rejects are added.
+	Suppressions *SuppressionStore
+	// MaxIssues caps how many parsed issues are verified; 0 uses
+	// maxReportedIssues and a negative value disables the cap.
+	MaxIssues int
+	// DropPolicy selects which issues survive the cap: DropPolicyRank
+	// (default), DropPolicyKeepP0 or DropPolicyOrder.
+	DropPolicy string
+	// FormalVerifier, when set, runs verify_agent's formal pipeline on each
+	// issue alongside the consensus vote; see formal.go.
+	FormalVerifier FormalVerifier
+	// Skills are the skill packs enabled for this run; each phase injects
+	// the ones it declares in phaseSkills.
+	Skills *skills.Set
+}
+
+// phaseSkills declares the skill packs each phase accepts. Exchange rounds
+// fork from the voter's own branch, which already carries its skills.
+var phaseSkills = map[string][]string{
+	"review":       {"re2"},
+	"verify_agent": {"re2"},
+	"recheck":      {"re2"},
 }
 
 // Result captures the high-level outcome plus supporting artifacts.
@@ -43,6 +94,20 @@ type Result struct {
 	LatestBranchID   string            `json:"latest_branch_id,omitempty"`
 	SummaryBranchID  string            `json:"summary_branch_id,omitempty"`
 	ReviewStatistics *ReviewStatistics `json:"review_statistics,omitempty"`
+	PromptTemplates  map[string]string `json:"prompt_templates,omitempty"`
+	// Specialists maps each specialist selected from the change analysis to
+	// the keyword or path that triggered it.
+	Specialists map[string]string `json:"specialists,omitempty"`
+	// HeadSHA is the reviewed head commit; IncrementalBaseSHA is set when only
+	// the commits after it were reviewed.
+	HeadSHA            string `json:"head_sha,omitempty"`
+	IncrementalBaseSHA string `json:"incremental_base_sha,omitempty"`
+	// ResolvedIssues lists previously confirmed issues fixed since the base.
+	ResolvedIssues []IssueReport `json:"resolved_issues,omitempty"`
+	// DroppedIssues lists parsed issues left unverified by the issue cap.
+	DroppedIssues []DroppedIssue `json:"dropped_issues,omitempty"`
+	// Skills lists the skill packs injected into agent prompts.
+	Skills []skills.Usage `json:"skills,omitempty"`
 }
 
 // ReviewStatistics tracks the review process statistics
@@ -52,6 +117,8 @@ type ReviewStatistics struct {
 	StepTimings     []StepTiming              `json:"step_timings,omitempty"`
 	TotalDuration   string                    `json:"total_duration"`
 	IssueStatistics map[string]IssueStatistic `json:"issue_statistics,omitempty"`
+	// SuppressedIssues lists parsed issues skipped as known false positives.
+	SuppressedIssues []SuppressedIssue `json:"suppressed_issues,omitempty"`
 }
 
 // AbnormalStep records steps that had errors or unusual behavior
@@ -99,7 +166,10 @@ type Transcript struct {
 
 // IssueReport stores the consensus outcome for a single ISSUE block.
 type IssueReport struct {
-	IssueText                 string     `json:"issue_text"`
+	IssueText string `json:"issue_text"`
+	// Priority and Confidence come from the issue parser.
+	Priority                  string     `json:"priority,omitempty"`
+	Confidence                string     `json:"confidence,omitempty"`
 	Status                    string     `json:"status,omitempty"`
 	Alpha                     Transcript `json:"alpha,omitempty"` // Reviewer transcript
 	Beta                      Transcript `json:"beta,omitempty"`  // VerifyAgent transcript (adversarial review)
@@ -109,8 +179,15 @@ type IssueReport struct {
 	VerifyAgentRound2BranchID string     `json:"verify_agent_round2_branch_id,omitempty"`
 	ReviewerRound3BranchID    string     `json:"reviewer_round3_branch_id,omitempty"`
 	VerifyAgentRound3BranchID string     `json:"verify_agent_round3_branch_id,omitempty"`
-	ExchangeRounds            int        `json:"exchange_rounds,omitempty"`
-	VerdictExplanation        string     `json:"verdict_explanation,omitempty"`
+	// Specialists holds the latest transcript of each domain specialist.
+	Specialists        []Transcript `json:"specialists,omitempty"`
+	ExchangeRounds     int          `json:"exchange_rounds,omitempty"`
+	VerdictExplanation string       `json:"verdict_explanation,omitempty"`
+	// CarriedFrom is the head SHA of the run that originally confirmed an
+	// issue re-checked by an incremental review.
+	CarriedFrom string `json:"carried_from,omitempty"`
+	// Formal is the outcome of the formal verification pipeline, when enabled.
+	Formal *FormalVerification `json:"formal_verification,omitempty"`
 	// Keep Tester fields for backward compatibility
 	TesterRound1BranchID string `json:"tester_round1_branch_id,omitempty"`
 	TesterRound2BranchID string `json:"tester_round2_branch_id,omitempty"`
@@ -128,8 +205,16 @@ type Runner struct {
 	alignmentOverride func(issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error)
 	// hasRealIssueOverride is a test hook to avoid network calls in Run().
 	hasRealIssueOverride func(reportText string) (bool, error)
+	// parseIssuesOverride is a test hook to avoid network calls in Run().
+	parseIssuesOverride func(reportText string) ([]string, error)
+
+	// specialists vote alongside the reviewer and verify agent; selected
+	// from the change analysis in Run.
+	specialists []Specialist
 
-	// Statistics tracking
+	// Statistics tracking; statsMu guards statistics while issues are
+	// verified concurrently.
+	statsMu    sync.Mutex
 	statistics *ReviewStatistics
 	startTime  time.Time
 }
@@ -155,6 +240,11 @@ func NewRunner(brain *b.LLMBrain, handler *t.ToolHandler, streamer *streaming.JS
 	if opts.ParentBranchID == "" {
 		return nil, errors.New("parent branch id is required")
 	}
+	policy, err := normalizeDropPolicy(opts.DropPolicy)
+	if err != nil {
+		return nil, err
+	}
+	opts.DropPolicy = policy
 	return &Runner{
 		brain:    brain,
 		handler:  handler,
@@ -177,9 +267,26 @@ func (r *Runner) Run() (*Result, error) {
 	parent := r.opts.ParentBranchID
 
 	result := &Result{
-		Task:         r.opts.Task,
-		ReviewerLogs: []ReviewerLog{},
-		Issues:       []IssueReport{},
+		Task:            r.opts.Task,
+		ReviewerLogs:    []ReviewerLog{},
+		Issues:          []IssueReport{},
+		PromptTemplates: PromptVersions(),
+		HeadSHA:         r.opts.HeadSHA,
+	}
+	defer func() { result.Skills = r.opts.Skills.Used() }()
+
+	if r.opts.Baseline != nil && r.opts.HeadSHA != "" && r.opts.Baseline.HeadSHA == r.opts.HeadSHA {
+		result.Issues = append(result.Issues, r.opts.Baseline.Issues...)
+		result.IncrementalBaseSHA = r.opts.HeadSHA
+		if len(result.Issues) > 0 {
+			result.Status = statusIssues
+		} else {
+			result.Status = statusClean
+		}
+		result.Summary = fmt.Sprintf("No new commits since %s; %d previously confirmed P0/P1 issues remain.", r.opts.HeadSHA, len(result.Issues))
+		r.finalizeStatistics(result)
+		result.ReviewStatistics = r.statistics
+		return result, nil
 	}
 
 	scoutBranchID := parent
@@ -189,7 +296,7 @@ func (r *Runner) Run() (*Result, error) {
 	} else {
 		r.recordStepStart("scout")
 		startTime := time.Now()
-		if branchID, path, err := r.runScout(parent); err != nil {
+		if branchID, path, analysis, err := r.runScout(parent); err != nil {
 			logx.Warningf("SCOUT soft-failed; continuing without change analysis. err=%v", err)
 			r.recordAbnormalStep("scout", fmt.Sprintf("SCOUT soft-failed: %v", err))
 			r.recordStepEnd("scout", time.Since(startTime))
@@ -197,12 +304,35 @@ func (r *Runner) Run() (*Result, error) {
 			scoutBranchID = branchID
 			analysisPath = path
 			r.recordStepEnd("scout", time.Since(startTime))
+			if !r.opts.SkipSpecialists && !r.opts.SkipTester {
+				specialists, triggers := selectSpecialists(analysis)
+				r.specialists = specialists
+				if len(triggers) > 0 {
+					result.Specialists = triggers
+				}
+				for _, spec := range specialists {
+					logx.Infof("Adding %s to the consensus panel (%s)", spec.Title, triggers[spec.Name])
+				}
+			}
 		}
 	}
 
+	// Incremental runs re-check what the previous run confirmed before
+	// reviewing the new commits.
+	var carried []IssueReport
+	since := r.incrementalBase()
+	if since != "" {
+		result.IncrementalBaseSHA = since
+		logx.Infof("Incremental review of %s..%s", since, r.opts.HeadSHA)
+		r.recordStepStart("recheck")
+		recheckStartTime := time.Now()
+		carried, result.ResolvedIssues = r.recheckIssues(scoutBranchID, analysisPath)
+		r.recordStepEnd("recheck", time.Since(recheckStartTime))
+	}
+
 	r.recordStepStart("review")
 	reviewStartTime := time.Now()
-	reviewLog, err := r.runSingleReview(scoutBranchID, analysisPath)
+	reviewLog, err := r.runSingleReview(scoutBranchID, analysisPath, since)
 	if err != nil {
 		r.recordAbnormalStep("review", fmt.Sprintf("Review failed: %v", err))
 		r.recordStepEnd("review", time.Since(reviewStartTime))
@@ -211,59 +341,69 @@ func (r *Runner) Run() (*Result, error) {
 	r.recordStepEnd("review", time.Since(reviewStartTime))
 	result.ReviewerLogs = append(result.ReviewerLogs, reviewLog)
 
-	if strings.TrimSpace(reviewLog.Report) == "" {
-		result.Status = statusClean
-		result.Summary = "Clean PR: Not found any blocking P0/P1 issues."
-		r.attachBranchRange(result)
-		return result, nil
-	}
-
-	// Check if the report actually describes a real issue
-	hasIssue, err := r.hasRealIssue(reviewLog.Report)
+	issues, err := r.findIssues(reviewLog.Report)
 	if err != nil {
 		return nil, err
 	}
-	if !hasIssue {
-		result.Status = statusClean
-		result.Summary = "Clean PR: Not found any blocking P0/P1 issues."
-		r.attachBranchRange(result)
-		return result, nil
-	}
-
-	// Parse and split issues from the review report
-	issues, err := r.parseIssuesFromReport(reviewLog.Report)
-	if err != nil {
-		logx.Warningf("Failed to parse issues from report, treating as single issue: %v", err)
-		issues = []string{reviewLog.Report}
-	}
-
-	if len(issues) == 0 {
+	issues = r.suppressKnownFalsePositives(issues)
+	if len(issues) == 0 && len(carried) == 0 {
 		result.Status = statusClean
 		result.Summary = "Clean PR: Not found any blocking P0/P1 issues."
+		if suppressed := len(r.statistics.SuppressedIssues); suppressed > 0 {
+			result.Summary += fmt.Sprintf(" %d known false positives suppressed.", suppressed)
+		}
+		if since != "" {
+			result.Summary += fmt.Sprintf(" Incremental review since %s; %d previously confirmed issues resolved.", since, len(result.ResolvedIssues))
+		}
 		r.attachBranchRange(result)
+		r.finalizeStatistics(result)
+		result.ReviewStatistics = r.statistics
 		return result, nil
 	}
 
 	numIssues := len(issues)
 	logx.Infof("Parsed %d issues from review report", numIssues)
 
-	// Convert issues to IssueReport without verification
-	for _, issueText := range issues {
-		result.Issues = append(result.Issues, IssueReport{
-			IssueText:              issueText,
-			Status:                 statusIssues, // Mark as unresolved since we skip verification
-			ReviewerRound1BranchID: reviewLog.BranchID,
-		})
+	limit := r.opts.MaxIssues
+	if limit == 0 {
+		limit = maxReportedIssues
+	}
+	issues, result.DroppedIssues = capIssues(issues, limit, r.opts.DropPolicy)
+	for _, dropped := range result.DroppedIssues {
+		logx.Warningf("Not verifying issue ranked %d (%s, %s confidence): %s", dropped.Rank, dropped.Priority, dropped.Confidence, truncateForError(dropped.IssueText))
 	}
 
-	// Limit to maximum 5 issues
-	if len(result.Issues) > 5 {
-		logx.Infof("Limiting issues from %d to 5", len(result.Issues))
-		result.Issues = result.Issues[:5]
+	texts := make([]string, len(issues))
+	for i, issue := range issues {
+		texts[i] = issue.Text
+	}
+	r.recordStepStart("verify")
+	verifyStartTime := time.Now()
+	reports := r.confirmIssues(texts, reviewLog.BranchID, analysisPath)
+	r.recordStepEnd("verify", time.Since(verifyStartTime))
+	for i := range reports {
+		reports[i].Priority, reports[i].Confidence = issues[i].Priority, issues[i].Confidence
 	}
+	r.recordFalsePositives(reports)
+	result.Issues = append(carried, r.filterDuplicateVerifyBranches(reports)...)
 
-	result.Status = statusIssues
-	result.Summary = fmt.Sprintf("Identified %d P0/P1 issues.", len(result.Issues))
+	confirmed, unresolved := summarizeIssueCounts(result.Issues)
+	if confirmed > 0 {
+		result.Status = statusIssues
+		result.Summary = fmt.Sprintf("Identified %d P0/P1 issues (%d confirmed, %d unresolved).", len(result.Issues), confirmed, unresolved)
+	} else {
+		result.Status = statusClean
+		result.Summary = fmt.Sprintf("Clean PR: none of the %d reported P0/P1 issues was confirmed by consensus.", len(result.Issues))
+	}
+	if suppressed := len(r.statistics.SuppressedIssues); suppressed > 0 {
+		result.Summary += fmt.Sprintf(" %d known false positives suppressed.", suppressed)
+	}
+	if len(result.DroppedIssues) > 0 {
+		result.Summary += fmt.Sprintf(" %d lower-ranked issues were not verified (see dropped_issues).", len(result.DroppedIssues))
+	}
+	if since != "" {
+		result.Summary += fmt.Sprintf(" Incremental review since %s: %d previously confirmed issues still open, %d resolved.", since, len(carried), len(result.ResolvedIssues))
+	}
 	r.attachBranchRange(result)
 
 	// Finalize statistics
@@ -292,6 +432,8 @@ func (r *Runner) recordStepStart(stepName string) {
 	if r.statistics == nil {
 		return
 	}
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
 	r.statistics.TotalSteps++
 	r.statistics.StepTimings = append(r.statistics.StepTimings, StepTiming{
 		StepName:  stepName,
@@ -304,6 +446,8 @@ func (r *Runner) recordStepEnd(stepName string, duration time.Duration) {
 	if r.statistics == nil {
 		return
 	}
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
 	// Find the last step with this name and update it
 	for i := len(r.statistics.StepTimings) - 1; i >= 0; i-- {
 		if r.statistics.StepTimings[i].StepName == stepName && r.statistics.StepTimings[i].EndTime == "" {
@@ -319,6 +463,8 @@ func (r *Runner) recordAbnormalStep(stepName string, description string) {
 	if r.statistics == nil {
 		return
 	}
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
 	r.statistics.AbnormalSteps = append(r.statistics.AbnormalSteps, AbnormalStep{
 		StepName:    stepName,
 		Issue:       "Error or unusual behavior",
@@ -396,8 +542,8 @@ func (r *Runner) attachBranchRange(res *Result) {
 	}
 }
 
-func (r *Runner) runSingleReview(parentBranchID string, changeAnalysisPath string) (ReviewerLog, error) {
-	prompt := buildIssueFinderPrompt(r.opts.Task, changeAnalysisPath)
+func (r *Runner) runSingleReview(parentBranchID string, changeAnalysisPath string, sinceSHA string) (ReviewerLog, error) {
+	prompt := r.withSkills("review", buildIssueFinderPrompt(r.opts.Task, changeAnalysisPath, sinceSHA))
 	data, err := r.executeAgent("review_code", prompt, parentBranchID)
 	if err != nil {
 		return ReviewerLog{}, err
@@ -413,9 +559,309 @@ func (r *Runner) runSingleReview(parentBranchID string, changeAnalysisPath strin
 	}, nil
 }
 
+// confirmIssues verifies every issue with confirmIssue, running at most
+// Options.IssueParallelism issues at once. Reports keep the input order. An
+// issue whose verification fails is reported as unresolved rather than
+// aborting the whole review.
+func (r *Runner) confirmIssues(issues []string, startBranchID string, changeAnalysisPath string) []IssueReport {
+	reports := make([]IssueReport, len(issues))
+	r.forEachIssue(len(issues), func(i int) {
+		issueText := issues[i]
+		step := fmt.Sprintf("verify_issue_%d", i+1)
+		r.recordStepStart(step)
+		start := time.Now()
+		report, err := r.confirmIssue(issueText, startBranchID, changeAnalysisPath)
+		if err != nil {
+			logx.Warningf("Verification of issue %d failed; reporting it as unresolved. err=%v", i+1, err)
+			r.recordAbnormalStep(step, fmt.Sprintf("Verification failed: %v", err))
+			report = IssueReport{
+				IssueText:          issueText,
+				Status:             commentUnresolved,
+				VerdictExplanation: fmt.Sprintf("Verification failed: %v", err),
+			}
+		}
+		r.recordStepEnd(step, time.Since(start))
+		reports[i] = report
+	})
+	return reports
+}
+
+// forEachIssue calls fn for every index below n, running at most
+// Options.IssueParallelism calls at once.
+func (r *Runner) forEachIssue(n int, fn func(i int)) {
+	limit := r.opts.IssueParallelism
+	if limit <= 0 {
+		limit = defaultIssueParallelism
+	}
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// confirmIssue runs the reviewer-vs-verify-agent consensus loop for one issue.
+// Selected domain specialists join as extra voters. Round 1 is double-blind:
+// every voter forks from startBranchID without seeing the others. In each
+// later round every voter answers its peers' latest opinions, forking from its
+// own previous branch. The issue is confirmed only when all voters confirm and
+// the alignment check agrees they describe the same defect; a unanimous
+// rejection, or no consensus after maxVerificationRounds, leaves it
+// unresolved (存疑不报).
+func (r *Runner) confirmIssue(issueText string, startBranchID string, changeAnalysisPath string) (IssueReport, error) {
+	withVerdict := func(transcript Transcript, err error) (Transcript, error) {
+		if err != nil {
+			return Transcript{}, err
+		}
+		decision, err := r.determineVerdict(transcript)
+		if err != nil {
+			return Transcript{}, fmt.Errorf("%s round %d verdict: %w", transcript.Agent, transcript.Round, err)
+		}
+		transcript.Verdict = decision.Verdict
+		transcript.VerdictReason = decision.Reason
+		return transcript, nil
+	}
+
+	// The formal pipeline, when enabled, runs once alongside Round 1 and
+	// votes with its final outcome.
+	var formal *FormalVerification
+	runFormal := func() error {
+		formal = r.runFormalVerification(issueText, startBranchID)
+		return nil
+	}
+
+	if r.opts.SkipTester {
+		var reviewer Transcript
+		err := runParallel(func() (err error) {
+			reviewer, err = withVerdict(r.runRole("reviewer", issueText, changeAnalysisPath, startBranchID))
+			return err
+		}, runFormal)
+		if err != nil {
+			return IssueReport{}, err
+		}
+		report := IssueReport{
+			IssueText:              issueText,
+			Alpha:                  reviewer,
+			ReviewerRound1BranchID: reviewer.BranchID,
+			Formal:                 formal,
+		}
+		switch {
+		case reviewer.Verdict == "confirmed" && withFormalVote(reviewer.Verdict, formal) == "":
+			report.Status = commentUnresolved
+			report.VerdictExplanation = "SkipTester enabled: Reviewer confirmed the issue but formal verification refuted it."
+		case reviewer.Verdict == "confirmed":
+			report.Status = commentConfirmed
+			report.VerdictExplanation = "SkipTester enabled: Reviewer confirmed the issue."
+		case reviewer.Verdict == "rejected":
+			report.Status = commentUnresolved
+			report.VerdictExplanation = "SkipTester enabled: Reviewer rejected the issue."
+		default:
+			report.Status = commentUnresolved
+			report.VerdictExplanation = fmt.Sprintf("SkipTester enabled: Reviewer verdict undetermined (%s).", strings.TrimSpace(reviewer.VerdictReason))
+		}
+		report.VerdictExplanation += formalNote(formal)
+		return report, nil
+	}
+
+	// Round 1: independent review (parallel + double-blind fork).
+	var reviewer, verifier Transcript
+	specialists := make([]Transcript, len(r.specialists))
+	round1 := []func() error{
+		func() (err error) {
+			reviewer, err = withVerdict(r.runRole("reviewer", issueText, changeAnalysisPath, startBranchID))
+			return err
+		},
+		func() (err error) {
+			verifier, err = withVerdict(r.runVerifyAgentReview(issueText, changeAnalysisPath, startBranchID, ""))
+			return err
+		},
+		runFormal,
+	}
+	for i, spec := range r.specialists {
+		i, spec := i, spec
+		round1 = append(round1, func() (err error) {
+			specialists[i], err = withVerdict(r.runSpecialist(spec, issueText, changeAnalysisPath, startBranchID))
+			return err
+		})
+	}
+	if err := runParallel(round1...); err != nil {
+		return IssueReport{}, err
+	}
+
+	report := IssueReport{IssueText: issueText, Formal: formal}
+	explanation := ""
+	for round := 1; ; round++ {
+		report.Alpha = reviewer
+		report.Beta = verifier
+		report.ExchangeRounds = round - 1
+		if len(specialists) > 0 {
+			report.Specialists = append([]Transcript(nil), specialists...)
+		}
+		switch round {
+		case 1:
+			report.ReviewerRound1BranchID, report.VerifyAgentRound1BranchID = reviewer.BranchID, verifier.BranchID
+		case 2:
+			report.ReviewerRound2BranchID, report.VerifyAgentRound2BranchID = reviewer.BranchID, verifier.BranchID
+		case 3:
+			report.ReviewerRound3BranchID, report.VerifyAgentRound3BranchID = reviewer.BranchID, verifier.BranchID
+		}
+
+		voters := append([]Transcript{reviewer, verifier}, specialists...)
+		unanimous := unanimousVerdict(voters)
+		switch withFormalVote(unanimous, formal) {
+		case "rejected":
+			report.Status = commentUnresolved
+			if len(specialists) == 0 {
+				report.VerdictExplanation = fmt.Sprintf("Round %d: Both Reviewer and VerifyAgent rejected the issue", round)
+			} else {
+				report.VerdictExplanation = fmt.Sprintf("Round %d: Reviewer, VerifyAgent and all specialists rejected the issue", round)
+			}
+			report.VerdictExplanation += formalNote(formal)
+			return report, nil
+		case "confirmed":
+			// Every voter must describe the same defect as the reviewer.
+			aligned := alignmentVerdict{Agree: true}
+			for _, peer := range voters[1:] {
+				verdict, err := r.checkAlignment(issueText, reviewer, peer)
+				if err != nil {
+					return IssueReport{}, err
+				}
+				if !verdict.Agree {
+					aligned = verdict
+					break
+				}
+				aligned.Explanation = verdict.Explanation
+			}
+			if aligned.Agree {
+				report.Status = commentConfirmed
+				if len(specialists) == 0 {
+					report.VerdictExplanation = fmt.Sprintf("Round %d: Both confirmed and aligned: %s", round, strings.TrimSpace(aligned.Explanation))
+				} else {
+					report.VerdictExplanation = fmt.Sprintf("Round %d: All %d voters confirmed and aligned: %s", round, len(voters), strings.TrimSpace(aligned.Explanation))
+				}
+				report.VerdictExplanation += formalNote(formal)
+				return report, nil
+			}
+			// All confirmed, but not the same defect: keep exchanging.
+			explanation = fmt.Sprintf("confirmed but misaligned: %s", strings.TrimSpace(aligned.Explanation))
+		default:
+			// The formal outcome never changes, so exchanging more rounds
+			// cannot settle a unanimous vote it contradicts.
+			if unanimous != "" {
+				report.Status = commentUnresolved
+				report.VerdictExplanation = fmt.Sprintf("Round %d: the formal verdict contradicts the voters, who %s the issue (存疑不报).", round, unanimous)
+				report.VerdictExplanation += formalNote(formal)
+				return report, nil
+			}
+			explanation = "no unanimous confirmation"
+		}
+		if round == maxVerificationRounds {
+			break
+		}
+
+		// Exchange: the reviewer answers the other voters' latest opinions,
+		// then the verify agent answers the reviewer's revised one, then the
+		// specialists answer both.
+		// The formal outcome is shown to every voter as one more peer.
+		next := round + 1
+		evidence := formalPeers(formal)
+		others := append(append([]Transcript(nil), specialists...), evidence...)
+		revised, err := withVerdict(r.runExchange("reviewer", next, issueText, changeAnalysisPath, reviewer.Text, peerOpinions(verifier, others...), reviewer.BranchID))
+		if err != nil {
+			return IssueReport{}, err
+		}
+		revisedVerifier, err := withVerdict(r.runExchange("verify_agent", next, issueText, changeAnalysisPath, verifier.Text, peerOpinions(revised, others...), verifier.BranchID))
+		if err != nil {
+			return IssueReport{}, err
+		}
+		revisedSpecialists := make([]Transcript, len(specialists))
+		exchanges := make([]func() error, 0, len(specialists))
+		for i, self := range specialists {
+			i, self := i, self
+			exchanges = append(exchanges, func() (err error) {
+				peers := []Transcript{revisedVerifier}
+				for j, other := range specialists {
+					if j != i {
+						peers = append(peers, other)
+					}
+				}
+				peers = append(peers, evidence...)
+				revisedSpecialists[i], err = withVerdict(r.runSpecialistExchange(r.specialists[i], next, issueText, changeAnalysisPath, self.Text, peerOpinions(revised, peers...), self.BranchID))
+				return err
+			})
+		}
+		if err := runParallel(exchanges...); err != nil {
+			return IssueReport{}, err
+		}
+		reviewer, verifier, specialists = revised, revisedVerifier, revisedSpecialists
+	}
+
+	report.Status = commentUnresolved
+	report.VerdictExplanation = fmt.Sprintf("Round %d: %s (存疑不报)", maxVerificationRounds, explanation)
+	return report, nil
+}
+
+// unanimousVerdict returns "confirmed" or "rejected" when every voter agrees,
+// and "" otherwise.
+func unanimousVerdict(voters []Transcript) string {
+	verdict := voters[0].Verdict
+	for _, v := range voters[1:] {
+		if v.Verdict != verdict {
+			return ""
+		}
+	}
+	if verdict != "confirmed" && verdict != "rejected" {
+		return ""
+	}
+	return verdict
+}
+
+// peerOpinions formats the opinions shown to a voter in an exchange round. A
+// single peer is passed through unchanged; several peers are labelled by agent.
+func peerOpinions(first Transcript, rest ...Transcript) string {
+	if len(rest) == 0 {
+		return first.Text
+	}
+	var sb strings.Builder
+	for i, peer := range append([]Transcript{first}, rest...) {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(fmt.Sprintf("### %s\n%s", peer.Agent, peer.Text))
+	}
+	return sb.String()
+}
+
+// runParallel runs fns concurrently and returns the first error in fns order.
+func runParallel(fns ...func() error) error {
+	errs := make([]error, len(fns))
+	var wg sync.WaitGroup
+	for i, fn := range fns {
+		wg.Add(1)
+		go func(i int, fn func() error) {
+			defer wg.Done()
+			errs[i] = fn()
+		}(i, fn)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // runVerifyAgentReview runs an adversarial review using the same review mechanism
 func (r *Runner) runVerifyAgentReview(issueText string, changeAnalysisPath string, parentBranchID string, reviewerOpinion string) (Transcript, error) {
-	prompt := buildVerifyAgentPrompt(r.opts.Task, issueText, changeAnalysisPath, reviewerOpinion)
+	prompt := r.withSkills("verify_agent", buildVerifyAgentPrompt(r.opts.Task, issueText, changeAnalysisPath, reviewerOpinion))
 
 	agent := "codex"
 	data, err := r.executeAgent(agent, prompt, parentBranchID)
@@ -430,6 +876,22 @@ func (r *Runner) runVerifyAgentReview(issueText string, changeAnalysisPath strin
 	}, nil
 }
 
+// runSpecialist runs a domain specialist's Round 1 review.
+func (r *Runner) runSpecialist(spec Specialist, issueText string, changeAnalysisPath string, parentBranchID string) (Transcript, error) {
+	prompt := buildSpecialistPrompt(spec, r.opts.Task, issueText, changeAnalysisPath)
+
+	data, err := r.executeAgent("codex", prompt, parentBranchID)
+	if err != nil {
+		return Transcript{}, err
+	}
+	return Transcript{
+		Agent:    spec.Name,
+		Round:    1,
+		BranchID: stringField(data, "branch_id"),
+		Text:     strings.TrimSpace(stringField(data, "response")),
+	}, nil
+}
+
 // runRole executes a role-based verification (Reviewer or Tester).
 func (r *Runner) runRole(role string, issueText string, changeAnalysisPath string, parentBranchID string) (Transcript, error) {
 	var prompt string
@@ -452,23 +914,38 @@ func (r *Runner) runRole(role string, issueText string, changeAnalysisPath strin
 	}, nil
 }
 
-// runExchange executes Round 2 with both the agent's and peer's opinions.
-func (r *Runner) runExchange(role string, issueText string, changeAnalysisPath string, selfOpinion string, peerOpinion string, parentBranchID string) (Transcript, error) {
+// runExchange executes an exchange round (2+) with both the agent's and peer's opinions.
+func (r *Runner) runExchange(role string, round int, issueText string, changeAnalysisPath string, selfOpinion string, peerOpinion string, parentBranchID string) (Transcript, error) {
 	prompt := buildExchangePrompt(role, r.opts.Task, issueText, changeAnalysisPath, selfOpinion, peerOpinion)
+	return r.runExchangePrompt(role, round, prompt, parentBranchID)
+}
 
+// runSpecialistExchange executes a domain specialist's exchange round (2+),
+// keeping its title and checklist in the prompt.
+func (r *Runner) runSpecialistExchange(spec Specialist, round int, issueText string, changeAnalysisPath string, selfOpinion string, peerOpinion string, parentBranchID string) (Transcript, error) {
+	prompt := buildSpecialistExchangePrompt(spec, r.opts.Task, issueText, changeAnalysisPath, selfOpinion, peerOpinion)
+	return r.runExchangePrompt(spec.Name, round, prompt, parentBranchID)
+}
+
+func (r *Runner) runExchangePrompt(agentName string, round int, prompt string, parentBranchID string) (Transcript, error) {
 	agent := "codex"
 	data, err := r.executeAgent(agent, prompt, parentBranchID)
 	if err != nil {
 		return Transcript{}, err
 	}
 	return Transcript{
-		Agent:    role,
-		Round:    2,
+		Agent:    agentName,
+		Round:    round,
 		BranchID: stringField(data, "branch_id"),
 		Text:     strings.TrimSpace(stringField(data, "response")),
 	}, nil
 }
 
+// withSkills injects the enabled skills phase declares into prompt.
+func (r *Runner) withSkills(phase, prompt string) string {
+	return r.opts.Skills.Apply(phase, phaseSkills[phase], prompt)
+}
+
 func (r *Runner) executeAgent(agent, prompt, parentBranchID string) (map[string]any, error) {
 	args := map[string]any{
 		"agent":            agent,
@@ -518,16 +995,16 @@ func (r *Runner) callTool(name string, args map[string]any) (map[string]any, err
 
 const changeAnalysisFilename = "change_analysis.md"
 
-func (r *Runner) runScout(parentBranchID string) (string, string, error) {
+func (r *Runner) runScout(parentBranchID string) (string, string, string, error) {
 	if strings.TrimSpace(r.opts.WorkspaceDir) == "" {
-		return "", "", errors.New("workspace dir is required for scout output")
+		return "", "", "", errors.New("workspace dir is required for scout output")
 	}
 	analysisPath := filepath.Join(r.opts.WorkspaceDir, changeAnalysisFilename)
 	prompt := buildScoutPrompt(r.opts.Task, analysisPath)
 
 	resp, err := r.executeAgent("codex", prompt, parentBranchID)
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 	branchID := stringField(resp, "branch_id")
 	artifact, err := r.callTool("read_artifact", map[string]any{
@@ -535,13 +1012,13 @@ func (r *Runner) runScout(parentBranchID string) (string, string, error) {
 		"path":      analysisPath,
 	})
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 	content := stringField(artifact, "content")
 	if strings.TrimSpace(content) == "" {
-		return "", "", fmt.Errorf("scout wrote empty analysis file: %s", analysisPath)
+		return "", "", "", fmt.Errorf("scout wrote empty analysis file: %s", analysisPath)
 	}
-	return branchID, analysisPath, nil
+	return branchID, analysisPath, content, nil
 }
 
 func (r *Runner) hasRealIssue(reportText string) (bool, error) {
@@ -718,9 +1195,43 @@ func summarizeIssueCounts(reports []IssueReport) (confirmed, unresolved int) {
 	return
 }
 
+// findIssues turns a review report into the issues to verify. An empty
+// result means the report has no blocking P0/P1 issue.
+func (r *Runner) findIssues(reportText string) ([]parsedIssue, error) {
+	if strings.TrimSpace(reportText) == "" {
+		return nil, nil
+	}
+	// Check if the report actually describes a real issue
+	hasIssue, err := r.hasRealIssue(reportText)
+	if err != nil {
+		return nil, err
+	}
+	if !hasIssue {
+		return nil, nil
+	}
+	// Parse and split issues from the review report
+	issues, err := r.parseIssuesFromReport(reportText)
+	if err != nil {
+		logx.Warningf("Failed to parse issues from report, treating as single issue: %v", err)
+		issues = []parsedIssue{newParsedIssue(reportText, "", "", 0)}
+	}
+	return issues, nil
+}
+
 // parseIssuesFromReport parses the review report to extract individual issues.
 // It uses LLM to identify and separate distinct P0/P1 issues from the report text.
-func (r *Runner) parseIssuesFromReport(reportText string) ([]string, error) {
+func (r *Runner) parseIssuesFromReport(reportText string) ([]parsedIssue, error) {
+	if r.parseIssuesOverride != nil {
+		texts, err := r.parseIssuesOverride(reportText)
+		if err != nil {
+			return nil, err
+		}
+		issues := make([]parsedIssue, 0, len(texts))
+		for i, text := range texts {
+			issues = append(issues, newParsedIssue(text, "", "", i))
+		}
+		return issues, nil
+	}
 	// Use LLM to parse issues from the report
 	prompt := buildIssueParserPrompt(reportText)
 	resp, err := r.brain.Complete([]b.ChatMessage{
@@ -737,8 +1248,9 @@ func (r *Runner) parseIssuesFromReport(reportText string) ([]string, error) {
 
 	type issueList struct {
 		Issues []struct {
-			Text     string `json:"text"`
-			Priority string `json:"priority"` // P0, P1, etc.
+			Text       string `json:"text"`
+			Priority   string `json:"priority"`   // P0, P1, etc.
+			Confidence string `json:"confidence"` // high, medium or low
 		} `json:"issues"`
 	}
 
@@ -747,7 +1259,7 @@ func (r *Runner) parseIssuesFromReport(reportText string) ([]string, error) {
 	if err := json.Unmarshal([]byte(jsonBlock), &list); err != nil {
 		// Fallback: treat entire report as single issue
 		logx.Warningf("Failed to parse JSON from LLM response, treating entire report as single issue: %v", err)
-		return []string{reportText}, nil
+		return []parsedIssue{newParsedIssue(reportText, "", "", 0)}, nil
 	}
 
 	// If LLM explicitly returned empty array (e.g., "No P0/P1 issues found"), return empty
@@ -757,24 +1269,24 @@ func (r *Runner) parseIssuesFromReport(reportText string) ([]string, error) {
 		lowerReport := strings.ToLower(reportText)
 		if strings.Contains(lowerReport, "no p0/p1 issues found") ||
 			strings.Contains(lowerReport, "no p0/p1 issue") {
-			return []string{}, nil
+			return []parsedIssue{}, nil
 		}
 		// Otherwise, fallback to treating entire report as single issue
 		logx.Warningf("LLM returned empty issues array, treating entire report as single issue")
-		return []string{reportText}, nil
+		return []parsedIssue{newParsedIssue(reportText, "", "", 0)}, nil
 	}
 
-	issues := make([]string, 0, len(list.Issues))
+	issues := make([]parsedIssue, 0, len(list.Issues))
 	for _, issue := range list.Issues {
 		if strings.TrimSpace(issue.Text) != "" {
-			issues = append(issues, strings.TrimSpace(issue.Text))
+			issues = append(issues, newParsedIssue(issue.Text, issue.Priority, issue.Confidence, len(issues)))
 		}
 	}
 
 	if len(issues) == 0 {
 		// All issues had empty text, fallback to entire report
 		logx.Warningf("All parsed issues had empty text, treating entire report as single issue")
-		return []string{reportText}, nil
+		return []parsedIssue{newParsedIssue(reportText, "", "", 0)}, nil
 	}
 
 	return issues, nil
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	b "review_agent/internal/brain"
 	tools "review_agent/internal/tools"
@@ -13,15 +14,24 @@ import (
 type fakeAgentClient struct {
 	mu sync.Mutex
 
-	next int
-	byID map[string]string
+	next    int
+	byID    map[string]string
+	roundOf map[string]int
 
 	calls []agentCall
 
-	reviewerR1 string
-	testerR1   string
-	reviewerR2 string
-	testerR2   string
+	// outputs holds per-role responses indexed by round-1; the last entry is
+	// reused for later rounds.
+	outputs map[string][]string
+	// respond, when set, overrides outputs.
+	respond func(role string, round int, prompt string) string
+	// analysis is served as the scout's change_analysis.md.
+	analysis string
+
+	// inFlight counts agent runs whose output was not read yet; peak is its
+	// maximum. runTime keeps each run in flight for that long.
+	inFlight, peak int
+	runTime        time.Duration
 }
 
 type agentCall struct {
@@ -32,14 +42,15 @@ type agentCall struct {
 	classifiedRound int
 }
 
-func newFakeAgentClient(reviewerR1, testerR1, reviewerR2, testerR2 string) *fakeAgentClient {
+func newFakeAgentClient(reviewer, verifyAgent []string) *fakeAgentClient {
 	return &fakeAgentClient{
-		byID:       map[string]string{},
-		reviewerR1: reviewerR1,
-		testerR1:   testerR1,
-		reviewerR2: reviewerR2,
-		testerR2:   testerR2,
-		calls:      []agentCall{},
+		byID:    map[string]string{},
+		roundOf: map[string]int{},
+		outputs: map[string][]string{
+			"reviewer":     reviewer,
+			"verify_agent": verifyAgent,
+		},
+		calls: []agentCall{},
 	}
 }
 
@@ -48,15 +59,24 @@ func (c *fakeAgentClient) ParallelExplore(projectName, parentBranchID string, pr
 	if len(prompts) > 0 {
 		prompt = prompts[0]
 	}
-	role, round := classifyPrompt(prompt)
-	out := pickOutput(role, round, c.reviewerR1, c.testerR1, c.reviewerR2, c.testerR2)
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	// Exchange rounds fork from the agent's own previous branch, so the
+	// round is one past the parent's.
+	role := classifyPrompt(prompt)
+	round := c.roundOf[parentBranchID] + 1
+	out := c.output(role, round, prompt)
+
+	c.inFlight++
+	if c.inFlight > c.peak {
+		c.peak = c.inFlight
+	}
 	c.next++
 	branchID := fmt.Sprintf("branch_%d", c.next)
 	c.byID[branchID] = out
+	c.roundOf[branchID] = round
 	c.calls = append(c.calls, agentCall{
 		branchID:        branchID,
 		parentBranchID:  parentBranchID,
@@ -69,7 +89,22 @@ func (c *fakeAgentClient) ParallelExplore(projectName, parentBranchID string, pr
 	}, nil
 }
 
+func (c *fakeAgentClient) output(role string, round int, prompt string) string {
+	if c.respond != nil {
+		return c.respond(role, round, prompt)
+	}
+	outs := c.outputs[role]
+	if len(outs) == 0 {
+		return "# VERDICT: REJECTED\n\nClaim: unknown\nAnchor: unknown\n\n## Reasoning\nUnknown role."
+	}
+	if round > len(outs) {
+		round = len(outs)
+	}
+	return outs[round-1]
+}
+
 func (c *fakeAgentClient) GetBranch(branchID string) (map[string]any, error) {
+	time.Sleep(c.runTime)
 	return map[string]any{
 		"id":             branchID,
 		"status":         "succeed",
@@ -78,33 +113,53 @@ func (c *fakeAgentClient) GetBranch(branchID string) (map[string]any, error) {
 }
 
 func (c *fakeAgentClient) BranchReadFile(branchID string, filePath string) (map[string]any, error) {
+	if strings.HasSuffix(filePath, "code_review.log") {
+		return map[string]any{"content": "P0: example defects"}, nil
+	}
+	if strings.HasSuffix(filePath, changeAnalysisFilename) && c.analysis != "" {
+		return map[string]any{"content": c.analysis}, nil
+	}
 	return map[string]any{}, fmt.Errorf("not implemented")
 }
 
 func (c *fakeAgentClient) BranchOutput(branchID string, fullOutput bool) (map[string]any, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.inFlight--
 	return map[string]any{
 		"output": c.byID[branchID],
 	}, nil
 }
 
-func TestConfirmIssueDoesNotConfirmWhenTranscriptsMisaligned(t *testing.T) {
-	reviewer := "# VERDICT: CONFIRMED\n\nClaim: issueText describes defect A\nAnchor: alpha.go:10\n\n## Reasoning\nConfirmed Defect A."
-	tester := "# VERDICT: CONFIRMED\n\nClaim: issueText describes defect B\nAnchor: beta.go:20\n\n## Reproduction Steps\nConfirmed Defect B."
-	reviewerR2 := "# VERDICT: CONFIRMED\n\nClaim: defect A\nAnchor: alpha.go:10\n\n## Response to Peer\nStill A.\n\n## Final Reasoning\nStill A."
-	testerR2 := "# VERDICT: CONFIRMED\n\nClaim: defect B\nAnchor: beta.go:20\n\n## Response to Peer\nStill B.\n\n## Final Reasoning\nStill B."
-	client := newFakeAgentClient(reviewer, tester, reviewerR2, testerR2)
-	handler := tools.NewToolHandler(client, "proj", "start", "")
-	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
-		Task:           "task",
-		ProjectName:    "proj",
-		ParentBranchID: "start",
-		WorkspaceDir:   "",
-	})
+func (c *fakeAgentClient) snapshot() []agentCall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]agentCall(nil), c.calls...)
+}
+
+func newAlignmentTestRunner(t *testing.T, client *fakeAgentClient, opts Options) *Runner {
+	t.Helper()
+	opts.Task = "task"
+	opts.ProjectName = "proj"
+	opts.ParentBranchID = "start"
+	opts.WorkspaceDir = "/workspace"
+	handler := tools.NewToolHandler(client, "proj", "start", "/workspace")
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, opts)
 	if err != nil {
 		t.Fatalf("NewRunner error: %v", err)
 	}
+	return runner
+}
+
+const (
+	confirmedA = "# VERDICT: CONFIRMED\n\nClaim: issueText describes defect A\nAnchor: alpha.go:10\n\n## Reasoning\nConfirmed Defect A."
+	confirmedB = "# VERDICT: CONFIRMED\n\nClaim: issueText describes defect B\nAnchor: beta.go:20\n\n## Adversarial Analysis\nConfirmed Defect B."
+	rejected   = "# VERDICT: REJECTED\n\nClaim: something\nAnchor: unknown\n\n## Reasoning\nNo."
+)
+
+func TestConfirmIssueDoesNotConfirmWhenTranscriptsMisaligned(t *testing.T) {
+	client := newFakeAgentClient([]string{confirmedA}, []string{confirmedB})
+	runner := newAlignmentTestRunner(t, client, Options{})
 	runner.alignmentOverride = func(issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
 		return alignmentVerdict{Agree: false, Explanation: "test: misaligned"}, nil
 	}
@@ -113,25 +168,58 @@ func TestConfirmIssueDoesNotConfirmWhenTranscriptsMisaligned(t *testing.T) {
 	if err != nil {
 		t.Fatalf("confirmIssue error: %v", err)
 	}
-	if report.Status == commentConfirmed {
-		t.Fatalf("expected unresolved when reviewer/tester confirm different anchors; got status=%q explanation=%q", report.Status, report.VerdictExplanation)
+	if report.Status != commentUnresolved {
+		t.Fatalf("expected unresolved when reviewer/verify agent confirm different anchors; got status=%q explanation=%q", report.Status, report.VerdictExplanation)
+	}
+	if report.ExchangeRounds != maxVerificationRounds-1 {
+		t.Fatalf("expected %d exchange rounds, got %d", maxVerificationRounds-1, report.ExchangeRounds)
+	}
+	if !strings.Contains(report.VerdictExplanation, "存疑不报") {
+		t.Fatalf("expected unresolved explanation, got %q", report.VerdictExplanation)
+	}
+	if calls := client.snapshot(); len(calls) != 2*maxVerificationRounds {
+		t.Fatalf("expected %d agent calls, got %d", 2*maxVerificationRounds, len(calls))
 	}
 }
 
-func TestConfirmIssueSkipsTesterWhenFlagSet(t *testing.T) {
-	reviewer := "# VERDICT: CONFIRMED\n\nClaim: issueText describes defect A\nAnchor: alpha.go:10\n\n## Reasoning\nConfirmed Defect A."
-	client := newFakeAgentClient(reviewer, "", "", "")
-	handler := tools.NewToolHandler(client, "proj", "start", "")
-	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
-		Task:           "task",
-		ProjectName:    "proj",
-		ParentBranchID: "start",
-		WorkspaceDir:   "",
-		SkipTester:     true,
-	})
+func TestConfirmIssueConfirmsAfterExchangeReachesConsensus(t *testing.T) {
+	client := newFakeAgentClient([]string{confirmedA}, []string{rejected, confirmedA})
+	runner := newAlignmentTestRunner(t, client, Options{})
+	runner.alignmentOverride = func(issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
+		return alignmentVerdict{Agree: true, Explanation: "same defect"}, nil
+	}
+
+	report, err := runner.confirmIssue("ISSUE: example", "start", "")
 	if err != nil {
-		t.Fatalf("NewRunner error: %v", err)
+		t.Fatalf("confirmIssue error: %v", err)
+	}
+	if report.Status != commentConfirmed || report.ExchangeRounds != 1 {
+		t.Fatalf("expected confirmation after one exchange, got status=%q rounds=%d explanation=%q", report.Status, report.ExchangeRounds, report.VerdictExplanation)
+	}
+	if report.Alpha.Round != 2 || report.Beta.Round != 2 {
+		t.Fatalf("expected round 2 transcripts, got alpha=%d beta=%d", report.Alpha.Round, report.Beta.Round)
+	}
+}
+
+func TestConfirmIssueStopsWhenBothReject(t *testing.T) {
+	client := newFakeAgentClient([]string{rejected}, []string{rejected})
+	runner := newAlignmentTestRunner(t, client, Options{})
+
+	report, err := runner.confirmIssue("ISSUE: example", "start", "")
+	if err != nil {
+		t.Fatalf("confirmIssue error: %v", err)
+	}
+	if report.Status != commentUnresolved || report.ExchangeRounds != 0 {
+		t.Fatalf("expected unresolved without exchange, got status=%q rounds=%d", report.Status, report.ExchangeRounds)
+	}
+	if calls := client.snapshot(); len(calls) != 2 {
+		t.Fatalf("expected 2 agent calls, got %d", len(calls))
 	}
+}
+
+func TestConfirmIssueSkipsTesterWhenFlagSet(t *testing.T) {
+	client := newFakeAgentClient([]string{confirmedA}, nil)
+	runner := newAlignmentTestRunner(t, client, Options{SkipTester: true})
 
 	report, err := runner.confirmIssue("ISSUE: example", "start", "")
 	if err != nil {
@@ -143,14 +231,11 @@ func TestConfirmIssueSkipsTesterWhenFlagSet(t *testing.T) {
 	if report.ExchangeRounds != 0 {
 		t.Fatalf("expected 0 exchange rounds, got %d", report.ExchangeRounds)
 	}
-	if report.TesterRound1BranchID != "" || report.TesterRound2BranchID != "" {
-		t.Fatalf("expected no tester branch ids, got r1=%q r2=%q", report.TesterRound1BranchID, report.TesterRound2BranchID)
+	if report.VerifyAgentRound1BranchID != "" || report.VerifyAgentRound2BranchID != "" {
+		t.Fatalf("expected no verify agent branch ids, got r1=%q r2=%q", report.VerifyAgentRound1BranchID, report.VerifyAgentRound2BranchID)
 	}
 
-	client.mu.Lock()
-	calls := append([]agentCall(nil), client.calls...)
-	client.mu.Unlock()
-
+	calls := client.snapshot()
 	if len(calls) != 1 {
 		t.Fatalf("expected 1 agent call (reviewer only), got %d: %#v", len(calls), calls)
 	}
@@ -160,22 +245,8 @@ func TestConfirmIssueSkipsTesterWhenFlagSet(t *testing.T) {
 }
 
 func TestConfirmIssueUsesDoubleBlindBranchTopology(t *testing.T) {
-	reviewerR1 := "# VERDICT: REJECTED\n\nClaim: something\nAnchor: unknown\n\n## Reasoning\nNo."
-	testerR1 := "# VERDICT: CONFIRMED\n\nClaim: something\nAnchor: cmd\n\n## Reproduction Steps\nYes."
-	reviewerR2 := "# VERDICT: REJECTED\n\nClaim: something\nAnchor: unknown\n\n## Response to Peer\nStill no.\n\n## Final Reasoning\nStill no."
-	testerR2 := "# VERDICT: CONFIRMED\n\nClaim: something\nAnchor: cmd\n\n## Response to Peer\nStill yes.\n\n## Final Reasoning\nStill yes."
-
-	client := newFakeAgentClient(reviewerR1, testerR1, reviewerR2, testerR2)
-	handler := tools.NewToolHandler(client, "proj", "start", "")
-	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
-		Task:           "task",
-		ProjectName:    "proj",
-		ParentBranchID: "start",
-		WorkspaceDir:   "",
-	})
-	if err != nil {
-		t.Fatalf("NewRunner error: %v", err)
-	}
+	client := newFakeAgentClient([]string{rejected}, []string{confirmedB})
+	runner := newAlignmentTestRunner(t, client, Options{})
 
 	startBranchID := "discovery_branch"
 	report, err := runner.confirmIssue("ISSUE: example", startBranchID, "")
@@ -183,107 +254,238 @@ func TestConfirmIssueUsesDoubleBlindBranchTopology(t *testing.T) {
 		t.Fatalf("confirmIssue error: %v", err)
 	}
 
-	client.mu.Lock()
-	calls := append([]agentCall(nil), client.calls...)
-	client.mu.Unlock()
-
-	if len(calls) != 4 {
-		t.Fatalf("expected 4 agent calls (2x round1 + 2x round2), got %d: %#v", len(calls), calls)
+	calls := client.snapshot()
+	if len(calls) != 2*maxVerificationRounds {
+		t.Fatalf("expected %d agent calls (2 per round), got %d: %#v", 2*maxVerificationRounds, len(calls), calls)
 	}
 
-	var reviewerR1Branch, testerR1Branch string
+	// branches[role][round] is the branch produced by that role in that round.
+	branches := map[string]map[int]agentCall{"reviewer": {}, "verify_agent": {}}
 	for _, call := range calls {
-		if call.classifiedRound == 1 && call.classifiedRole == "reviewer" {
-			reviewerR1Branch = call.branchID
-			if call.parentBranchID != startBranchID {
-				t.Fatalf("reviewer round1 should fork from startBranchID=%q, got %q", startBranchID, call.parentBranchID)
-			}
+		if _, ok := branches[call.classifiedRole]; !ok {
+			t.Fatalf("unexpected role %q in call %#v", call.classifiedRole, call)
 		}
-		if call.classifiedRound == 1 && call.classifiedRole == "tester" {
-			testerR1Branch = call.branchID
-			if call.parentBranchID != startBranchID {
-				t.Fatalf("tester round1 should fork from startBranchID=%q, got %q", startBranchID, call.parentBranchID)
+		branches[call.classifiedRole][call.classifiedRound] = call
+	}
+	for role, byRound := range branches {
+		for round := 1; round <= maxVerificationRounds; round++ {
+			call, ok := byRound[round]
+			if !ok {
+				t.Fatalf("missing %s round %d branch. Calls: %#v", role, round, calls)
+			}
+			wantParent := startBranchID
+			if round > 1 {
+				wantParent = byRound[round-1].branchID
+			}
+			if call.parentBranchID != wantParent {
+				t.Fatalf("%s round %d should fork from %q, got %q", role, round, wantParent, call.parentBranchID)
 			}
 		}
 	}
-	if reviewerR1Branch == "" || testerR1Branch == "" {
-		t.Fatalf("missing round1 branches (reviewer=%q tester=%q). Calls: %#v", reviewerR1Branch, testerR1Branch, calls)
-	}
-	if report.ReviewerRound1BranchID != reviewerR1Branch {
-		t.Fatalf("expected report reviewer_round1_branch_id=%q, got %q", reviewerR1Branch, report.ReviewerRound1BranchID)
+
+	got := [][2]string{
+		{report.ReviewerRound1BranchID, branches["reviewer"][1].branchID},
+		{report.VerifyAgentRound1BranchID, branches["verify_agent"][1].branchID},
+		{report.ReviewerRound2BranchID, branches["reviewer"][2].branchID},
+		{report.VerifyAgentRound2BranchID, branches["verify_agent"][2].branchID},
+		{report.ReviewerRound3BranchID, branches["reviewer"][3].branchID},
+		{report.VerifyAgentRound3BranchID, branches["verify_agent"][3].branchID},
 	}
-	if report.TesterRound1BranchID != testerR1Branch {
-		t.Fatalf("expected report tester_round1_branch_id=%q, got %q", testerR1Branch, report.TesterRound1BranchID)
+	for i, pair := range got {
+		if pair[0] != pair[1] {
+			t.Fatalf("report branch id %d: expected %q, got %q", i, pair[1], pair[0])
+		}
 	}
+}
 
-	for _, call := range calls {
-		if call.classifiedRound == 2 && call.classifiedRole == "reviewer" {
-			if call.parentBranchID != reviewerR1Branch {
-				t.Fatalf("reviewer round2 should fork from its own round1 branch %q, got %q", reviewerR1Branch, call.parentBranchID)
-			}
-			if call.parentBranchID == testerR1Branch {
-				t.Fatalf("reviewer round2 must not fork from tester branch %q", testerR1Branch)
-			}
+func TestRunVerifiesEachIssueAndReportsOnlyConsensus(t *testing.T) {
+	client := newFakeAgentClient(nil, nil)
+	client.respond = func(role string, round int, prompt string) string {
+		if strings.Contains(prompt, "ISSUE: real") {
+			return confirmedA
 		}
-		if call.classifiedRound == 2 && call.classifiedRole == "tester" {
-			if call.parentBranchID != testerR1Branch {
-				t.Fatalf("tester round2 should fork from its own round1 branch %q, got %q", testerR1Branch, call.parentBranchID)
-			}
-			if call.parentBranchID == reviewerR1Branch {
-				t.Fatalf("tester round2 must not fork from reviewer branch %q", reviewerR1Branch)
-			}
+		return rejected
+	}
+	runner := newAlignmentTestRunner(t, client, Options{SkipScout: true, IssueParallelism: 3})
+	runner.hasRealIssueOverride = func(string) (bool, error) { return true, nil }
+	runner.parseIssuesOverride = func(string) ([]string, error) {
+		return []string{"ISSUE: real", "ISSUE: bogus 1", "ISSUE: bogus 2"}, nil
+	}
+	runner.alignmentOverride = func(issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
+		return alignmentVerdict{Agree: true, Explanation: "same defect"}, nil
+	}
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if result.Status != statusIssues {
+		t.Fatalf("expected status %q, got %q (%s)", statusIssues, result.Status, result.Summary)
+	}
+	if len(result.Issues) != 3 {
+		t.Fatalf("expected 3 issue reports, got %d", len(result.Issues))
+	}
+	for i, want := range []string{commentConfirmed, commentUnresolved, commentUnresolved} {
+		if result.Issues[i].Status != want {
+			t.Fatalf("issue %d: expected %q, got %q", i, want, result.Issues[i].Status)
 		}
 	}
+	if !strings.Contains(result.Summary, "1 confirmed, 2 unresolved") {
+		t.Fatalf("unexpected summary %q", result.Summary)
+	}
+}
+
+func TestRunReportsCleanWhenNoIssueIsConfirmed(t *testing.T) {
+	client := newFakeAgentClient([]string{rejected}, []string{rejected})
+	runner := newAlignmentTestRunner(t, client, Options{SkipScout: true})
+	runner.hasRealIssueOverride = func(string) (bool, error) { return true, nil }
+	runner.parseIssuesOverride = func(string) ([]string, error) {
+		return []string{"ISSUE: bogus"}, nil
+	}
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if result.Status != statusClean {
+		t.Fatalf("expected status %q, got %q (%s)", statusClean, result.Status, result.Summary)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Status != commentUnresolved {
+		t.Fatalf("expected the rejected issue to stay unresolved, got %#v", result.Issues)
+	}
+}
+
+func TestRunAttachesStatisticsToCleanReview(t *testing.T) {
+	client := newFakeAgentClient(nil, nil)
+	client.analysis = "# CHANGE ANALYSIS\n## High-Risk Areas\n- none\n"
+	runner := newAlignmentTestRunner(t, client, Options{})
+	runner.hasRealIssueOverride = func(string) (bool, error) { return false, nil }
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if result.Status != statusClean {
+		t.Fatalf("expected status %q, got %q (%s)", statusClean, result.Status, result.Summary)
+	}
+	if result.ReviewStatistics == nil {
+		t.Fatalf("a clean review must still report its statistics")
+	}
+	var steps []string
+	for _, step := range result.ReviewStatistics.StepTimings {
+		steps = append(steps, step.StepName)
+	}
+	if strings.Join(steps, ",") != "scout,review" {
+		t.Fatalf("expected scout and review step timings, got %v", steps)
+	}
+}
+
+func TestConfirmIssueRequiresSpecialistConsensus(t *testing.T) {
+	client := newFakeAgentClient([]string{confirmedA}, []string{confirmedA})
+	client.outputs["specialist"] = []string{rejected, rejected, confirmedA}
+	runner := newAlignmentTestRunner(t, client, Options{})
+	runner.specialists = []Specialist{specialistCatalog[0]}
+	alignmentChecks := 0
+	runner.alignmentOverride = func(issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
+		alignmentChecks++
+		return alignmentVerdict{Agree: true, Explanation: "same defect"}, nil
+	}
+
+	report, err := runner.confirmIssue("ISSUE: example", "start", "")
+	if err != nil {
+		t.Fatalf("confirmIssue error: %v", err)
+	}
+	if report.Status != commentConfirmed || report.ExchangeRounds != 2 {
+		t.Fatalf("expected confirmation once the specialist agrees in round 3, got status=%q rounds=%d explanation=%q", report.Status, report.ExchangeRounds, report.VerdictExplanation)
+	}
+	if alignmentChecks != 2 {
+		t.Fatalf("expected the reviewer to be aligned with both voters, got %d checks", alignmentChecks)
+	}
+	if len(report.Specialists) != 1 || report.Specialists[0].Agent != "concurrency_specialist" || report.Specialists[0].Round != 3 {
+		t.Fatalf("unexpected specialist transcripts %#v", report.Specialists)
+	}
 
-	reviewerR2Branch := ""
-	testerR2Branch := ""
+	calls := client.snapshot()
+	if len(calls) != 9 {
+		t.Fatalf("expected 3 voters x 3 rounds, got %d calls", len(calls))
+	}
 	for _, call := range calls {
-		if call.classifiedRound == 2 && call.classifiedRole == "reviewer" {
-			reviewerR2Branch = call.branchID
-		}
-		if call.classifiedRound == 2 && call.classifiedRole == "tester" {
-			testerR2Branch = call.branchID
+		if call.classifiedRole == "reviewer" && call.classifiedRound > 1 && !strings.Contains(call.prompt, "### concurrency_specialist") {
+			t.Fatalf("reviewer exchange must see the specialist's opinion: %q", call.prompt)
 		}
 	}
-	if reviewerR2Branch == "" || testerR2Branch == "" {
-		t.Fatalf("missing round2 branches (reviewer=%q tester=%q). Calls: %#v", reviewerR2Branch, testerR2Branch, calls)
+}
+
+func TestSpecialistExchangeKeepsTitleAndFocus(t *testing.T) {
+	client := newFakeAgentClient([]string{confirmedA}, []string{confirmedA})
+	client.outputs["specialist"] = []string{rejected, confirmedA}
+	runner := newAlignmentTestRunner(t, client, Options{})
+	spec := specialistCatalog[0]
+	runner.specialists = []Specialist{spec}
+	runner.alignmentOverride = func(issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
+		return alignmentVerdict{Agree: true, Explanation: "same defect"}, nil
+	}
+
+	if _, err := runner.confirmIssue("ISSUE: example", "start", ""); err != nil {
+		t.Fatalf("confirmIssue error: %v", err)
 	}
-	if report.ReviewerRound2BranchID != reviewerR2Branch {
-		t.Fatalf("expected report reviewer_round2_branch_id=%q, got %q", reviewerR2Branch, report.ReviewerRound2BranchID)
+	exchanges := 0
+	for _, call := range client.snapshot() {
+		if call.classifiedRole != "specialist" || call.classifiedRound != 2 {
+			continue
+		}
+		exchanges++
+		for _, needle := range append([]string{"You remain the " + spec.Title}, spec.Focus...) {
+			if !strings.Contains(call.prompt, needle) {
+				t.Fatalf("specialist round-2 prompt missing %q: %q", needle, call.prompt)
+			}
+		}
 	}
-	if report.TesterRound2BranchID != testerR2Branch {
-		t.Fatalf("expected report tester_round2_branch_id=%q, got %q", testerR2Branch, report.TesterRound2BranchID)
+	if exchanges != 1 {
+		t.Fatalf("expected one specialist exchange, got %d", exchanges)
 	}
 }
 
-func classifyPrompt(prompt string) (role string, round int) {
-	prompt = strings.TrimSpace(prompt)
-	if strings.Contains(prompt, "Round 2 - Exchange") {
-		round = 2
-	} else {
-		round = 1
+func TestRunAddsSpecialistsFromChangeAnalysis(t *testing.T) {
+	client := newFakeAgentClient([]string{confirmedA}, []string{confirmedA})
+	client.outputs["specialist"] = []string{rejected}
+	client.analysis = "# CHANGE ANALYSIS\n## High-Risk Areas\n- pkg/cache.go:40 now guards the map with a sync.Mutex\n"
+	runner := newAlignmentTestRunner(t, client, Options{})
+	runner.hasRealIssueOverride = func(string) (bool, error) { return true, nil }
+	runner.parseIssuesOverride = func(string) ([]string, error) {
+		return []string{"ISSUE: example"}, nil
 	}
-	switch {
-	case strings.Contains(prompt, "Verification Role: REVIEWER"):
-		return "reviewer", round
-	case strings.Contains(prompt, "Verification Role: TESTER"):
-		return "tester", round
-	default:
-		return "unknown", round
+	runner.alignmentOverride = func(issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
+		return alignmentVerdict{Agree: true, Explanation: "same defect"}, nil
+	}
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if got := result.Specialists["concurrency_specialist"]; got != "keyword sync.Mutex" {
+		t.Fatalf("expected the concurrency specialist to be triggered by sync.Mutex, got %#v", result.Specialists)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Status != commentUnresolved {
+		t.Fatalf("a rejecting specialist must block confirmation, got %#v", result.Issues)
+	}
+	if result.Status != statusClean {
+		t.Fatalf("expected status %q, got %q", statusClean, result.Status)
 	}
 }
 
-func pickOutput(role string, round int, reviewerR1, testerR1, reviewerR2, testerR2 string) string {
+func classifyPrompt(prompt string) string {
 	switch {
-	case role == "reviewer" && round == 1:
-		return reviewerR1
-	case role == "tester" && round == 1:
-		return testerR1
-	case role == "reviewer" && round == 2:
-		return reviewerR2
-	case role == "tester" && round == 2:
-		return testerR2
+	case strings.Contains(prompt, "Verification Role: REVIEWER"):
+		return "reviewer"
+	case strings.Contains(prompt, "Verification Role: VERIFY_AGENT"):
+		return "verify_agent"
+	case strings.Contains(prompt, "Verification Role: RESOLUTION CHECK"):
+		return "resolution"
+	case strings.Contains(prompt, "Verification Role: SPECIALIST"),
+		strings.Contains(prompt, "_SPECIALIST (Round 2+ - Exchange)"):
+		return "specialist"
 	default:
-		return "# VERDICT: REJECTED\n\nClaim: unknown\nAnchor: unknown\n\n## Reasoning\nUnknown role."
+		return "unknown"
 	}
 }
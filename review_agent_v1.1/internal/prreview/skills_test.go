@@ -0,0 +1,40 @@
+package prreview
+
+import (
+	"strings"
+	"testing"
+
+	"review_agent/internal/skills"
+)
+
+func TestRunInjectsDeclaredSkillsAndRecordsThem(t *testing.T) {
+	set, err := skills.Select([]skills.Skill{{Name: "re2", Body: "Enumerate the invariants first.", Version: "v1"}}, []string{"re2"})
+	if err != nil {
+		t.Fatalf("Select error: %v", err)
+	}
+	client := newFakeAgentClient([]string{confirmedA}, []string{rejected})
+	runner := newAlignmentTestRunner(t, client, Options{SkipScout: true, Skills: set})
+	runner.hasRealIssueOverride = func(string) (bool, error) { return true, nil }
+	runner.parseIssuesOverride = func(string) ([]string, error) { return []string{"P1: stale read in cache/lru.go:10"}, nil }
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	for _, call := range client.snapshot() {
+		injected := strings.HasPrefix(call.prompt, "# Skill: re2")
+		switch {
+		case call.classifiedRole == "verify_agent" && call.classifiedRound == 1, call.classifiedRole == "unknown" && strings.Contains(call.prompt, "code_review.log"):
+			if !injected {
+				t.Fatalf("%s prompt must carry the re2 skill:\n%.300s", call.classifiedRole, call.prompt)
+			}
+		case call.classifiedRole == "reviewer":
+			if injected {
+				t.Fatalf("the reviewer phase declares no skills")
+			}
+		}
+	}
+	if len(result.Skills) != 1 || result.Skills[0].Name != "re2" || strings.Join(result.Skills[0].Phases, ",") != "review,verify_agent" {
+		t.Fatalf("expected re2 recorded for the review and verify_agent phases, got %+v", result.Skills)
+	}
+}
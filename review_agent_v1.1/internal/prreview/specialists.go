@@ -0,0 +1,134 @@
+package prreview
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Specialist is a domain reviewer that joins the consensus vote when the
+// scout's change analysis touches its domain.
+type Specialist struct {
+	// Name is the transcript agent name, e.g. "concurrency_specialist".
+	Name  string
+	Title string
+	// Focus is the domain checklist rendered into specialist.tmpl.
+	Focus []string
+	// Keywords trigger the specialist when they appear in change_analysis.md
+	// (case-insensitive, whole words).
+	Keywords []string
+	// Paths trigger the specialist when a file path named in
+	// change_analysis.md contains one of these fragments.
+	Paths []string
+}
+
+// specialistCatalog lists the optional specialists from pr_review_design_v2.md.
+// Keywords name code constructs rather than generic topics, because the scout
+// template always has concurrency and security headings.
+var specialistCatalog = []Specialist{
+	{
+		Name:  "concurrency_specialist",
+		Title: "Concurrency Specialist",
+		Focus: []string{
+			"Goroutine, thread and task lifecycles: leaks, missing joins, unbounded spawning",
+			"Channel usage: sends on closed channels, blocking without a reader, missing close",
+			"Lock ordering, lock scope and deadlocks; mutexes copied by value",
+			"Data races on shared state, check-then-act (TOCTOU) windows, atomics mixed with plain access",
+		},
+		Keywords: []string{
+			"goroutine", "goroutines", "chan", "sync.Mutex", "sync.RWMutex", "sync.WaitGroup", "sync.Once",
+			"sync/atomic", "errgroup", "deadlock", "data race",
+			"tokio::spawn", "std::thread", "Arc<Mutex", "RwLock", "Condvar", "crossbeam",
+		},
+		Paths: []string{"/sync/", "/concurrency/", "/scheduler/", "/worker"},
+	},
+	{
+		Name:  "security_specialist",
+		Title: "Security Reviewer",
+		Focus: []string{
+			"Authentication and authorization checks: missing, bypassable or applied in the wrong order",
+			"Cryptography: weak algorithms, hard-coded keys or IVs, non-constant-time comparisons, bad randomness",
+			"Secrets and tokens: logging, leaking through errors, insecure storage",
+			"Untrusted input reaching queries, shell commands, file paths or deserializers",
+		},
+		Keywords: []string{
+			"jwt", "oauth", "oidc", "bcrypt", "scrypt", "argon2", "hmac", "x509", "tls.Config",
+			"crypto/rand", "crypto/tls", "password", "credential", "credentials", "csrf", "rbac", "privilege",
+		},
+		Paths: []string{"auth/", "/auth", "crypto/", "security/", "/tls", "/session", "/acl", "/rbac", "/privilege"},
+	},
+	{
+		Name:  "database_specialist",
+		Title: "Database Expert",
+		Focus: []string{
+			"Schema migrations: backward compatibility, locking on large tables, reversibility",
+			"Query correctness: NULL handling, joins, pagination, index usage",
+			"Transactions: isolation level, missing commit/rollback, partial writes",
+			"Data compatibility between old and new code during rolling upgrades",
+		},
+		Keywords: []string{
+			"ALTER TABLE", "CREATE TABLE", "DROP TABLE", "CREATE INDEX", "DROP INDEX", "ADD COLUMN",
+			"schema migration", "database/sql", "gorm", "sqlx", "diesel", "sqlalchemy",
+			"BEGIN TRANSACTION", "isolation level", "foreign key",
+		},
+		Paths: []string{"migrations/", "migrate/", "schema/", ".sql", "/ddl/", "/store/", "/dao/"},
+	},
+}
+
+var analysisPathRe = regexp.MustCompile(`[\w.-]*/[\w./-]+|[\w-]+\.sql\b`)
+
+// selectSpecialists returns the catalog specialists triggered by the change
+// analysis, in catalog order, together with the trigger that selected each.
+func selectSpecialists(analysis string) ([]Specialist, map[string]string) {
+	triggers := map[string]string{}
+	if strings.TrimSpace(analysis) == "" {
+		return nil, triggers
+	}
+	lowered := strings.ToLower(analysis)
+	paths := analysisPathRe.FindAllString(lowered, -1)
+
+	var selected []Specialist
+	for _, spec := range specialistCatalog {
+		if trigger, ok := matchSpecialist(spec, lowered, paths); ok {
+			selected = append(selected, spec)
+			triggers[spec.Name] = trigger
+		}
+	}
+	return selected, triggers
+}
+
+func matchSpecialist(spec Specialist, lowered string, paths []string) (string, bool) {
+	for _, keyword := range spec.Keywords {
+		if containsWord(lowered, strings.ToLower(keyword)) {
+			return "keyword " + keyword, true
+		}
+	}
+	for _, fragment := range spec.Paths {
+		for _, p := range paths {
+			if strings.Contains(p, fragment) {
+				return "path " + p, true
+			}
+		}
+	}
+	return "", false
+}
+
+// containsWord reports whether word occurs in text without being part of a
+// longer identifier.
+func containsWord(text, word string) bool {
+	for start := 0; ; {
+		i := strings.Index(text[start:], word)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(word)
+		if (i == 0 || !isWordByte(text[i-1])) && (end == len(text) || !isWordByte(text[end])) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
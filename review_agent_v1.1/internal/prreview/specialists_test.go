@@ -0,0 +1,73 @@
+package prreview
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelectSpecialistsMatchesKeywordsAndPaths(t *testing.T) {
+	cases := []struct {
+		name     string
+		analysis string
+		want     []string
+	}{
+		{
+			name:     "concurrency keyword",
+			analysis: "Workers now fan out with a sync.WaitGroup and report on a results chan.",
+			want:     []string{"concurrency_specialist"},
+		},
+		{
+			name:     "security path",
+			analysis: "## Complete File Inventory\n- internal/auth/token.go: refresh flow",
+			want:     []string{"security_specialist"},
+		},
+		{
+			name:     "database migration file plus jwt",
+			analysis: "Adds db/migrations/0042_add_index.sql and validates the JWT audience.",
+			want:     []string{"security_specialist", "database_specialist"},
+		},
+		{
+			name:     "scout headings alone do not trigger",
+			analysis: "## Concurrency & Synchronization Analysis\nNone.\n## Error Handling & Edge Cases\n- Potential race conditions: none",
+			want:     nil,
+		},
+		{
+			name:     "keywords inside identifiers do not trigger",
+			analysis: "Renamed channelName to topicName in internal/pubsub/topic.go",
+			want:     nil,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			selected, triggers := selectSpecialists(tc.analysis)
+			var names []string
+			for _, spec := range selected {
+				names = append(names, spec.Name)
+				if triggers[spec.Name] == "" {
+					t.Fatalf("missing trigger for %s", spec.Name)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tc.want, ",") {
+				t.Fatalf("selected %v, want %v (triggers %v)", names, tc.want, triggers)
+			}
+		})
+	}
+}
+
+func TestBuildSpecialistPromptRendersChecklist(t *testing.T) {
+	spec := specialistCatalog[1]
+	prompt := buildSpecialistPrompt(spec, "task", "ISSUE: token leak", "/workspace/change_analysis.md")
+	required := []string{
+		"Verification Role: SPECIALIST (Security Reviewer)",
+		"ISSUE: token leak",
+		"Change Analysis at: /workspace/change_analysis.md",
+		"- " + spec.Focus[0],
+		"# VERDICT: [CONFIRMED | REJECTED]",
+		"Anchor:",
+	}
+	for _, needle := range required {
+		if !strings.Contains(prompt, needle) {
+			t.Fatalf("specialist prompt missing %q", needle)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package prreview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"review_agent/internal/logx"
+)
+
+// ReviewState is what an incremental review needs from the previous run: the
+// head commit it reviewed and the issues it confirmed.
+type ReviewState struct {
+	HeadSHA    string        `json:"head_sha"`
+	ReviewedAt string        `json:"reviewed_at"`
+	Issues     []IssueReport `json:"issues"`
+}
+
+// NewReviewState records the confirmed issues of result as reviewed at headSHA.
+func NewReviewState(headSHA string, result *Result) ReviewState {
+	state := ReviewState{
+		HeadSHA:    strings.TrimSpace(headSHA),
+		ReviewedAt: time.Now().UTC().Format(time.RFC3339),
+		Issues:     []IssueReport{},
+	}
+	if result == nil {
+		return state
+	}
+	for _, issue := range result.Issues {
+		if issue.Status == commentConfirmed {
+			state.Issues = append(state.Issues, issue)
+		}
+	}
+	return state
+}
+
+// LoadReviewState reads a state file written by SaveReviewState. A missing
+// file returns nil so the first run reviews the full diff.
+func LoadReviewState(path string) (*ReviewState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read review state: %w", err)
+	}
+	var state ReviewState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse review state %s: %w", filepath.Base(path), err)
+	}
+	return &state, nil
+}
+
+// SaveReviewState writes state to path, replacing it atomically.
+func SaveReviewState(path string, state ReviewState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("write review state: %w", err)
+	}
+	return nil
+}
+
+// incrementalBase returns the previously reviewed head SHA when this run
+// should only review the commits after it.
+func (r *Runner) incrementalBase() string {
+	baseline := r.opts.Baseline
+	if baseline == nil || baseline.HeadSHA == "" {
+		return ""
+	}
+	if r.opts.HeadSHA == "" {
+		logx.Warningf("Review state from %s found but the head SHA is unknown; reviewing the full diff", baseline.HeadSHA)
+		return ""
+	}
+	return baseline.HeadSHA
+}
+
+var resolutionStatusRe = regexp.MustCompile(`(?im)^\s*#?\s*status\s*:\s*\[?\s*(resolved|still_present)\s*\]?\s*$`)
+
+// recheckIssues asks an agent, per previously confirmed issue, whether the
+// issue is still present at the current head. Still-present issues are
+// carried over as confirmed; the rest are returned as resolved. A failed or
+// inconclusive check keeps the issue open.
+func (r *Runner) recheckIssues(parentBranchID string, changeAnalysisPath string) (carried []IssueReport, resolved []IssueReport) {
+	previous := r.opts.Baseline.Issues
+	since := r.opts.Baseline.HeadSHA
+	checked := make([]IssueReport, len(previous))
+	stillPresent := make([]bool, len(previous))
+	r.forEachIssue(len(previous), func(i int) {
+		issue := previous[i]
+		step := fmt.Sprintf("recheck_issue_%d", i+1)
+		r.recordStepStart(step)
+		start := time.Now()
+		defer func() { r.recordStepEnd(step, time.Since(start)) }()
+
+		// An issue carried over before keeps the head it was first reported at.
+		if issue.CarriedFrom == "" {
+			issue.CarriedFrom = since
+		}
+		prompt := r.withSkills("recheck", buildResolutionCheckPrompt(r.opts.Task, issue.IssueText, since, changeAnalysisPath))
+		data, err := r.executeAgent("codex", prompt, parentBranchID)
+		if err != nil {
+			r.recordAbnormalStep(step, fmt.Sprintf("Resolution check failed: %v", err))
+			issue.VerdictExplanation = fmt.Sprintf("Reported at %s; resolution check failed, still open: %v", issue.CarriedFrom, err)
+			checked[i], stillPresent[i] = issue, true
+			return
+		}
+		text := strings.TrimSpace(stringField(data, "response"))
+		m := resolutionStatusRe.FindStringSubmatch(text)
+		if m != nil && strings.EqualFold(m[1], "resolved") {
+			issue.Status = commentResolved
+			issue.VerdictExplanation = fmt.Sprintf("Reported at %s; resolved by later commits.", issue.CarriedFrom)
+			checked[i] = issue
+			return
+		}
+		issue.Status = commentConfirmed
+		issue.VerdictExplanation = fmt.Sprintf("Reported at %s; still present at %s.", issue.CarriedFrom, r.opts.HeadSHA)
+		checked[i], stillPresent[i] = issue, true
+	})
+
+	for i, issue := range checked {
+		if stillPresent[i] {
+			carried = append(carried, issue)
+		} else {
+			resolved = append(resolved, issue)
+		}
+	}
+	return carried, resolved
+}
@@ -0,0 +1,140 @@
+package prreview
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReviewStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "review_state.json")
+	if state, err := LoadReviewState(path); err != nil || state != nil {
+		t.Fatalf("missing state file should load as nil, got %+v, %v", state, err)
+	}
+
+	result := &Result{Issues: []IssueReport{
+		{IssueText: "ISSUE: kept", Status: commentConfirmed},
+		{IssueText: "ISSUE: dropped", Status: commentUnresolved},
+	}}
+	if err := SaveReviewState(path, NewReviewState("abc123", result)); err != nil {
+		t.Fatalf("SaveReviewState returned error: %v", err)
+	}
+	state, err := LoadReviewState(path)
+	if err != nil {
+		t.Fatalf("LoadReviewState returned error: %v", err)
+	}
+	if state.HeadSHA != "abc123" || len(state.Issues) != 1 || state.Issues[0].IssueText != "ISSUE: kept" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatalf("write corrupt state: %v", err)
+	}
+	if _, err := LoadReviewState(path); err == nil {
+		t.Fatalf("expected a corrupt state file to be rejected")
+	}
+}
+
+func TestRunIncrementalReviewRechecksPreviousIssues(t *testing.T) {
+	client := newFakeAgentClient(nil, nil)
+	client.respond = func(role string, round int, prompt string) string {
+		if role != "resolution" {
+			return rejected
+		}
+		if strings.Contains(prompt, "ISSUE: fixed") {
+			return "# STATUS: RESOLVED\n\n## Evidence\nGuard added."
+		}
+		return "# STATUS: STILL_PRESENT\n\n## Evidence\nUnchanged."
+	}
+	runner := newAlignmentTestRunner(t, client, Options{
+		SkipScout: true,
+		HeadSHA:   "def456",
+		Baseline: &ReviewState{HeadSHA: "abc123", Issues: []IssueReport{
+			{IssueText: "ISSUE: fixed", Status: commentConfirmed},
+			{IssueText: "ISSUE: open", Status: commentConfirmed},
+		}},
+	})
+	runner.hasRealIssueOverride = func(string) (bool, error) { return false, nil }
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if result.IncrementalBaseSHA != "abc123" || result.HeadSHA != "def456" {
+		t.Fatalf("unexpected incremental range %q..%q", result.IncrementalBaseSHA, result.HeadSHA)
+	}
+	if result.Status != statusIssues || len(result.Issues) != 1 || result.Issues[0].IssueText != "ISSUE: open" {
+		t.Fatalf("expected the unfixed issue to be carried over, got status=%q issues=%#v", result.Status, result.Issues)
+	}
+	if result.Issues[0].CarriedFrom != "abc123" || result.Issues[0].Status != commentConfirmed {
+		t.Fatalf("unexpected carried issue %#v", result.Issues[0])
+	}
+	if len(result.ResolvedIssues) != 1 || result.ResolvedIssues[0].Status != commentResolved {
+		t.Fatalf("expected the fixed issue to be resolved, got %#v", result.ResolvedIssues)
+	}
+
+	scoped := false
+	for _, call := range client.snapshot() {
+		if strings.Contains(call.prompt, "INCREMENTAL review") && strings.Contains(call.prompt, "git diff abc123 HEAD") {
+			scoped = true
+		}
+		if strings.Contains(call.prompt, "git merge-base HEAD BASE_BRANCH") {
+			t.Fatalf("incremental review must not diff against the merge-base")
+		}
+	}
+	if !scoped {
+		t.Fatalf("expected the issue finder prompt to be scoped to abc123..HEAD")
+	}
+}
+
+func TestRecheckIssuesBoundsParallelismAndKeepsCarriedFrom(t *testing.T) {
+	client := newFakeAgentClient(nil, nil)
+	client.respond = func(role string, round int, prompt string) string {
+		return "# STATUS: STILL_PRESENT\n\n## Evidence\nUnchanged."
+	}
+	client.runTime = 20 * time.Millisecond
+	runner := newAlignmentTestRunner(t, client, Options{
+		IssueParallelism: 2,
+		HeadSHA:          "def456",
+		Baseline: &ReviewState{HeadSHA: "abc123", Issues: []IssueReport{
+			{IssueText: "ISSUE: old", Status: commentConfirmed, CarriedFrom: "0a1b2c"},
+			{IssueText: "ISSUE: new", Status: commentConfirmed},
+			{IssueText: "ISSUE: newer", Status: commentConfirmed},
+		}},
+	})
+
+	carried, resolved := runner.recheckIssues("start", "")
+	if len(carried) != 3 || len(resolved) != 0 {
+		t.Fatalf("expected every issue to be carried, got %d carried, %d resolved", len(carried), len(resolved))
+	}
+	if carried[0].CarriedFrom != "0a1b2c" || carried[1].CarriedFrom != "abc123" {
+		t.Fatalf("CarriedFrom must keep the first reporting head, got %q and %q", carried[0].CarriedFrom, carried[1].CarriedFrom)
+	}
+	if client.peak != 2 {
+		t.Fatalf("expected rechecks to honor IssueParallelism=2, saw %d at once", client.peak)
+	}
+}
+
+func TestRunSkipsReviewWhenHeadIsUnchanged(t *testing.T) {
+	client := newFakeAgentClient(nil, nil)
+	runner := newAlignmentTestRunner(t, client, Options{
+		HeadSHA:  "abc123",
+		Baseline: &ReviewState{HeadSHA: "abc123", Issues: []IssueReport{{IssueText: "ISSUE: open", Status: commentConfirmed}}},
+	})
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if result.Status != statusIssues || len(result.Issues) != 1 {
+		t.Fatalf("expected previous findings to be returned, got %#v", result)
+	}
+	if calls := client.snapshot(); len(calls) != 0 {
+		t.Fatalf("expected no agent runs for an unchanged head, got %d", len(calls))
+	}
+	if result.ReviewStatistics == nil || len(result.ReviewStatistics.StepTimings) != 0 {
+		t.Fatalf("expected empty review statistics for an unchanged head, got %#v", result.ReviewStatistics)
+	}
+}
@@ -0,0 +1,345 @@
+package prreview
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"review_agent/internal/logx"
+)
+
+const (
+	// SuppressionConsensus marks issues every voter rejected.
+	SuppressionConsensus = "consensus_rejected"
+	// SuppressionDismissed marks issues a human dismissed.
+	SuppressionDismissed = "dismissed"
+
+	// Token-set similarity an issue needs to match a suppression, lowered
+	// when both name the same symbol.
+	suppressionTextThreshold   = 0.6
+	suppressionSymbolThreshold = 0.3
+)
+
+var (
+	symbolRe     = regexp.MustCompile(`\b([A-Za-z_]\w+\.[A-Za-z_]\w+|[A-Za-z_]\w+)\(`)
+	dottedRe     = regexp.MustCompile(`\b[A-Za-z_]\w+\.[A-Za-z_]\w+\b`)
+	issueLabelRe = regexp.MustCompile(`(?i)^\s*(issue\s*#?\d*|\d+[.)])\s*[:.-]?\s*`)
+	nonWordRe    = regexp.MustCompile(`[^a-z_]+`)
+	sourceExts   = map[string]bool{
+		".go": true, ".rs": true, ".py": true, ".js": true, ".ts": true, ".tsx": true, ".jsx": true,
+		".java": true, ".kt": true, ".c": true, ".h": true, ".cc": true, ".cpp": true, ".rb": true,
+		".sql": true, ".sh": true, ".proto": true, ".md": true, ".json": true, ".yaml": true, ".yml": true, ".toml": true,
+	}
+	suppressStops = map[string]bool{
+		"the": true, "and": true, "for": true, "that": true, "this": true, "with": true, "when": true,
+		"can": true, "may": true, "not": true, "are": true, "was": true, "which": true, "from": true,
+		"issue": true, "severity": true, "impact": true, "fix": true, "line": true,
+	}
+)
+
+// Suppression is a known false positive.
+type Suppression struct {
+	Fingerprint string `json:"fingerprint"`
+	Project     string `json:"project"`
+	File        string `json:"file,omitempty"`
+	Symbol      string `json:"symbol,omitempty"`
+	// Text is the normalized issue text matched against new issues.
+	Text      string `json:"text"`
+	Reason    string `json:"reason"`
+	IssueText string `json:"issue_text"`
+	CreatedAt string `json:"created_at"`
+	Hits      int    `json:"hits,omitempty"`
+	LastHitAt string `json:"last_hit_at,omitempty"`
+}
+
+// SuppressedIssue records a parsed issue skipped as a known false positive.
+type SuppressedIssue struct {
+	IssueText   string `json:"issue_text"`
+	Fingerprint string `json:"fingerprint"`
+	Reason      string `json:"reason"`
+}
+
+// SuppressionStore is a file-backed list of known false positives. Entries
+// are scoped by project, so one file can serve several projects.
+type SuppressionStore struct {
+	path    string
+	mu      sync.Mutex
+	entries []Suppression
+	dirty   bool
+}
+
+type suppressionFile struct {
+	Suppressions []Suppression `json:"suppressions"`
+}
+
+// LoadSuppressionStore reads the store at path. A missing file yields an
+// empty store that is created on the first Save.
+func LoadSuppressionStore(path string) (*SuppressionStore, error) {
+	store := &SuppressionStore{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read suppressions: %w", err)
+	}
+	var file suppressionFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse suppressions %s: %w", filepath.Base(path), err)
+	}
+	store.entries = file.Suppressions
+	return store, nil
+}
+
+// Entries returns a copy of the stored suppressions.
+func (s *SuppressionStore) Entries() []Suppression {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Suppression(nil), s.entries...)
+}
+
+// Add records issueText as a false positive of project. Re-adding a known
+// issue keeps the existing entry and reports false.
+func (s *SuppressionStore) Add(project, issueText, reason string) (Suppression, bool) {
+	entry := newSuppression(project, issueText, reason)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.entries {
+		if existing.Fingerprint == entry.Fingerprint {
+			return existing, false
+		}
+	}
+	s.entries = append(s.entries, entry)
+	s.dirty = true
+	return entry, true
+}
+
+// Match returns the suppression issueText matches for project, and records
+// the hit.
+func (s *SuppressionStore) Match(project, issueText string) (Suppression, bool) {
+	candidate := newSuppression(project, issueText, "")
+	tokens := tokenSet(candidate.Text)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	best, bestScore := -1, 0.0
+	for i, entry := range s.entries {
+		if entry.Project != candidate.Project || !sameFile(entry.File, candidate.File) {
+			continue
+		}
+		threshold := similarityThreshold(entry.Symbol, candidate.Symbol)
+		score := jaccard(tokens, tokenSet(entry.Text))
+		if entry.Fingerprint == candidate.Fingerprint {
+			score = 1
+		}
+		if score >= threshold && score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return Suppression{}, false
+	}
+	s.entries[best].Hits++
+	s.entries[best].LastHitAt = time.Now().UTC().Format(time.RFC3339)
+	s.dirty = true
+	return s.entries[best], true
+}
+
+// Save writes the store back to its file when it changed, replacing it
+// atomically.
+func (s *SuppressionStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(suppressionFile{Suppressions: s.entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("write suppressions: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
+
+func newSuppression(project, issueText, reason string) Suppression {
+	entry := Suppression{
+		Project:   strings.TrimSpace(project),
+		Text:      normalizeIssueText(issueText),
+		Symbol:    issueSymbol(issueText),
+		Reason:    reason,
+		IssueText: strings.TrimSpace(issueText),
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if refs := (IssueReport{IssueText: issueText}).References(); len(refs) > 0 {
+		entry.File = path.Clean(refs[0].Path)
+	}
+	sum := sha1.Sum([]byte(entry.Project + "\x00" + entry.File + "\x00" + entry.Symbol + "\x00" + entry.Text))
+	entry.Fingerprint = hex.EncodeToString(sum[:8])
+	return entry
+}
+
+// normalizeIssueText reduces an issue to its sorted distinctive words, so
+// renumbering, line shifts and punctuation do not defeat matching.
+func normalizeIssueText(text string) string {
+	text = issueLabelRe.ReplaceAllString(strings.TrimSpace(text), "")
+	text = fileLineRe.ReplaceAllString(text, " ")
+	words := tokenSet(nonWordRe.ReplaceAllString(strings.ToLower(text), " "))
+	sorted := make([]string, 0, len(words))
+	for w := range words {
+		sorted = append(sorted, w)
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, " ")
+}
+
+// issueSymbol returns the first function or method the issue names, e.g.
+// "Store.Put" from "Store.Put()" or "nil map write in Store.Put".
+func issueSymbol(text string) string {
+	text = fileLineRe.ReplaceAllString(text, " ")
+	if m := symbolRe.FindStringSubmatch(text); m != nil {
+		return m[1]
+	}
+	for _, candidate := range dottedRe.FindAllString(text, -1) {
+		// Skip bare file names such as main.go.
+		if !sourceExts[strings.ToLower(path.Ext(candidate))] {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// SameIssue reports whether two wordings describe the same issue by the
+// suppression matcher's rules: their distinctive words overlap enough, with
+// a lower bar when both name the same symbol.
+func SameIssue(a, b string) bool {
+	score := jaccard(tokenSet(normalizeIssueText(a)), tokenSet(normalizeIssueText(b)))
+	return score >= similarityThreshold(issueSymbol(a), issueSymbol(b))
+}
+
+func similarityThreshold(symbolA, symbolB string) float64 {
+	if symbolA != "" && symbolA == symbolB {
+		return suppressionSymbolThreshold
+	}
+	return suppressionTextThreshold
+}
+
+func tokenSet(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(text) {
+		if len(w) >= 3 && !suppressStops[w] {
+			set[w] = true
+		}
+	}
+	return set
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// sameFile compares file references the way agents print them: absolute,
+// workspace-relative or repo-relative.
+func sameFile(a, b string) bool {
+	if a == "" || b == "" {
+		return a == b
+	}
+	return a == b || strings.HasSuffix(a, "/"+b) || strings.HasSuffix(b, "/"+a)
+}
+
+// rejectedByConsensus reports whether every voter on the issue rejected it.
+// A reviewer-only decision (SkipTester) is not a consensus.
+func rejectedByConsensus(report IssueReport) bool {
+	if report.Status != commentUnresolved || report.Beta.Agent == "" {
+		return false
+	}
+	voters := append([]Transcript{report.Alpha, report.Beta}, report.Specialists...)
+	return withFormalVote(unanimousVerdict(voters), report.Formal) == "rejected"
+}
+
+// suppressKnownFalsePositives drops issues matching the suppression store
+// and records them in the review statistics.
+func (r *Runner) suppressKnownFalsePositives(issues []parsedIssue) []parsedIssue {
+	store := r.opts.Suppressions
+	if store == nil || len(issues) == 0 {
+		return issues
+	}
+	kept := issues[:0:0]
+	for _, issue := range issues {
+		entry, ok := store.Match(r.opts.ProjectName, issue.Text)
+		if !ok {
+			kept = append(kept, issue)
+			continue
+		}
+		logx.Infof("Skipping known false positive %s (%s): %s", entry.Fingerprint, entry.Reason, truncateForError(issue.Text))
+		r.statsMu.Lock()
+		r.statistics.SuppressedIssues = append(r.statistics.SuppressedIssues, SuppressedIssue{
+			IssueText:   issue.Text,
+			Fingerprint: entry.Fingerprint,
+			Reason:      entry.Reason,
+		})
+		r.statsMu.Unlock()
+	}
+	return kept
+}
+
+// recordFalsePositives adds the issues every voter rejected to the
+// suppression store.
+func (r *Runner) recordFalsePositives(reports []IssueReport) {
+	store := r.opts.Suppressions
+	if store == nil {
+		return
+	}
+	for _, report := range reports {
+		if !rejectedByConsensus(report) {
+			continue
+		}
+		if entry, added := store.Add(r.opts.ProjectName, report.IssueText, SuppressionConsensus); added {
+			logx.Infof("Recorded false positive %s: %s", entry.Fingerprint, truncateForError(report.IssueText))
+		}
+	}
+}
+
+// writeFileAtomic replaces path with data via a temporary file in the same
+// directory.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package prreview
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSuppressionStoreMatchesRephrasedIssue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suppressions.json")
+	store, err := LoadSuppressionStore(path)
+	if err != nil {
+		t.Fatalf("LoadSuppressionStore on a missing file: %v", err)
+	}
+	entry, added := store.Add("proj", "ISSUE 1 (P0): nil map write in Store.Put at pkg/store.go:12 panics when the cache is empty", SuppressionConsensus)
+	if !added || entry.File != "pkg/store.go" || entry.Symbol != "Store.Put" {
+		t.Fatalf("unexpected suppression %+v", entry)
+	}
+	if _, again := store.Add("proj", "ISSUE 1 (P0): nil map write in Store.Put at pkg/store.go:12 panics when the cache is empty", SuppressionDismissed); again {
+		t.Fatalf("re-adding the same issue must not duplicate it")
+	}
+
+	rephrased := "ISSUE 3 (P0): Store.Put() writes to a nil map in /workspace/pkg/store.go:15 and panics when the cache is empty"
+	if _, ok := store.Match("proj", rephrased); !ok {
+		t.Fatalf("expected the rephrased issue to match")
+	}
+	if _, ok := store.Match("other", rephrased); ok {
+		t.Fatalf("suppressions must be scoped to their project")
+	}
+	if _, ok := store.Match("proj", "ISSUE 3 (P0): Store.Put() writes to a nil map in pkg/cache.go:15 and panics when the cache is empty"); ok {
+		t.Fatalf("an issue in another file must not match")
+	}
+	if _, ok := store.Match("proj", "ISSUE 2 (P1): Store.Put ignores the context deadline in pkg/store.go:40 and blocks forever"); ok {
+		t.Fatalf("a different defect in the same function must not match")
+	}
+
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	reloaded, err := LoadSuppressionStore(path)
+	if err != nil {
+		t.Fatalf("reload error: %v", err)
+	}
+	entries := reloaded.Entries()
+	if len(entries) != 1 || entries[0].Hits != 1 || entries[0].Reason != SuppressionConsensus {
+		t.Fatalf("unexpected reloaded entries %+v", entries)
+	}
+}
+
+func TestRunSkipsSuppressedIssuesAndRecordsRejections(t *testing.T) {
+	store, err := LoadSuppressionStore(filepath.Join(t.TempDir(), "suppressions.json"))
+	if err != nil {
+		t.Fatalf("LoadSuppressionStore error: %v", err)
+	}
+	store.Add("proj", "ISSUE: goroutine leak in Worker.Run at worker/pool.go:30 when the context is cancelled", SuppressionDismissed)
+
+	client := newFakeAgentClient([]string{rejected}, []string{rejected})
+	runner := newAlignmentTestRunner(t, client, Options{SkipScout: true, Suppressions: store})
+	runner.hasRealIssueOverride = func(string) (bool, error) { return true, nil }
+	runner.parseIssuesOverride = func(string) ([]string, error) {
+		return []string{
+			"ISSUE 1: Worker.Run() leaks a goroutine in worker/pool.go:31 when the context is cancelled",
+			"ISSUE 2: off-by-one in Pager.Next at api/page.go:8 skips the last item",
+		}, nil
+	}
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if len(result.Issues) != 1 || !strings.Contains(result.Issues[0].IssueText, "Pager.Next") {
+		t.Fatalf("expected only the unsuppressed issue to be verified, got %#v", result.Issues)
+	}
+	suppressed := result.ReviewStatistics.SuppressedIssues
+	if len(suppressed) != 1 || suppressed[0].Reason != SuppressionDismissed || !strings.Contains(result.Summary, "1 known false positives suppressed") {
+		t.Fatalf("expected the suppression in the statistics and summary, got %+v / %q", suppressed, result.Summary)
+	}
+	for _, call := range client.snapshot() {
+		if strings.Contains(call.prompt, "Worker.Run") {
+			t.Fatalf("suppressed issue must not reach verification: %s", call.classifiedRole)
+		}
+	}
+
+	entries := store.Entries()
+	if len(entries) != 2 || entries[1].Reason != SuppressionConsensus || entries[1].Symbol != "Pager.Next" {
+		t.Fatalf("expected the unanimously rejected issue to be recorded, got %+v", entries)
+	}
+}
@@ -0,0 +1,255 @@
+// Package skills loads skill packs (codex_skills/<name>/SKILL.md) and injects
+// them into agent prompts. Each runner phase declares the skills it accepts;
+// a run enables a subset with --skills.
+package skills
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FileName is the file that marks a skill directory.
+const FileName = "SKILL.md"
+
+// DefaultDir is where skill packs are looked up when no directory is given.
+const DefaultDir = "codex_skills"
+
+// Skill is one parsed skill pack.
+type Skill struct {
+	Name        string
+	Description string
+	// Path is the SKILL.md file the skill was read from.
+	Path string
+	// Body is the methodology after the front-matter.
+	Body string
+	// Version is a short content hash, so results show which revision of a
+	// skill shaped them.
+	Version string
+}
+
+// Usage records one skill injected during a run and the phases it shaped.
+type Usage struct {
+	Name    string   `json:"name"`
+	Version string   `json:"version"`
+	Phases  []string `json:"phases"`
+}
+
+// Parse reads a SKILL.md. The front-matter between leading "---" lines holds
+// "key: value" pairs; name defaults to the directory name.
+func Parse(path string, data []byte) (Skill, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	sum := sha256.Sum256(data)
+	skill := Skill{
+		Name:    filepath.Base(filepath.Dir(path)),
+		Path:    path,
+		Body:    strings.TrimSpace(text),
+		Version: hex.EncodeToString(sum[:])[:12],
+	}
+	if !strings.HasPrefix(text, "---\n") {
+		return skill, nil
+	}
+	end := strings.Index(text[4:], "\n---")
+	if end < 0 {
+		return Skill{}, fmt.Errorf("%s: unterminated front-matter", path)
+	}
+	header := text[4 : 4+end]
+	skill.Body = strings.TrimSpace(strings.TrimPrefix(text[4+end+4:], "-"))
+	for _, line := range strings.Split(header, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		value = unquote(strings.TrimSpace(value))
+		switch strings.TrimSpace(key) {
+		case "name":
+			if value != "" {
+				skill.Name = value
+			}
+		case "description":
+			skill.Description = value
+		}
+	}
+	return skill, nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 {
+		switch value[0] {
+		case '"':
+			if s, err := strconv.Unquote(value); err == nil {
+				return s
+			}
+		case '\'':
+			if value[len(value)-1] == '\'' {
+				return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+			}
+		}
+	}
+	return value
+}
+
+// Discover parses every <dir>/<name>/SKILL.md, sorted by name.
+func Discover(dir string) ([]Skill, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*", FileName))
+	if err != nil {
+		return nil, err
+	}
+	var out []Skill
+	seen := map[string]string{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read skill: %w", err)
+		}
+		skill, err := Parse(path, data)
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := seen[skill.Name]; ok {
+			return nil, fmt.Errorf("skill %q defined twice (%s, %s)", skill.Name, prev, path)
+		}
+		seen[skill.Name] = path
+		out = append(out, skill)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// ParseList splits a --skills value. "none" or an empty value selects no
+// skill; "all" selects every discovered one.
+func ParseList(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" && name != "none" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Set is the skills enabled for one run. A nil Set injects nothing. It is
+// safe for concurrent use.
+type Set struct {
+	skills map[string]Skill
+
+	mu   sync.Mutex
+	used map[string]map[string]bool
+}
+
+// Load discovers the skills in dir and enables names. It returns nil when no
+// skill is requested, and an error naming the available skills when a
+// requested one does not exist.
+func Load(dir string, names []string) (*Set, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if strings.TrimSpace(dir) == "" {
+		dir = DefaultDir
+	}
+	available, err := Discover(dir)
+	if err != nil {
+		return nil, err
+	}
+	return Select(available, names)
+}
+
+// Select enables names out of available; "all" enables every skill.
+func Select(available []Skill, names []string) (*Set, error) {
+	byName := map[string]Skill{}
+	for _, skill := range available {
+		byName[skill.Name] = skill
+	}
+	set := &Set{skills: map[string]Skill{}, used: map[string]map[string]bool{}}
+	for _, name := range names {
+		if name == "all" {
+			for n, skill := range byName {
+				set.skills[n] = skill
+			}
+			continue
+		}
+		skill, ok := byName[name]
+		if !ok {
+			known := make([]string, 0, len(byName))
+			for n := range byName {
+				known = append(known, n)
+			}
+			sort.Strings(known)
+			if len(known) == 0 {
+				return nil, fmt.Errorf("unknown skill %q: no skills found", name)
+			}
+			return nil, fmt.Errorf("unknown skill %q (available: %s)", name, strings.Join(known, ", "))
+		}
+		set.skills[name] = skill
+	}
+	if len(set.skills) == 0 {
+		return nil, errors.New("no skills selected")
+	}
+	return set, nil
+}
+
+// Apply prepends to prompt every skill in declared that the run enabled, and
+// records that phase used them. Skills already present in the prompt are not
+// repeated.
+func (s *Set) Apply(phase string, declared []string, prompt string) string {
+	if s == nil {
+		return prompt
+	}
+	var blocks []string
+	for _, name := range declared {
+		skill, ok := s.skills[name]
+		if !ok {
+			continue
+		}
+		s.record(name, phase)
+		header := fmt.Sprintf("# Skill: %s", skill.Name)
+		if strings.Contains(prompt, header) {
+			continue
+		}
+		block := header
+		if skill.Description != "" {
+			block += "\n> " + skill.Description
+		}
+		blocks = append(blocks, block+"\n\nFollow this methodology for the task below.\n\n"+skill.Body)
+	}
+	if len(blocks) == 0 {
+		return prompt
+	}
+	return strings.Join(blocks, "\n\n") + "\n\n---\n\n" + prompt
+}
+
+func (s *Set) record(name, phase string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.used[name] == nil {
+		s.used[name] = map[string]bool{}
+	}
+	s.used[name][phase] = true
+}
+
+// Used lists the skills injected so far, sorted by name, with their phases.
+func (s *Set) Used() []Usage {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Usage, 0, len(s.used))
+	for name, phases := range s.used {
+		usage := Usage{Name: name, Version: s.skills[name].Version}
+		for phase := range phases {
+			usage.Phases = append(usage.Phases, phase)
+		}
+		sort.Strings(usage.Phases)
+		out = append(out, usage)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
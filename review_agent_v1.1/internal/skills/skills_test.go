@@ -0,0 +1,91 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSkill(t *testing.T, root, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, dir, FileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscoverParsesFrontMatter(t *testing.T) {
+	root := t.TempDir()
+	writeSkill(t, root, "re2", "---\nname: re2\ndescription: \"Invariant-first review: enumerate invariants\"\nauthor: someone\n---\n\n# RE2\nList the invariants.\n")
+	writeSkill(t, root, "plain", "# Plain\nNo front-matter.\n")
+	if err := os.MkdirAll(filepath.Join(root, "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := Discover(root)
+	if err != nil {
+		t.Fatalf("Discover error: %v", err)
+	}
+	if len(found) != 2 || found[0].Name != "plain" || found[1].Name != "re2" {
+		t.Fatalf("unexpected skills %+v", found)
+	}
+	re2 := found[1]
+	if re2.Description != "Invariant-first review: enumerate invariants" || re2.Body != "# RE2\nList the invariants." || len(re2.Version) != 12 {
+		t.Fatalf("unexpected parse %+v", re2)
+	}
+	if found[0].Body != "# Plain\nNo front-matter." {
+		t.Fatalf("a skill without front-matter keeps its whole text, got %q", found[0].Body)
+	}
+}
+
+func TestSelectRejectsUnknownSkill(t *testing.T) {
+	available := []Skill{{Name: "re2"}}
+	if _, err := Select(available, []string{"re3"}); err == nil || !strings.Contains(err.Error(), "available: re2") {
+		t.Fatalf("expected an error listing the available skills, got %v", err)
+	}
+	if set, err := Load(t.TempDir(), ParseList("none")); err != nil || set != nil {
+		t.Fatalf("none must select nothing, got %v %v", set, err)
+	}
+}
+
+func TestApplyInjectsDeclaredSkillsAndRecordsUsage(t *testing.T) {
+	set, err := Select([]Skill{{Name: "re2", Description: "deep review", Body: "List the invariants.", Version: "abc"}, {Name: "other", Body: "x"}}, ParseList("re2"))
+	if err != nil {
+		t.Fatalf("Select error: %v", err)
+	}
+	prompt := set.Apply("review", []string{"re2", "other"}, "Review the PR.")
+	if !strings.HasPrefix(prompt, "# Skill: re2\n> deep review") || !strings.HasSuffix(prompt, "---\n\nReview the PR.") || strings.Contains(prompt, "# Skill: other") {
+		t.Fatalf("unexpected prompt:\n%s", prompt)
+	}
+	if again := set.Apply("recheck", []string{"re2"}, prompt); again != prompt {
+		t.Fatalf("an already injected skill must not be repeated")
+	}
+	if got := set.Apply("summary", nil, "Summarize."); got != "Summarize." {
+		t.Fatalf("a phase declaring no skills must be left alone, got %q", got)
+	}
+	used := set.Used()
+	if len(used) != 1 || used[0].Name != "re2" || used[0].Version != "abc" || strings.Join(used[0].Phases, ",") != "recheck,review" {
+		t.Fatalf("unexpected usage %+v", used)
+	}
+
+	var none *Set
+	if none.Apply("review", []string{"re2"}, "p") != "p" || none.Used() != nil {
+		t.Fatalf("a nil set must inject nothing")
+	}
+}
+
+func TestRepositorySkillPackParses(t *testing.T) {
+	found, err := Discover(filepath.Join("..", "..", "..", DefaultDir))
+	if err != nil {
+		t.Fatalf("Discover error: %v", err)
+	}
+	for _, skill := range found {
+		if skill.Name == "re2" && skill.Description != "" && !strings.HasPrefix(skill.Body, "---") {
+			return
+		}
+	}
+	t.Fatalf("expected the shipped re2 skill, got %+v", found)
+}
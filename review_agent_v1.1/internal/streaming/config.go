@@ -0,0 +1,148 @@
+package streaming
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Sink types of a --stream-sinks file.
+const (
+	SinkStdout  = "stdout"
+	SinkFile    = "file"
+	SinkUnix    = "unix"
+	SinkWebhook = "webhook"
+)
+
+// SinkConfig is one entry of a --stream-sinks file. Header values expand
+// ${VAR} references from the environment, so tokens stay out of the file.
+type SinkConfig struct {
+	Type string `json:"type"`
+	// Path is the NDJSON file or the Unix socket.
+	Path     string `json:"path,omitempty"`
+	MaxBytes int64  `json:"max_bytes,omitempty"`
+	MaxFiles int    `json:"max_files,omitempty"`
+
+	URL             string            `json:"url,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	BatchSize       int               `json:"batch_size,omitempty"`
+	FlushIntervalMS int               `json:"flush_interval_ms,omitempty"`
+	MaxRetries      int               `json:"max_retries,omitempty"`
+	TimeoutSeconds  float64           `json:"timeout_seconds,omitempty"`
+
+	Filter
+}
+
+type sinkConfigFile struct {
+	Sinks []SinkConfig `json:"sinks"`
+}
+
+// LoadSinkConfigs reads and validates a --stream-sinks file. An empty path
+// configures no sinks.
+func LoadSinkConfigs(path string) ([]SinkConfig, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read stream sinks: %w", err)
+	}
+	var file sinkConfigFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse stream sinks %s: %w", filepath.Base(path), err)
+	}
+	for i := range file.Sinks {
+		c := &file.Sinks[i]
+		c.Type = strings.ToLower(strings.TrimSpace(c.Type))
+		if err := c.validate(); err != nil {
+			return nil, fmt.Errorf("stream sinks %s: sink %d: %w", filepath.Base(path), i+1, err)
+		}
+	}
+	return file.Sinks, nil
+}
+
+func (c SinkConfig) validate() error {
+	switch c.Type {
+	case SinkStdout:
+	case SinkFile, SinkUnix:
+		if strings.TrimSpace(c.Path) == "" {
+			return fmt.Errorf("%s sink needs a path", c.Type)
+		}
+	case SinkWebhook:
+		if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+			return fmt.Errorf("webhook sink needs an http(s) url, got %q", c.URL)
+		}
+	default:
+		return fmt.Errorf("unknown sink type %q (expected stdout, file, unix or webhook)", c.Type)
+	}
+	if c.MaxBytes < 0 || c.MaxFiles < 0 || c.BatchSize < 0 || c.FlushIntervalMS < 0 || c.TimeoutSeconds < 0 {
+		return fmt.Errorf("%s sink: sizes and intervals must not be negative", c.Type)
+	}
+	return nil
+}
+
+// WritesStdout reports whether a run streams events to stdout, either through
+// --stream-json or a stdout sink. Such a run must keep stdout machine-readable.
+func WritesStdout(streamJSON bool, configs []SinkConfig) bool {
+	if streamJSON {
+		return true
+	}
+	for _, c := range configs {
+		if c.Type == SinkStdout {
+			return true
+		}
+	}
+	return false
+}
+
+// Open builds the streamer of a CLI run from configs. streamJSON adds an
+// unfiltered stdout sink unless configs already declare one. With neither the
+// streamer is disabled.
+func Open(streamJSON bool, configs []SinkConfig) (*JSONStreamer, error) {
+	var sinks []Sink
+	hasStdout := false
+	for _, c := range configs {
+		sink, err := c.open()
+		if err != nil {
+			for _, opened := range sinks {
+				opened.Close()
+			}
+			return nil, err
+		}
+		hasStdout = hasStdout || c.Type == SinkStdout
+		sinks = append(sinks, sink)
+	}
+	if streamJSON && !hasStdout {
+		sinks = append(sinks, NewWriterSink(os.Stdout, Filter{}))
+	}
+	return NewSinkStreamer(sinks...), nil
+}
+
+func (c SinkConfig) open() (Sink, error) {
+	switch c.Type {
+	case SinkStdout:
+		return NewWriterSink(os.Stdout, c.Filter), nil
+	case SinkFile:
+		return NewFileSink(c.Path, c.MaxBytes, c.MaxFiles, c.Filter)
+	case SinkUnix:
+		return NewUnixSink(c.Path, c.Filter), nil
+	case SinkWebhook:
+		headers := make(map[string]string, len(c.Headers))
+		for k, v := range c.Headers {
+			headers[k] = os.ExpandEnv(v)
+		}
+		return NewWebhookSink(WebhookConfig{
+			URL:           c.URL,
+			Headers:       headers,
+			BatchSize:     c.BatchSize,
+			FlushInterval: time.Duration(c.FlushIntervalMS) * time.Millisecond,
+			MaxRetries:    c.MaxRetries,
+			Timeout:       time.Duration(c.TimeoutSeconds * float64(time.Second)),
+		}, c.Filter), nil
+	}
+	return nil, fmt.Errorf("unknown sink type %q", c.Type)
+}
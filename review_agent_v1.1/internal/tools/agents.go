@@ -0,0 +1,210 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AgentRole describes where an agent sits in the implement/review loop.
+type AgentRole string
+
+const (
+	AgentRoleBuilder AgentRole = "builder"
+	AgentRoleCritic  AgentRole = "critic"
+	AgentRoleJudge   AgentRole = "judge"
+)
+
+// ArtifactContract names a file an agent must leave on its branch. Relative
+// paths resolve against the handler's workspace directory. When ResultKey is
+// set, the file content is returned under that key in the execute_agent data.
+type ArtifactContract struct {
+	Path      string `json:"path"`
+	ResultKey string `json:"result_key,omitempty"`
+}
+
+// AgentSpec is the contract ToolHandler enforces for one Pantheon agent.
+type AgentSpec struct {
+	Name string
+	Role AgentRole
+	// PollTimeout overrides the handler's branch polling timeout; zero keeps
+	// the handler default.
+	PollTimeout       time.Duration
+	RequiredArtifacts []ArtifactContract
+	// Retries is the number of extra runs allowed when a required artifact is
+	// missing. Branch failures are never retried.
+	Retries int
+	// PromptPrefixes are prepended, in order, to every prompt sent to the
+	// agent unless the prompt already contains them.
+	PromptPrefixes []string
+}
+
+// Attempts returns the total number of runs allowed for the agent.
+func (s AgentSpec) Attempts() int {
+	if s.Retries < 0 {
+		return 1
+	}
+	return s.Retries + 1
+}
+
+// ApplyPromptPrefixes returns prompt with the spec's prefixes prepended.
+func (s AgentSpec) ApplyPromptPrefixes(prompt string) string {
+	var missing []string
+	for _, prefix := range s.PromptPrefixes {
+		if prefix = strings.TrimSpace(prefix); prefix != "" && !strings.Contains(prompt, prefix) {
+			missing = append(missing, prefix)
+		}
+	}
+	if len(missing) == 0 {
+		return prompt
+	}
+	return strings.Join(missing, "\n") + "\n\n" + prompt
+}
+
+func (s AgentSpec) validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("agent name is required")
+	}
+	switch s.Role {
+	case AgentRoleBuilder, AgentRoleCritic, AgentRoleJudge:
+	default:
+		return fmt.Errorf("agent %s: invalid role %q (expected builder, critic or judge)", s.Name, s.Role)
+	}
+	if s.PollTimeout < 0 {
+		return fmt.Errorf("agent %s: poll timeout must not be negative", s.Name)
+	}
+	if s.Retries < 0 {
+		return fmt.Errorf("agent %s: retries must not be negative", s.Name)
+	}
+	for _, artifact := range s.RequiredArtifacts {
+		if strings.TrimSpace(artifact.Path) == "" {
+			return fmt.Errorf("agent %s: required artifact path is empty", s.Name)
+		}
+	}
+	return nil
+}
+
+// AgentRegistry maps agent names to their contracts.
+type AgentRegistry struct {
+	specs map[string]AgentSpec
+}
+
+// defaultAgents backs handlers that were not given a registry. It is never
+// mutated.
+var defaultAgents = DefaultAgentRegistry()
+
+// DefaultAgentSpecs returns the built-in Pantheon agents.
+func DefaultAgentSpecs() []AgentSpec {
+	return []AgentSpec{
+		{Name: "codex", Role: AgentRoleBuilder},
+		{Name: "claude_code", Role: AgentRoleBuilder},
+		{
+			Name:              reviewCodeAgent,
+			Role:              AgentRoleCritic,
+			RequiredArtifacts: []ArtifactContract{{Path: reviewArtifactName, ResultKey: "review_report"}},
+			Retries:           reviewMaxAttempts - 1,
+		},
+	}
+}
+
+// NewAgentRegistry validates specs and builds a registry. A later spec with
+// the same name replaces an earlier one.
+func NewAgentRegistry(specs ...AgentSpec) (*AgentRegistry, error) {
+	reg := &AgentRegistry{specs: map[string]AgentSpec{}}
+	for _, spec := range specs {
+		if err := reg.Register(spec); err != nil {
+			return nil, err
+		}
+	}
+	return reg, nil
+}
+
+// DefaultAgentRegistry returns a registry holding DefaultAgentSpecs.
+func DefaultAgentRegistry() *AgentRegistry {
+	reg, err := NewAgentRegistry(DefaultAgentSpecs()...)
+	if err != nil {
+		panic(err)
+	}
+	return reg
+}
+
+// Register adds or replaces an agent.
+func (r *AgentRegistry) Register(spec AgentSpec) error {
+	spec.Name = strings.TrimSpace(spec.Name)
+	if err := spec.validate(); err != nil {
+		return err
+	}
+	r.specs[spec.Name] = spec
+	return nil
+}
+
+// Lookup returns the contract for name.
+func (r *AgentRegistry) Lookup(name string) (AgentSpec, bool) {
+	if r == nil {
+		return AgentSpec{}, false
+	}
+	spec, ok := r.specs[strings.TrimSpace(name)]
+	return spec, ok
+}
+
+// Names returns the registered agent names in sorted order.
+func (r *AgentRegistry) Names() []string {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, 0, len(r.specs))
+	for name := range r.specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// agentRegistryFile is the on-disk format read by LoadAgentRegistry.
+type agentRegistryFile struct {
+	Agents []struct {
+		Name               string             `json:"name"`
+		Role               AgentRole          `json:"role"`
+		PollTimeoutSeconds float64            `json:"poll_timeout_seconds,omitempty"`
+		RequiredArtifacts  []ArtifactContract `json:"required_artifacts,omitempty"`
+		Retries            int                `json:"retries,omitempty"`
+		PromptPrefixes     []string           `json:"prompt_prefixes,omitempty"`
+	} `json:"agents"`
+}
+
+// LoadAgentRegistry returns the default registry extended with the agents
+// declared in the JSON file at path. Entries named like a built-in agent
+// replace it. An empty path returns the defaults.
+func LoadAgentRegistry(path string) (*AgentRegistry, error) {
+	reg := DefaultAgentRegistry()
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return reg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read agent registry: %w", err)
+	}
+	var file agentRegistryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse agent registry %s: %w", filepath.Base(path), err)
+	}
+	for _, entry := range file.Agents {
+		spec := AgentSpec{
+			Name:              entry.Name,
+			Role:              AgentRole(stringsTrimLower(string(entry.Role))),
+			PollTimeout:       time.Duration(entry.PollTimeoutSeconds * float64(time.Second)),
+			RequiredArtifacts: entry.RequiredArtifacts,
+			Retries:           entry.Retries,
+			PromptPrefixes:    entry.PromptPrefixes,
+		}
+		if err := reg.Register(spec); err != nil {
+			return nil, fmt.Errorf("agent registry %s: %w", filepath.Base(path), err)
+		}
+	}
+	return reg, nil
+}
@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultAgentRegistryDeclaresBuiltInAgents(t *testing.T) {
+	reg := DefaultAgentRegistry()
+	if got := strings.Join(reg.Names(), ","); got != "claude_code,codex,review_code" {
+		t.Fatalf("unexpected default agents %q", got)
+	}
+	review, ok := reg.Lookup("review_code")
+	if !ok || review.Role != AgentRoleCritic || review.Attempts() != reviewMaxAttempts {
+		t.Fatalf("unexpected review_code spec %+v", review)
+	}
+	if len(review.RequiredArtifacts) != 1 || review.RequiredArtifacts[0].Path != reviewArtifactName {
+		t.Fatalf("review_code must require %s, got %+v", reviewArtifactName, review.RequiredArtifacts)
+	}
+	if codex, _ := reg.Lookup("codex"); codex.Role != AgentRoleBuilder || codex.Attempts() != 1 {
+		t.Fatalf("unexpected codex spec %+v", codex)
+	}
+}
+
+func TestLoadAgentRegistryExtendsDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents.json")
+	body := `{"agents": [
+		{"name": "judge", "role": "Judge", "poll_timeout_seconds": 90, "retries": 1,
+		 "required_artifacts": [{"path": "verdict.json", "result_key": "verdict"}],
+		 "prompt_prefixes": ["/judge"]},
+		{"name": "codex", "role": "builder", "retries": 2}
+	]}`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatalf("write registry: %v", err)
+	}
+
+	reg, err := LoadAgentRegistry(path)
+	if err != nil {
+		t.Fatalf("LoadAgentRegistry returned error: %v", err)
+	}
+	judge, ok := reg.Lookup("judge")
+	if !ok {
+		t.Fatalf("expected judge agent, have %v", reg.Names())
+	}
+	if judge.Role != AgentRoleJudge || judge.PollTimeout != 90*time.Second || judge.Attempts() != 2 {
+		t.Fatalf("unexpected judge spec %+v", judge)
+	}
+	if codex, _ := reg.Lookup("codex"); codex.Retries != 2 {
+		t.Fatalf("expected codex override, got %+v", codex)
+	}
+	if _, ok := reg.Lookup("review_code"); !ok {
+		t.Fatalf("expected built-in review_code to remain registered")
+	}
+}
+
+func TestLoadAgentRegistryRejectsInvalidRole(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents.json")
+	if err := os.WriteFile(path, []byte(`{"agents": [{"name": "x", "role": "boss"}]}`), 0o644); err != nil {
+		t.Fatalf("write registry: %v", err)
+	}
+	if _, err := LoadAgentRegistry(path); err == nil || !strings.Contains(err.Error(), "invalid role") {
+		t.Fatalf("expected invalid role error, got %v", err)
+	}
+}
+
+func TestApplyPromptPrefixesSkipsPresentPrefixes(t *testing.T) {
+	spec := AgentSpec{Name: "a", Role: AgentRoleBuilder, PromptPrefixes: []string{"/skill build", "MODE=strict"}}
+	if got := spec.ApplyPromptPrefixes("do it"); got != "/skill build\nMODE=strict\n\ndo it" {
+		t.Fatalf("unexpected prefixed prompt %q", got)
+	}
+	if got := spec.ApplyPromptPrefixes("/skill build\nMODE=strict\n\ndo it"); got != "/skill build\nMODE=strict\n\ndo it" {
+		t.Fatalf("prefixes must not be applied twice, got %q", got)
+	}
+}
+
+func TestExecuteAgentEnforcesRegisteredContract(t *testing.T) {
+	reg, err := NewAgentRegistry(AgentSpec{
+		Name:              "judge",
+		Role:              AgentRoleJudge,
+		RequiredArtifacts: []ArtifactContract{{Path: "verdict.json", ResultKey: "verdict"}},
+		Retries:           1,
+		PromptPrefixes:    []string{"/judge"},
+	})
+	if err != nil {
+		t.Fatalf("NewAgentRegistry returned error: %v", err)
+	}
+	client := &fakeMCPClient{
+		readResults: []branchReadResult{
+			{err: notFoundErr(1)},
+			{data: map[string]any{"content": `{"verdict": "pass"}`}},
+		},
+	}
+	handler := &ToolHandler{
+		client:        client,
+		defaultProj:   "proj",
+		branchTracker: NewBranchTracker("parent"),
+		workspaceDir:  "/workspace",
+	}
+	handler.SetAgentRegistry(reg)
+
+	res, err := handler.executeAgent(map[string]any{"agent": "judge", "prompt": "rule on it", "parent_branch_id": "parent"})
+	if err != nil {
+		t.Fatalf("executeAgent returned error: %v", err)
+	}
+	if client.parallelExploreCalls != 2 {
+		t.Fatalf("expected a retry after the missing artifact, got %d runs", client.parallelExploreCalls)
+	}
+	if client.explorePrompts[0] != "/judge\n\nrule on it" {
+		t.Fatalf("expected prompt prefix, got %q", client.explorePrompts[0])
+	}
+	if client.branchReadInputs[1].path != "/workspace/verdict.json" {
+		t.Fatalf("artifact must resolve against the workspace, got %q", client.branchReadInputs[1].path)
+	}
+	if res["verdict"] != `{"verdict": "pass"}` || res["agent_role"] != "judge" {
+		t.Fatalf("unexpected result %#v", res)
+	}
+
+	if _, err := handler.executeAgent(map[string]any{"agent": "codex", "prompt": "p", "parent_branch_id": "parent"}); err == nil {
+		t.Fatalf("expected agents missing from the registry to be rejected")
+	} else {
+		var te ToolExecutionError
+		if !errors.As(err, &te) || !strings.Contains(te.Msg, "unknown agent") {
+			t.Fatalf("expected unknown agent error, got %v", err)
+		}
+	}
+}
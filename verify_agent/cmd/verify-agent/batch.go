@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"verify_agent/internal/batch"
+	b "verify_agent/internal/brain"
+	cfg "verify_agent/internal/config"
+	"verify_agent/internal/logx"
+	"verify_agent/internal/skills"
+	"verify_agent/internal/tools"
+	"verify_agent/internal/verify"
+)
+
+// runBatch implements `verify-agent batch`: it verifies every bug of a JSONL
+// or CSV queue, writes one JSONL outcome per bug as it finishes and prints
+// the confirmed/wrong/cannot_disprove counts.
+func runBatch(args []string) int {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	input := fs.String("input", "", "JSONL or .csv queue of {id, description, code_context, hypothesis, parent_branch_id, project_name} records (required)")
+	output := fs.String("output", "-", "Write one JSONL outcome per bug to this file (\"-\" for stdout)")
+	concurrency := fs.Int("concurrency", 2, "Number of bugs verified concurrently")
+	parent := fs.String("parent-branch-id", "", "Branch UUID to fork from for records without parent_branch_id")
+	project := fs.String("project-name", "", "Project name for records without project_name (defaults to PROJECT_NAME)")
+	hypothesis := fs.String("hypothesis", verify.HypothesisReal, "Hypothesis for records without one: real, false-positive or both")
+	chainTasks := fs.String("chain-tasks", "none", "Tasks that fork from the previous task's branch instead of the parent: none, all, or a list such as 3 or 2,3")
+	bisect := fs.Bool("bisect", false, "Git bisect every bug a test confirms to find the commit that introduced it")
+	fix := fs.Bool("fix", false, "Ask a builder agent for a minimal fix of every bug a test confirms")
+	fixAgent := fs.String("fix-agent", "codex", "Builder agent that writes the --fix patches")
+	explorationID := fs.String("exploration-id", "", "Optional exploration id for MCP headers")
+	promptDir := fs.String("prompt-dir", "", "Directory of *.tmpl files overriding the embedded prompt templates")
+	skillNames := fs.String("skills", "", "Comma-separated skill packs to inject into the task prompts that declare them")
+	skillsDir := fs.String("skills-dir", skills.DefaultDir, "Directory of skill packs (<name>/SKILL.md)")
+	_ = fs.Parse(args)
+
+	if strings.TrimSpace(*input) == "" {
+		fmt.Fprintln(os.Stderr, "batch: --input is required")
+		return 2
+	}
+	switch *hypothesis {
+	case verify.HypothesisReal, verify.HypothesisFalsePositive, verify.HypothesisBoth:
+	default:
+		fmt.Fprintf(os.Stderr, "batch: unknown --hypothesis %q (want real, false-positive or both)\n", *hypothesis)
+		return 2
+	}
+	chain, err := verify.ParseTaskChain(*chainTasks)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "batch: --chain-tasks: %v\n", err)
+		return 2
+	}
+	if err := verify.ConfigurePrompts(*promptDir); err != nil {
+		fmt.Fprintf(os.Stderr, "batch: prompt template error: %v\n", err)
+		return 1
+	}
+	selected := skills.ParseList(*skillNames)
+	if _, err := skills.Load(*skillsDir, selected); err != nil {
+		fmt.Fprintf(os.Stderr, "batch: skills error: %v\n", err)
+		return 1
+	}
+	records, err := batch.LoadRecords(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "batch: %v\n", err)
+		return 1
+	}
+
+	conf, err := cfg.FromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "batch: configuration error: %v\n", err)
+		return 1
+	}
+	if *project != "" {
+		conf.ProjectName = *project
+	}
+	for _, rec := range records {
+		if rec.ParentBranchID == "" && strings.TrimSpace(*parent) == "" {
+			fmt.Fprintf(os.Stderr, "batch: bug %s has no parent_branch_id and --parent-branch-id is not set\n", rec.ID)
+			return 2
+		}
+		if rec.ProjectName == "" && conf.ProjectName == "" {
+			fmt.Fprintf(os.Stderr, "batch: bug %s needs project_name, PROJECT_NAME or --project-name\n", rec.ID)
+			return 2
+		}
+	}
+	agents, err := tools.LoadAgentRegistry(conf.AgentRegistryFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "batch: agent registry error: %v\n", err)
+		return 1
+	}
+
+	var out io.Writer = os.Stdout
+	if *output == "-" {
+		// Progress logs go to stdout; keep it for the JSONL outcomes.
+		logx.SetLevel(logx.Error)
+	} else {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "batch: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	// Each bug gets its own brain and tool handler so branch lineage is
+	// tracked per bug.
+	run := func(rec batch.Record) (*verify.Result, error) {
+		recConf := conf
+		if rec.ProjectName != "" {
+			recConf.ProjectName = rec.ProjectName
+		}
+		parentBranch := rec.ParentBranchID
+		if parentBranch == "" {
+			parentBranch = strings.TrimSpace(*parent)
+		}
+		hyp := rec.Hypothesis
+		if hyp == "" {
+			hyp = *hypothesis
+		}
+		skillSet, err := skills.Load(*skillsDir, selected)
+		if err != nil {
+			return nil, err
+		}
+		brain := b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
+		handler := tools.NewToolHandlerWithConfig(tools.NewMCPClient(conf.MCPBaseURL, *explorationID), &recConf, parentBranch)
+		handler.SetAgentRegistry(agents)
+		runner, err := verify.NewRunner(brain, handler, nil, verify.Options{
+			BugDescription: rec.Description,
+			ProjectName:    recConf.ProjectName,
+			ParentBranchID: parentBranch,
+			WorkspaceDir:   recConf.WorkspaceDir,
+			CodeContext:    rec.CodeContext,
+			Hypothesis:     hyp,
+			Skills:         skillSet,
+			Chain:          chain,
+			Bisect:         *bisect,
+			Fix:            *fix,
+			FixAgent:       *fixAgent,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return runner.Run()
+	}
+
+	enc := json.NewEncoder(out)
+	var writeErr error
+	queue := batch.Batch{Run: run, Concurrency: *concurrency}
+	outcomes, summary, err := queue.Execute(records, func(o batch.Outcome) {
+		if writeErr == nil {
+			writeErr = enc.Encode(o)
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "batch: %v\n", err)
+		return 1
+	}
+	if writeErr != nil {
+		fmt.Fprintf(os.Stderr, "batch: write outcomes: %v\n", writeErr)
+		return 1
+	}
+	batch.WriteTable(os.Stderr, outcomes, summary)
+	if summary.Errors == summary.Total {
+		return 1
+	}
+	return 0
+}
@@ -10,25 +10,78 @@ import (
 
 	b "verify_agent/internal/brain"
 	cfg "verify_agent/internal/config"
+	"verify_agent/internal/export"
 	"verify_agent/internal/logx"
+	"verify_agent/internal/skills"
 	"verify_agent/internal/streaming"
 	"verify_agent/internal/tools"
 	"verify_agent/internal/verify"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "batch" {
+		os.Exit(runBatch(os.Args[2:]))
+	}
+
 	bugDesc := flag.String("bug", "", "Bug description to verify")
 	parent := flag.String("parent-branch-id", "", "Branch UUID to fork from (required)")
 	project := flag.String("project-name", "", "Override project name")
 	headless := flag.Bool("headless", false, "Headless mode (no interactive prompt)")
 	streamJSON := flag.Bool("stream-json", false, "Emit workflow events as NDJSON (implies headless)")
+	streamSinks := flag.String("stream-sinks", "", "JSON file of NDJSON event sinks (stdout, file, unix, webhook), each with its own event filter")
 	codeContext := flag.String("code-context", "", "Optional: additional code context")
 	isFalsePositive := flag.Bool("false-positive", false, "Treat bug as false positive (虚假报警) - agent will try to refute it")
+	hypothesis := flag.String("hypothesis", "", "Hypothesis to verify from: real, false-positive or both (runs both in parallel and reconciles them); defaults from --false-positive")
+	chainTasks := flag.String("chain-tasks", "none", "Tasks that fork from the previous task's branch instead of the parent: none, all, or a list such as 3 or 2,3")
+	bisect := flag.Bool("bisect", false, "When a test confirms the bug, git bisect the history with it to find the commit that introduced the bug")
+	bisectGood := flag.String("bisect-good", "", "Revision known to pass the reproduction test (default: let the agent search for one)")
+	fix := flag.Bool("fix", false, "When a test confirms the bug, ask a builder agent for a minimal fix that makes the test pass (Task 4)")
+	fixAgent := flag.String("fix-agent", "codex", "Builder agent that writes the --fix patch")
 	explorationID := flag.String("exploration-id", "", "Optional exploration id for MCP headers")
+	outputFormat := flag.String("output-format", "json", "Result format written to --output-file: json, sarif, junit or patch (the reproduction test and any verified --fix as a git patch)")
+	outputFile := flag.String("output-file", "", "Write the result in --output-format to this file (\"-\" for stdout)")
+	promptDir := flag.String("prompt-dir", "", "Directory of *.tmpl files overriding the embedded prompt templates")
+	skillNames := flag.String("skills", "", "Comma-separated skill packs to inject into the task prompts that declare them (e.g. re2, all or none)")
+	skillsDir := flag.String("skills-dir", skills.DefaultDir, "Directory of skill packs (<name>/SKILL.md)")
 	flag.Parse()
 
-	streamEnabled := streamJSON != nil && *streamJSON
-	if streamEnabled {
+	if err := verify.ConfigurePrompts(*promptDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Prompt template error: %v\n", err)
+		os.Exit(1)
+	}
+
+	skillSet, err := skills.Load(*skillsDir, skills.ParseList(*skillNames))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Skills error: %v\n", err)
+		os.Exit(1)
+	}
+
+	chain, err := verify.ParseTaskChain(*chainTasks)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--chain-tasks: %v\n", err)
+		os.Exit(1)
+	}
+
+	format, err := export.ParseFormat(*outputFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	sinkConfigs, err := streaming.LoadSinkConfigs(*streamSinks)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "--stream-sinks: %v\n", err)
+		os.Exit(1)
+	}
+	streamEnabled := *streamJSON || len(sinkConfigs) > 0
+	// Only events on stdout force headless mode and quiet the logs; with
+	// file, socket or webhook sinks the terminal stays readable.
+	streamStdout := streaming.WritesStdout(*streamJSON, sinkConfigs)
+	if streamStdout && *outputFile == "-" {
+		fmt.Fprintln(os.Stderr, "--output-file - conflicts with streaming events on stdout")
+		os.Exit(1)
+	}
+	if streamStdout {
 		*headless = true
 		logx.SetLevel(logx.Error)
 	}
@@ -66,10 +119,21 @@ func main() {
 	brain := b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
 	mcp := tools.NewMCPClient(conf.MCPBaseURL, *explorationID)
 	handler := tools.NewToolHandlerWithConfig(mcp, &conf, *parent)
+	agents, err := tools.LoadAgentRegistry(conf.AgentRegistryFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Agent registry error: %v\n", err)
+		os.Exit(1)
+	}
+	handler.SetAgentRegistry(agents)
 
 	var streamer *streaming.JSONStreamer
 	if streamEnabled {
-		streamer = streaming.NewJSONStreamer(true, os.Stdout)
+		streamer, err = streaming.Open(*streamJSON, sinkConfigs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "--stream-sinks: %v\n", err)
+			os.Exit(1)
+		}
+		streamer.SetAgent(streaming.AgentVerify)
 		streamer.EmitThreadStarted(bug, conf.ProjectName, *parent, *headless)
 	}
 
@@ -80,6 +144,13 @@ func main() {
 		WorkspaceDir:    conf.WorkspaceDir,
 		CodeContext:     strings.TrimSpace(*codeContext),
 		IsFalsePositive: *isFalsePositive,
+		Hypothesis:      strings.TrimSpace(*hypothesis),
+		Skills:          skillSet,
+		Chain:           chain,
+		Bisect:          *bisect,
+		BisectGoodRef:   *bisectGood,
+		Fix:             *fix,
+		FixAgent:        *fixAgent,
 	}
 	runner, err := verify.NewRunner(brain, handler, streamer, opts)
 	if err != nil {
@@ -88,6 +159,7 @@ func main() {
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "init error: %v\n", err)
+		closeStreamer(streamer)
 		os.Exit(1)
 	}
 
@@ -98,33 +170,43 @@ func main() {
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "workflow error: %v\n", err)
+		closeStreamer(streamer)
 		os.Exit(1)
 	}
 
-	status := "completed"
-	if result != nil {
-		switch result.Status {
-		case "bug_wrong":
-			status = "bug_wrong"
-		case "bug_confirmed":
-			status = "bug_confirmed"
-		case "cannot_disprove":
-			status = "cannot_disprove"
-		case "error":
-			status = "error"
-		}
-		if streamer != nil && streamer.Enabled() {
-			streamer.EmitThreadCompleted(status, result.Summary, map[string]any{
-				"bug_description": result.BugDescription,
-				"status":          result.Status,
-				"summary":         result.Summary,
-				"task1_result":    result.Task1Result,
-				"task2_result":    result.Task2Result,
-				"task3_result":    result.Task3Result,
-			})
-		}
-	}
+	verify.EmitCompleted(streamer, result)
+	closeStreamer(streamer)
 
 	out, _ := json.MarshalIndent(result, "", "  ")
 	fmt.Fprintln(os.Stderr, string(out))
+	if *outputFile != "" && result != nil {
+		if err := writeOutput(*outputFile, format, result, conf.WorkspaceDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Output error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// closeStreamer flushes the stream sinks and reports events they dropped.
+func closeStreamer(streamer *streaming.JSONStreamer) {
+	if err := streamer.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Stream sinks: %v\n", err)
+	}
+}
+
+// writeOutput writes result to path ("-" for stdout) in format.
+func writeOutput(path string, format export.Format, result *verify.Result, workspaceDir string) error {
+	results := []*verify.Result{result}
+	if path == "-" {
+		return export.WriteVerify(os.Stdout, format, results, workspaceDir)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := export.WriteVerify(f, format, results, workspaceDir); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
@@ -0,0 +1,283 @@
+// Package batch verifies a queue of bug reports, such as a static-analysis or
+// fuzzer triage export, with bounded concurrency.
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"verify_agent/internal/verify"
+)
+
+const statusError = "error"
+
+// Record is one bug report to verify.
+type Record struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	CodeContext string `json:"code_context,omitempty"`
+	// Hypothesis is real, false-positive or both; empty uses the batch
+	// default.
+	Hypothesis string `json:"hypothesis,omitempty"`
+	// ParentBranchID and ProjectName override the batch defaults.
+	ParentBranchID string `json:"parent_branch_id,omitempty"`
+	ProjectName    string `json:"project_name,omitempty"`
+}
+
+// csvColumns are the header names a CSV queue may use.
+var csvColumns = map[string]bool{
+	"id": true, "description": true, "code_context": true, "hypothesis": true,
+	"parent_branch_id": true, "project_name": true,
+}
+
+// LoadRecords reads a queue file: CSV with a header row when the extension is
+// .csv, JSONL (one Record per line) otherwise.
+func LoadRecords(path string) ([]Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read bug queue: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return ParseCSV(data)
+	}
+	return ParseJSONL(data)
+}
+
+// ParseJSONL parses one Record per line. Blank lines and lines starting with
+// '#' are ignored.
+func ParseJSONL(data []byte) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, fmt.Errorf("bug queue line %d: %w", lineNo, err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read bug queue: %w", err)
+	}
+	return normalize(records)
+}
+
+// ParseCSV parses a CSV queue whose header names the Record fields by their
+// JSON keys; description is required.
+func ParseCSV(data []byte) ([]Record, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("bug queue header: %w", err)
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !csvColumns[name] {
+			return nil, fmt.Errorf("bug queue header: unknown column %q", name)
+		}
+		index[name] = i
+	}
+	if _, ok := index["description"]; !ok {
+		return nil, errors.New("bug queue header: description column is required")
+	}
+	var records []Record
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bug queue: %w", err)
+		}
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+		records = append(records, Record{
+			ID:             field("id"),
+			Description:    field("description"),
+			CodeContext:    field("code_context"),
+			Hypothesis:     field("hypothesis"),
+			ParentBranchID: field("parent_branch_id"),
+			ProjectName:    field("project_name"),
+		})
+	}
+	return normalize(records)
+}
+
+// normalize trims fields, fills in default IDs and rejects records without a
+// description, duplicate IDs and unknown hypotheses.
+func normalize(records []Record) ([]Record, error) {
+	seen := map[string]bool{}
+	for i := range records {
+		rec := &records[i]
+		rec.ID = strings.TrimSpace(rec.ID)
+		rec.Description = strings.TrimSpace(rec.Description)
+		rec.CodeContext = strings.TrimSpace(rec.CodeContext)
+		rec.Hypothesis = strings.ToLower(strings.TrimSpace(rec.Hypothesis))
+		rec.ParentBranchID = strings.TrimSpace(rec.ParentBranchID)
+		rec.ProjectName = strings.TrimSpace(rec.ProjectName)
+		if rec.ID == "" {
+			rec.ID = fmt.Sprintf("bug-%03d", i+1)
+		}
+		if seen[rec.ID] {
+			return nil, fmt.Errorf("bug %s: duplicate id", rec.ID)
+		}
+		seen[rec.ID] = true
+		if rec.Description == "" {
+			return nil, fmt.Errorf("bug %s: description is required", rec.ID)
+		}
+		switch rec.Hypothesis {
+		case "", verify.HypothesisReal, verify.HypothesisFalsePositive, verify.HypothesisBoth:
+		default:
+			return nil, fmt.Errorf("bug %s: unknown hypothesis %q (expected real, false-positive or both)", rec.ID, rec.Hypothesis)
+		}
+	}
+	if len(records) == 0 {
+		return nil, errors.New("bug queue is empty")
+	}
+	return records, nil
+}
+
+// RunFunc verifies one record.
+type RunFunc func(rec Record) (*verify.Result, error)
+
+// Batch verifies records at most Concurrency at once.
+type Batch struct {
+	Run         RunFunc
+	Concurrency int
+}
+
+// Outcome is the verification of one record, written as one JSONL line.
+type Outcome struct {
+	ID             string         `json:"id"`
+	Status         string         `json:"status"`
+	Error          string         `json:"error,omitempty"`
+	LatencySeconds float64        `json:"latency_seconds"`
+	Result         *verify.Result `json:"result,omitempty"`
+}
+
+// Summary counts outcomes by status.
+type Summary struct {
+	Total          int `json:"total"`
+	Confirmed      int `json:"bug_confirmed"`
+	Wrong          int `json:"bug_wrong"`
+	CannotDisprove int `json:"cannot_disprove"`
+	Errors         int `json:"errors"`
+}
+
+// Execute verifies every record and returns the outcomes in queue order.
+// done, when set, is called once per record as it finishes, one call at a
+// time, so results can be streamed out before the batch completes.
+func (b Batch) Execute(records []Record, done func(Outcome)) ([]Outcome, Summary, error) {
+	if b.Run == nil {
+		return nil, Summary{}, errors.New("batch requires a RunFunc")
+	}
+	limit := b.Concurrency
+	if limit <= 0 {
+		limit = 1
+	}
+	outcomes := make([]Outcome, len(records))
+	sem := make(chan struct{}, limit)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, rec := range records {
+		wg.Add(1)
+		go func(i int, rec Record) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			out := b.verify(rec)
+			mu.Lock()
+			defer mu.Unlock()
+			outcomes[i] = out
+			if done != nil {
+				done(out)
+			}
+		}(i, rec)
+	}
+	wg.Wait()
+	return outcomes, Summarize(outcomes), nil
+}
+
+func (b Batch) verify(rec Record) Outcome {
+	out := Outcome{ID: rec.ID}
+	start := time.Now()
+	result, err := b.Run(rec)
+	out.LatencySeconds = time.Since(start).Seconds()
+	switch {
+	case err != nil:
+		out.Status, out.Error = statusError, err.Error()
+	case result == nil:
+		out.Status, out.Error = statusError, "verification returned no result"
+	default:
+		out.Status, out.Result = result.Status, result
+	}
+	return out
+}
+
+// Summarize counts outcomes by status.
+func Summarize(outcomes []Outcome) Summary {
+	s := Summary{Total: len(outcomes)}
+	for _, o := range outcomes {
+		switch o.Status {
+		case "bug_confirmed":
+			s.Confirmed++
+		case "bug_wrong":
+			s.Wrong++
+		case "cannot_disprove":
+			s.CannotDisprove++
+		default:
+			s.Errors++
+		}
+	}
+	return s
+}
+
+// WriteTable prints one row per outcome followed by the status counts.
+func WriteTable(w io.Writer, outcomes []Outcome, summary Summary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BUG\tSTATUS\tCONFIDENCE\tLATENCY")
+	for _, o := range outcomes {
+		status, confidence := o.Status, "-"
+		if o.Error != "" {
+			status = "error: " + truncate(o.Error, 40)
+		}
+		if o.Result != nil && o.Result.Confidence != "" {
+			confidence = o.Result.Confidence
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.1fs\n", o.ID, status, confidence, o.LatencySeconds)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\n%d bug(s): %d bug_confirmed, %d bug_wrong, %d cannot_disprove, %d error\n",
+		summary.Total, summary.Confirmed, summary.Wrong, summary.CannotDisprove, summary.Errors)
+}
+
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
@@ -0,0 +1,85 @@
+package batch
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"verify_agent/internal/verify"
+)
+
+func TestParseJSONLFillsIDsAndValidates(t *testing.T) {
+	records, err := ParseJSONL([]byte("# fuzzer export\n{\"description\": \" nil deref in Parse \", \"hypothesis\": \"Both\"}\n\n{\"id\": \"cov-7\", \"description\": \"leak\", \"parent_branch_id\": \"b1\"}\n"))
+	if err != nil {
+		t.Fatalf("ParseJSONL error: %v", err)
+	}
+	if len(records) != 2 || records[0].ID != "bug-001" || records[0].Description != "nil deref in Parse" || records[0].Hypothesis != verify.HypothesisBoth || records[1].ParentBranchID != "b1" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+	if _, err := ParseJSONL([]byte(`{"description": "x", "hypothesis": "maybe"}`)); err == nil || !strings.Contains(err.Error(), "unknown hypothesis") {
+		t.Fatalf("expected an unknown hypothesis error, got %v", err)
+	}
+	if _, err := ParseJSONL([]byte(`{"id": "a", "description": "x"}` + "\n" + `{"id": "a", "description": "y"}`)); err == nil {
+		t.Fatalf("expected a duplicate id error")
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	records, err := ParseCSV([]byte("id,description,hypothesis,code_context\ns1,\"overflow in a, b\",false-positive,\n,race in Cache.Get,,see lru.go\n"))
+	if err != nil {
+		t.Fatalf("ParseCSV error: %v", err)
+	}
+	if len(records) != 2 || records[0].Description != "overflow in a, b" || records[0].Hypothesis != verify.HypothesisFalsePositive || records[1].ID != "bug-002" || records[1].CodeContext != "see lru.go" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+	if _, err := ParseCSV([]byte("id,descr\n1,x\n")); err == nil || !strings.Contains(err.Error(), "unknown column") {
+		t.Fatalf("expected an unknown column error, got %v", err)
+	}
+}
+
+func TestExecuteBoundsConcurrencyAndKeepsOrder(t *testing.T) {
+	var running, peak int32
+	statuses := map[string]string{"a": "bug_confirmed", "b": "bug_wrong", "c": "cannot_disprove", "d": "bug_confirmed"}
+	run := func(rec Record) (*verify.Result, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if rec.ID == "e" {
+			return nil, errors.New("mcp unavailable")
+		}
+		return &verify.Result{Status: statuses[rec.ID]}, nil
+	}
+	var records []Record
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		records = append(records, Record{ID: id, Description: "bug " + id})
+	}
+	var streamed []string
+	outcomes, summary, err := Batch{Run: run, Concurrency: 2}.Execute(records, func(o Outcome) { streamed = append(streamed, o.ID) })
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if peak > 2 {
+		t.Fatalf("at most 2 bugs may run at once, saw %d", peak)
+	}
+	if len(streamed) != 5 || outcomes[0].ID != "a" || outcomes[4].ID != "e" || outcomes[4].Error != "mcp unavailable" {
+		t.Fatalf("unexpected outcomes %+v (streamed %v)", outcomes, streamed)
+	}
+	if summary != (Summary{Total: 5, Confirmed: 2, Wrong: 1, CannotDisprove: 1, Errors: 1}) {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+
+	var buf bytes.Buffer
+	WriteTable(&buf, outcomes, summary)
+	if !strings.Contains(buf.String(), "error: mcp unavailable") || !strings.Contains(buf.String(), "5 bug(s): 2 bug_confirmed, 1 bug_wrong, 1 cannot_disprove, 1 error") {
+		t.Fatalf("unexpected table:\n%s", buf.String())
+	}
+}
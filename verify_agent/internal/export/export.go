@@ -0,0 +1,121 @@
+// Package export renders verification results in formats CI systems consume
+// directly: SARIF 2.1.0 for code-scanning dashboards, JUnit XML for test
+// report viewers and a git patch of the reproduction tests for fix PRs.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"verify_agent/internal/verify"
+)
+
+// Format selects the output encoding of a verification result.
+type Format string
+
+const (
+	FormatJSON  Format = "json"
+	FormatSARIF Format = "sarif"
+	FormatJUnit Format = "junit"
+	FormatPatch Format = "patch"
+)
+
+const toolName = "verify-agent"
+
+var (
+	fileLineRe = regexp.MustCompile("([\\w./-]+\\.[\\w]+):(\\d+)")
+	severityRe = regexp.MustCompile(`\bP([01])\b`)
+)
+
+// ParseFormat validates a --output-format value. Empty selects JSON.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return FormatJSON, nil
+	case FormatJSON, FormatSARIF, FormatJUnit, FormatPatch:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (expected json, sarif, junit or patch)", s)
+	}
+}
+
+// WriteVerify writes results to w in the given format. sourceRoot is the
+// workspace directory agents report absolute paths under; locations are
+// made relative to it. JSON output of a single result is the bare object.
+func WriteVerify(w io.Writer, format Format, results []*verify.Result, sourceRoot string) error {
+	if len(results) == 0 {
+		return fmt.Errorf("no verification result to export")
+	}
+	switch format {
+	case FormatJSON, "":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if len(results) == 1 {
+			return enc.Encode(results[0])
+		}
+		return enc.Encode(results)
+	case FormatSARIF:
+		return writeSARIF(w, results, sourceRoot)
+	case FormatJUnit:
+		return writeJUnit(w, results)
+	case FormatPatch:
+		return writePatch(w, results, sourceRoot)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// location returns the first file:line reference of the verified bug: the
+// formalized assertion's path, the extracted claim, then the description.
+func location(result *verify.Result) (string, int, bool) {
+	var candidates []string
+	if t1 := result.Task1Result; t1 != nil {
+		if t1.FormalizedAssertion != nil {
+			candidates = append(candidates, t1.FormalizedAssertion.Path)
+		}
+		candidates = append(candidates, t1.BugClaim)
+	}
+	candidates = append(candidates, result.BugDescription)
+	for _, text := range candidates {
+		for _, m := range fileLineRe.FindAllStringSubmatch(text, -1) {
+			if line, err := strconv.Atoi(m[2]); err == nil && line > 0 {
+				return m[1], line, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// severity returns "P0" or "P1" as first stated in the bug description, or
+// "" when it names neither.
+func severity(result *verify.Result) string {
+	m := severityRe.FindStringSubmatch(strings.ToUpper(result.BugDescription))
+	if m == nil {
+		return ""
+	}
+	return "P" + m[1]
+}
+
+// relativePath strips sourceRoot from an agent-reported path so it matches
+// the repository layout.
+func relativePath(p, sourceRoot string) string {
+	p = path.Clean(strings.TrimSpace(p))
+	root := strings.TrimRight(path.Clean(strings.TrimSpace(sourceRoot)), "/")
+	if root != "" && root != "." && strings.HasPrefix(p, root+"/") {
+		p = p[len(root)+1:]
+	}
+	return strings.TrimPrefix(p, "./")
+}
+
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	return strings.TrimSpace(text)
+}
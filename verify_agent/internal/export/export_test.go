@@ -0,0 +1,172 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"verify_agent/internal/verify"
+)
+
+func confirmedResult() *verify.Result {
+	return &verify.Result{
+		BugDescription: "P0: nil map write in Store.Put",
+		Status:         "bug_confirmed",
+		Summary:        "Bug claim CONFIRMED as REAL: test panics",
+		Task1Result: &verify.Task1Result{
+			Status:              "VALID",
+			FormalizedAssertion: &verify.FormalizedAssertion{Path: "Put at /workspace/pkg/store.go:12 writes s.m"},
+		},
+		Task2Result: &verify.Task2Result{Status: "REACHABLE", Judgment: "reachable from the handler"},
+		Task3Result: &verify.Task3Result{Status: "BUG_CONFIRMED", Judgment: "test panics"},
+	}
+}
+
+func refutedResult() *verify.Result {
+	return &verify.Result{
+		BugDescription: "race in cache/lru.go:40",
+		Status:         "bug_wrong",
+		Summary:        "Bug state is unreachable",
+		Task1Result:    &verify.Task1Result{Status: "VALID"},
+		Task2Result:    &verify.Task2Result{Status: "UNREACHABLE"},
+	}
+}
+
+func TestWriteVerifySARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteVerify(&buf, FormatSARIF, []*verify.Result{confirmedResult(), refutedResult()}, "/workspace"); err != nil {
+		t.Fatalf("WriteVerify returned error: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v\n%s", err, buf.String())
+	}
+	results := log.Runs[0].Results
+	if log.Version != "2.1.0" || len(results) != 2 {
+		t.Fatalf("unexpected SARIF log %+v", log)
+	}
+	loc := results[0].Locations[0].PhysicalLocation
+	if results[0].RuleID != "P0" || results[0].Level != "error" || loc.ArtifactLocation.URI != "pkg/store.go" || loc.Region.StartLine != 12 {
+		t.Fatalf("unexpected confirmed result %+v", results[0])
+	}
+	if results[1].Kind != "pass" || results[1].RuleID != "P1" || results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI != "cache/lru.go" {
+		t.Fatalf("unexpected refuted result %+v", results[1])
+	}
+}
+
+func TestWriteVerifyJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteVerify(&buf, FormatJUnit, []*verify.Result{confirmedResult(), refutedResult()}, ""); err != nil {
+		t.Fatalf("WriteVerify returned error: %v", err)
+	}
+	var doc junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JUnit XML: %v\n%s", err, buf.String())
+	}
+	if doc.Tests != 6 || doc.Failures != 2 || doc.Skipped != 1 || len(doc.Suites) != 2 {
+		t.Fatalf("unexpected totals %+v", doc)
+	}
+	confirmed := doc.Suites[0].Cases
+	if confirmed[0].Failure != nil || confirmed[1].Failure == nil || confirmed[2].Failure == nil {
+		t.Fatalf("expected reachability and test generation to fail, got %+v", confirmed)
+	}
+	if doc.Suites[1].Cases[2].Skipped == nil {
+		t.Fatalf("a task that did not run must be skipped, got %+v", doc.Suites[1].Cases[2])
+	}
+}
+
+func TestWriteVerifyJUnitReportsProtocolErrors(t *testing.T) {
+	result := refutedResult()
+	result.Status = "protocol_error"
+	result.Task2Result = &verify.Task2Result{Status: "PROTOCOL_ERROR", ProtocolError: "result file .verify_agent/task2_result.json unavailable"}
+	var buf bytes.Buffer
+	if err := WriteVerify(&buf, FormatJUnit, []*verify.Result{result}, ""); err != nil {
+		t.Fatalf("WriteVerify returned error: %v", err)
+	}
+	var doc junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JUnit XML: %v\n%s", err, buf.String())
+	}
+	if e := doc.Suites[0].Cases[1].Error; doc.Errors != 1 || e == nil || e.Message != "protocol error" {
+		t.Fatalf("a task without a valid result file must be an error, got %+v", doc.Suites[0].Cases[1])
+	}
+}
+
+func TestWriteVerifyJSONKeepsSingleResultShape(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteVerify(&buf, FormatJSON, []*verify.Result{refutedResult()}, ""); err != nil {
+		t.Fatalf("WriteVerify returned error: %v", err)
+	}
+	var got verify.Result
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || got.Status != "bug_wrong" {
+		t.Fatalf("expected a bare result object, got %q (%v)", buf.String(), err)
+	}
+}
+
+func TestWriteVerifyPatchAddsReproductionTest(t *testing.T) {
+	withTest := confirmedResult()
+	exit := 101
+	withTest.Task3Result.Artifact = &verify.TestArtifact{Path: "/workspace/pkg/store_repro_test.go", Content: "package pkg\n\nfunc TestRepro(t *testing.T) {}", Command: "go test ./pkg -run TestRepro", ExitCode: &exit}
+	var buf bytes.Buffer
+	if err := WriteVerify(&buf, FormatPatch, []*verify.Result{withTest, refutedResult()}, "/workspace"); err != nil {
+		t.Fatalf("WriteVerify returned error: %v", err)
+	}
+	want := "diff --git a/pkg/store_repro_test.go b/pkg/store_repro_test.go\nnew file mode 100644\n--- /dev/null\n+++ b/pkg/store_repro_test.go\n@@ -0,0 +1,3 @@\n+package pkg\n+\n+func TestRepro(t *testing.T) {}\n\\ No newline at end of file\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected patch:\n%s", buf.String())
+	}
+	if err := WriteVerify(&buf, FormatPatch, []*verify.Result{refutedResult()}, ""); err == nil {
+		t.Fatalf("expected an error when no result carries a reproduction test")
+	}
+}
+
+func TestWriteVerifyPatchExtendsExistingTestFile(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "src", "lib.rs"), []byte("pub fn get() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result := confirmedResult()
+	result.Task3Result.Artifact = &verify.TestArtifact{Path: "src/lib.rs", Content: "pub fn get() {}\n\n#[cfg(test)]\nmod repro {}\n"}
+
+	var buf bytes.Buffer
+	if err := WriteVerify(&buf, FormatPatch, []*verify.Result{result}, root); err == nil || !strings.Contains(err.Error(), "recorded no diff") {
+		t.Fatalf("an existing file must not be exported as a new file: %q (%v)", buf.String(), err)
+	}
+
+	diff := "diff --git a/src/lib.rs b/src/lib.rs\n--- a/src/lib.rs\n+++ b/src/lib.rs\n@@ -1 +1,4 @@\n pub fn get() {}\n+\n+#[cfg(test)]\n+mod repro {}\n"
+	result.Task3Result.Artifact.Diff = diff
+	buf.Reset()
+	if err := WriteVerify(&buf, FormatPatch, []*verify.Result{result}, root); err != nil {
+		t.Fatalf("WriteVerify returned error: %v", err)
+	}
+	if buf.String() != diff || strings.Contains(buf.String(), "new file mode") {
+		t.Fatalf("expected the recorded diff, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteVerifyPatchAppendsVerifiedFix(t *testing.T) {
+	withFix := confirmedResult()
+	withFix.Task3Result.Artifact = &verify.TestArtifact{Path: "pkg/store_repro_test.go", Content: "package pkg\n"}
+	fixDiff := "diff --git a/pkg/store.go b/pkg/store.go\n--- a/pkg/store.go\n+++ b/pkg/store.go\n@@ -10,1 +10,2 @@\n+\tif s.m == nil {\n"
+	withFix.Fix = &verify.FixResult{Status: "fixed", Diff: fixDiff}
+	var buf bytes.Buffer
+	if err := WriteVerify(&buf, FormatPatch, []*verify.Result{withFix}, ""); err != nil {
+		t.Fatalf("WriteVerify returned error: %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "+package pkg\n"+fixDiff) {
+		t.Fatalf("the fix must follow its reproduction test:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	withFix.Fix.Status = "not_fixed"
+	if err := WriteVerify(&buf, FormatPatch, []*verify.Result{withFix}, ""); err != nil || strings.Contains(buf.String(), "pkg/store.go") {
+		t.Fatalf("an unverified fix must not be exported: %q (%v)", buf.String(), err)
+	}
+}
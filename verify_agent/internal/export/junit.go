@@ -0,0 +1,148 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"verify_agent/internal/verify"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitOutcome `xml:"failure,omitempty"`
+	Error     *junitOutcome `xml:"error,omitempty"`
+	Skipped   *junitOutcome `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitOutcome struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// taskCase is one verification task as seen by the JUnit exporter.
+type taskCase struct {
+	name     string
+	ran      bool
+	status   string
+	judgment string
+	response string
+	// protocolError explains a PROTOCOL_ERROR status.
+	protocolError string
+	// failing lists the task statuses that are evidence for the bug.
+	failing []string
+	// known lists every status the task can report.
+	known []string
+}
+
+// writeJUnit emits one testsuite per verified bug with one testcase per
+// verification task. A task fails when its verdict supports the bug, is
+// skipped when it did not run or was inconclusive, and errors when it
+// left no valid result file or reported no recognizable verdict.
+func writeJUnit(w io.Writer, results []*verify.Result) error {
+	doc := junitSuites{Name: toolName}
+	for n, result := range results {
+		suite := junitSuite{
+			Name: fmt.Sprintf("bug %d: %s", n+1, firstLine(result.BugDescription)),
+			Properties: []junitProperty{
+				{Name: "status", Value: result.Status},
+				{Name: "summary", Value: result.Summary},
+			},
+		}
+		for _, task := range taskCases(result) {
+			tc := junitCase{Name: task.name, ClassName: toolName + "." + task.name, SystemOut: strings.TrimSpace(task.response)}
+			switch {
+			case !task.ran:
+				tc.Skipped = &junitOutcome{Message: "not run: verification stopped at an earlier task"}
+				suite.Skipped++
+			case containsStatus(task.failing, task.status):
+				tc.Failure = &junitOutcome{Message: task.status, Type: task.status, Body: strings.TrimSpace(task.judgment)}
+				suite.Failures++
+			case task.status == "PROTOCOL_ERROR":
+				tc.Error = &junitOutcome{Message: "protocol error", Type: task.status, Body: task.protocolError}
+				suite.Errors++
+			case task.status == "TEST_INCONCLUSIVE":
+				tc.Skipped = &junitOutcome{Message: "test inconclusive"}
+				suite.Skipped++
+			case !containsStatus(task.known, task.status):
+				tc.Error = &junitOutcome{Message: fmt.Sprintf("unrecognized status %q", task.status)}
+				suite.Errors++
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		suite.Tests = len(suite.Cases)
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Errors += suite.Errors
+		doc.Skipped += suite.Skipped
+		doc.Suites = append(doc.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func taskCases(result *verify.Result) []taskCase {
+	task1 := taskCase{name: "task1_formalization", known: []string{"VALID", "INVALID"}}
+	if t := result.Task1Result; t != nil {
+		task1.ran, task1.status, task1.judgment, task1.response = true, t.Status, t.Judgment, t.Response
+		task1.protocolError = t.ProtocolError
+	}
+	task2 := taskCase{name: "task2_reachability", failing: []string{"REACHABLE"}, known: []string{"REACHABLE", "UNREACHABLE", "INVALID"}}
+	if t := result.Task2Result; t != nil {
+		task2.ran, task2.status, task2.judgment, task2.response = true, t.Status, t.Judgment, t.Response
+		task2.protocolError = t.ProtocolError
+	}
+	task3 := taskCase{name: "task3_test_generation", failing: []string{"BUG_CONFIRMED"}, known: []string{"BUG_CONFIRMED", "BUG_REFUTED", "TEST_INCONCLUSIVE"}}
+	if t := result.Task3Result; t != nil {
+		task3.ran, task3.status, task3.judgment, task3.response = true, t.Status, t.Judgment, t.Response
+		task3.protocolError = t.ProtocolError
+	}
+	return []taskCase{task1, task2, task3}
+}
+
+func containsStatus(statuses []string, status string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
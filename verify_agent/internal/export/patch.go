@@ -0,0 +1,82 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"verify_agent/internal/verify"
+)
+
+// writePatch writes the reproduction tests of results as a git patch, ready
+// to `git apply` onto the bug's fix branch. Each test is exported as the diff
+// its branch recorded; only a test file missing from sourceRoot falls back
+// to a new-file diff built from its content. A verified Task 4 fix follows
+// its test, so the patch carries the whole change. Results without a test
+// artifact are skipped.
+func writePatch(w io.Writer, results []*verify.Result, sourceRoot string) error {
+	written := 0
+	seen := map[string]bool{}
+	for _, result := range results {
+		if result == nil || result.Task3Result == nil || result.Task3Result.Artifact == nil {
+			continue
+		}
+		artifact := result.Task3Result.Artifact
+		p := relativePath(artifact.Path, sourceRoot)
+		if p == "" || p == "." || strings.HasPrefix(p, "../") || strings.HasPrefix(p, "/") {
+			return fmt.Errorf("reproduction test path %q is outside the workspace", artifact.Path)
+		}
+		if seen[p] {
+			return fmt.Errorf("two reproduction tests write %s", p)
+		}
+		seen[p] = true
+		if err := writeTestDiff(w, p, artifact, sourceRoot); err != nil {
+			return err
+		}
+		if fix := result.Fix; fix != nil && fix.Status == "fixed" && fix.Diff != "" {
+			if _, err := io.WriteString(w, strings.TrimRight(fix.Diff, "\n")+"\n"); err != nil {
+				return err
+			}
+		}
+		written++
+	}
+	if written == 0 {
+		return fmt.Errorf("no reproduction test artifact to export")
+	}
+	return nil
+}
+
+// writeTestDiff writes the diff adding artifact's test at p.
+func writeTestDiff(w io.Writer, p string, artifact *verify.TestArtifact, sourceRoot string) error {
+	if diff := strings.TrimSpace(artifact.Diff); diff != "" {
+		_, err := io.WriteString(w, diff+"\n")
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(sourceRoot, filepath.FromSlash(p))); err == nil {
+		return fmt.Errorf("reproduction test %s extends an existing file but its branch recorded no diff", p)
+	}
+	return writeNewFileDiff(w, p, artifact.Content)
+}
+
+// writeNewFileDiff writes a git diff creating p with content.
+func writeNewFileDiff(w io.Writer, p, content string) error {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "diff --git a/%s b/%s\nnew file mode 100644\n--- /dev/null\n+++ b/%s\n", p, p, p)
+	if len(lines) > 0 {
+		fmt.Fprintf(&sb, "@@ -0,0 +1,%d @@\n", len(lines))
+		for _, line := range lines {
+			sb.WriteString("+" + line)
+		}
+		if !strings.HasSuffix(content, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,170 @@
+package export
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"verify_agent/internal/verify"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	DefaultConfig    sarifConfig  `json:"defaultConfiguration"`
+}
+
+type sarifConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Kind                string            `json:"kind,omitempty"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifRules maps bug severities to rules. Bugs whose description names no
+// severity are reported under P1.
+var sarifRules = []sarifRule{
+	{ID: "P0", ShortDescription: sarifMessage{Text: "P0 bug: crash, data loss or security"}, DefaultConfig: sarifConfig{Level: "error"}},
+	{ID: "P1", ShortDescription: sarifMessage{Text: "P1 bug: correctness or regression"}, DefaultConfig: sarifConfig{Level: "warning"}},
+}
+
+// writeSARIF emits one SARIF result per verified bug. Confirmed bugs carry
+// their severity's level, bugs that could not be disproved are warnings,
+// refuted claims are passes and failed runs are notes.
+func writeSARIF(w io.Writer, results []*verify.Result, sourceRoot string) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: toolName, Rules: sarifRules}},
+		Results: []sarifResult{},
+	}
+	for _, result := range results {
+		run.Results = append(run.Results, sarifVerification(result, sourceRoot))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+func sarifVerification(result *verify.Result, sourceRoot string) sarifResult {
+	rule := severity(result)
+	if rule == "" {
+		rule = "P1"
+	}
+	level := "warning"
+	if rule == "P0" {
+		level = "error"
+	}
+
+	text := strings.TrimSpace(result.BugDescription)
+	if summary := strings.TrimSpace(result.Summary); summary != "" {
+		text += "\n\nVerdict: " + summary
+	}
+	res := sarifResult{
+		RuleID:  rule,
+		Level:   level,
+		Message: sarifMessage{Text: text},
+		PartialFingerprints: map[string]string{
+			"verifyAgentBug/v1": fingerprint(result.BugDescription),
+		},
+		Properties: map[string]string{"status": result.Status},
+	}
+	if summary := strings.TrimSpace(result.Summary); summary != "" {
+		res.Properties["verdictExplanation"] = summary
+	}
+	if result.Confidence != "" {
+		res.Properties["confidence"] = result.Confidence
+	}
+	if b := result.Bisect; b != nil && b.Status == "found" {
+		res.Properties["firstBadCommit"] = b.FirstBadCommit
+		if b.PullRequest != "" {
+			res.Properties["introducedBy"] = b.PullRequest
+		}
+	}
+
+	switch result.Status {
+	case "bug_confirmed":
+	case "cannot_disprove":
+		res.Level = "warning"
+	case "bug_wrong":
+		res.Kind = "pass"
+		res.Level = "none"
+	default:
+		res.Level = "note"
+	}
+
+	if file, line, ok := location(result); ok {
+		uri := relativePath(file, sourceRoot)
+		artifact := sarifArtifactLocation{URI: uri}
+		if !strings.HasPrefix(uri, "/") {
+			artifact.URIBaseID = "%SRCROOT%"
+		} else {
+			artifact.URI = "file://" + uri
+		}
+		res.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: artifact,
+			Region:           sarifRegion{StartLine: line},
+		}}}
+	}
+	return res
+}
+
+// fingerprint identifies a bug across runs by its headline.
+func fingerprint(description string) string {
+	sum := sha1.Sum([]byte(strings.ToLower(firstLine(description))))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,190 @@
+package streaming
+
+import (
+	_ "embed"
+	"encoding/json"
+)
+
+// SchemaVersion is the version of the event format, carried in every
+// envelope. A minor bump only adds optional fields or event types; a major
+// bump changes or removes existing ones.
+const SchemaVersion = "1.0"
+
+// Schema is the JSON Schema (draft 2020-12) of one NDJSON event line.
+//
+//go:embed events.schema.json
+var Schema []byte
+
+// Event types.
+const (
+	EventThreadStarted    = "thread.started"
+	EventTurnStarted      = "turn.started"
+	EventAssistantMessage = "assistant.message"
+	EventTurnCompleted    = "turn.completed"
+	EventItemStarted      = "item.started"
+	EventItemCompleted    = "item.completed"
+	EventThreadCompleted  = "thread.completed"
+	EventError            = "error"
+)
+
+// Agents that emit events, as named in the envelope. The agent decides the
+// shape of thread.completed's final_report.
+const (
+	AgentDev    = "dev-agent"
+	AgentDevV2  = "dev-agent-v2"
+	AgentReview = "review-agent"
+	AgentVerify = "verify-agent"
+	// AgentLogicAnalyst is review_agent's standalone verify-agent, which runs
+	// a single logic analysis rather than the formal verification workflow.
+	AgentLogicAnalyst = "logic-analyst"
+)
+
+// Envelope holds the fields every event carries.
+type Envelope struct {
+	Type          string `json:"type"`
+	SchemaVersion string `json:"schema_version"`
+	// Timestamp is RFC 3339 UTC with nanoseconds.
+	Timestamp string `json:"timestamp"`
+	// Sequence increases by one per event of the run, so a consumer behind
+	// a filtered sink can tell that events were skipped.
+	Sequence int64  `json:"sequence"`
+	ThreadID string `json:"thread_id"`
+	Agent    string `json:"agent,omitempty"`
+}
+
+// Header returns the envelope; it makes every event type an Event.
+func (e *Envelope) Header() *Envelope { return e }
+
+// Event is one typed event line.
+type Event interface {
+	Header() *Envelope
+}
+
+// ThreadStarted is emitted once the CLI resolved its inputs.
+type ThreadStarted struct {
+	Envelope
+	Task           string `json:"task"`
+	ProjectName    string `json:"project_name"`
+	ParentBranchID string `json:"parent_branch_id"`
+	Headless       bool   `json:"headless"`
+}
+
+// TurnStarted is emitted before each LLM call.
+type TurnStarted struct {
+	Envelope
+	TurnID       string `json:"turn_id"`
+	Iteration    int    `json:"iteration"`
+	MessageCount int    `json:"message_count"`
+	ToolCount    int    `json:"tool_count"`
+}
+
+// AssistantMessage previews an LLM reply.
+type AssistantMessage struct {
+	Envelope
+	TurnID        string `json:"turn_id"`
+	Preview       string `json:"preview"`
+	ToolCallCount int    `json:"tool_call_count"`
+	Truncated     bool   `json:"truncated,omitempty"`
+}
+
+// TurnCompleted is emitted after a turn handled its tool calls.
+type TurnCompleted struct {
+	Envelope
+	TurnID         string `json:"turn_id"`
+	Iteration      int    `json:"iteration"`
+	ToolCallCount  int    `json:"tool_call_count"`
+	HasFinalReport bool   `json:"has_final_report"`
+}
+
+// ItemStarted is emitted before a tool call or workflow step.
+type ItemStarted struct {
+	Envelope
+	ItemID string         `json:"item_id"`
+	Kind   string         `json:"kind"`
+	Name   string         `json:"name"`
+	Args   map[string]any `json:"args"`
+}
+
+// ItemCompleted is emitted when a tool call or workflow step finished.
+type ItemCompleted struct {
+	Envelope
+	ItemID     string `json:"item_id"`
+	Status     string `json:"status"`
+	DurationMS int64  `json:"duration_ms"`
+	BranchID   string `json:"branch_id,omitempty"`
+	Summary    string `json:"summary,omitempty"`
+}
+
+// ThreadCompleted is the last event of a run. FinalReport is the agent's
+// report; see Envelope.Agent.
+type ThreadCompleted struct {
+	Envelope
+	Status      string          `json:"status"`
+	Summary     string          `json:"summary,omitempty"`
+	FinalReport json.RawMessage `json:"final_report,omitempty"`
+}
+
+// Error reports a failure; the run may continue.
+type Error struct {
+	Envelope
+	Scope       string `json:"scope"`
+	Message     string `json:"message"`
+	Iteration   int    `json:"iteration,omitempty"`
+	TurnID      string `json:"turn_id,omitempty"`
+	ItemID      string `json:"item_id,omitempty"`
+	Instruction string `json:"instruction,omitempty"`
+	// Details holds any other context the emitter attached.
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// newError builds an Error, lifting the known keys of extra into fields.
+func newError(scope, message string, extra map[string]any) *Error {
+	ev := &Error{Scope: scope, Message: message}
+	for k, v := range extra {
+		switch s, _ := v.(string); k {
+		case "iteration":
+			if n, ok := v.(int); ok {
+				ev.Iteration = n
+				continue
+			}
+		case "turn_id":
+			ev.TurnID = s
+			continue
+		case "item_id":
+			ev.ItemID = s
+			continue
+		case "instruction":
+			ev.Instruction = s
+			continue
+		}
+		if ev.Details == nil {
+			ev.Details = map[string]any{}
+		}
+		ev.Details[k] = v
+	}
+	return ev
+}
+
+// NewEvent returns an empty event of eventType to decode into, or nil for a
+// type this version does not know.
+func NewEvent(eventType string) Event {
+	switch eventType {
+	case EventThreadStarted:
+		return &ThreadStarted{}
+	case EventTurnStarted:
+		return &TurnStarted{}
+	case EventAssistantMessage:
+		return &AssistantMessage{}
+	case EventTurnCompleted:
+		return &TurnCompleted{}
+	case EventItemStarted:
+		return &ItemStarted{}
+	case EventItemCompleted:
+		return &ItemCompleted{}
+	case EventThreadCompleted:
+		return &ThreadCompleted{}
+	case EventError:
+		return &Error{}
+	}
+	return nil
+}
@@ -69,6 +69,7 @@ type ToolHandler struct {
 	defaultProj   string
 	branchTracker *BranchTracker
 	workspaceDir  string
+	agents        *AgentRegistry // nil = DefaultAgentRegistry
 }
 
 // NewToolHandler creates a handler without config. Uses hardcoded defaults.
@@ -153,14 +154,15 @@ func (h *ToolHandler) executeAgent(arguments map[string]any) (map[string]any, er
 		return nil, ToolExecutionError{Msg: "missing required arguments"}
 	}
 
-	if agent == reviewCodeAgent {
-		return h.executeReviewAgent(project, parent, prompt)
+	spec, ok := h.agentRegistry().Lookup(agent)
+	if !ok {
+		return nil, ToolExecutionError{Msg: fmt.Sprintf("unknown agent %q (registered agents: %s)", agent, strings.Join(h.agentRegistry().Names(), ", "))}
 	}
-	result, _, err := h.runAgentOnce(agent, project, parent, prompt)
-	return result, err
+	return h.executeContract(spec, project, parent, spec.ApplyPromptPrefixes(prompt))
 }
 
-func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[string]any, string, error) {
+func (h *ToolHandler) runAgentOnce(spec AgentSpec, project, parent, prompt string) (map[string]any, string, error) {
+	agent := spec.Name
 	logx.Infof("Executing agent %s on project %s from parent %s", agent, project, parent)
 	resp, err := h.client.ParallelExplore(project, parent, []string{prompt}, agent, 1)
 	if err != nil {
@@ -194,7 +196,11 @@ func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[s
 	result := map[string]any{"parallel_explore": resp, "branch_id": branchID}
 
 	logx.Infof("Waiting for branch %s to complete.", branchID)
-	statusResp, err := h.checkStatus(map[string]any{"branch_id": branchID})
+	statusArgs := map[string]any{"branch_id": branchID}
+	if spec.PollTimeout > 0 {
+		statusArgs["timeout_seconds"] = spec.PollTimeout.Seconds()
+	}
+	statusResp, err := h.checkStatus(statusArgs)
 	if err != nil {
 		// checkStatus failed - don't record this branch ID
 		if te, ok := err.(ToolExecutionError); ok {
@@ -246,36 +252,52 @@ func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[s
 	return result, branchID, nil
 }
 
-func (h *ToolHandler) executeReviewAgent(project, parent, prompt string) (map[string]any, error) {
-	artifactPath := h.reviewLogPath()
-	if artifactPath == "" {
-		return nil, ToolExecutionError{Msg: "workspace directory not configured for review_code validation"}
+// executeContract runs the agent and enforces its artifact contract: while a
+// required artifact is missing from the new branch, the agent is re-run from
+// the same parent until its attempts are used up.
+func (h *ToolHandler) executeContract(spec AgentSpec, project, parent, prompt string) (map[string]any, error) {
+	paths := make([]string, len(spec.RequiredArtifacts))
+	for i, artifact := range spec.RequiredArtifacts {
+		if paths[i] = h.artifactPath(artifact.Path); paths[i] == "" {
+			return nil, ToolExecutionError{Msg: fmt.Sprintf("workspace directory not configured for %s validation", spec.Name)}
+		}
 	}
-	var lastBranch string
-	for attempt := 1; attempt <= reviewMaxAttempts; attempt++ {
-		result, branchID, err := h.runAgentOnce(reviewCodeAgent, project, parent, prompt)
+	attempts := spec.Attempts()
+	var lastBranch, missing string
+	for attempt := 1; attempt <= attempts; attempt++ {
+		result, branchID, err := h.runAgentOnce(spec, project, parent, prompt)
 		if err != nil {
 			return nil, err
 		}
+		result["agent_role"] = string(spec.Role)
 		lastBranch = branchID
-		if artifact, err := h.client.BranchReadFile(branchID, artifactPath); err == nil {
-			if content, ok := artifact["content"].(string); ok && strings.TrimSpace(content) != "" {
-				result["review_report"] = content
+		missing = ""
+		for i, artifact := range spec.RequiredArtifacts {
+			file, err := h.client.BranchReadFile(branchID, paths[i])
+			if err != nil {
+				if !isNotFoundError(err) {
+					return nil, err
+				}
+				missing = paths[i]
+				break
+			}
+			if content, ok := file["content"].(string); ok && artifact.ResultKey != "" && strings.TrimSpace(content) != "" {
+				result[artifact.ResultKey] = content
 			}
+		}
+		if missing == "" {
 			return result, nil
-		} else if !isNotFoundError(err) {
-			return nil, err
 		}
-		logx.Warningf("review_code attempt %d/%d did not produce %s (branch=%s)", attempt, reviewMaxAttempts, artifactPath, branchID)
+		logx.Warningf("%s attempt %d/%d did not produce %s (branch=%s)", spec.Name, attempt, attempts, missing, branchID)
 	}
 	details := map[string]any{
-		"attempts":      reviewMaxAttempts,
-		"artifact_path": artifactPath,
+		"attempts":      attempts,
+		"artifact_path": missing,
 	}
 	if lastBranch != "" {
 		details["last_branch_id"] = lastBranch
 	}
-	msg := fmt.Sprintf("review_code failed to produce %s after %d attempts", artifactPath, reviewMaxAttempts)
+	msg := fmt.Sprintf("%s failed to produce %s after %d attempts", spec.Name, missing, attempts)
 	if lastBranch != "" {
 		msg = fmt.Sprintf("%s (last_branch_id=%s). Inspect manifest %s in Pantheon.", msg, lastBranch, lastBranch)
 	}
@@ -286,11 +308,26 @@ func (h *ToolHandler) executeReviewAgent(project, parent, prompt string) (map[st
 	}
 }
 
-func (h *ToolHandler) reviewLogPath() string {
+// artifactPath resolves a contract path against the workspace directory.
+func (h *ToolHandler) artifactPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
 	if strings.TrimSpace(h.workspaceDir) == "" {
 		return ""
 	}
-	return filepath.Join(h.workspaceDir, reviewArtifactName)
+	return filepath.Join(h.workspaceDir, path)
+}
+
+// SetAgentRegistry replaces the agent contracts enforced by execute_agent;
+// nil restores the built-in agents.
+func (h *ToolHandler) SetAgentRegistry(reg *AgentRegistry) { h.agents = reg }
+
+func (h *ToolHandler) agentRegistry() *AgentRegistry {
+	if h != nil && h.agents != nil {
+		return h.agents
+	}
+	return defaultAgents
 }
 
 func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, error) {
@@ -0,0 +1,124 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"verify_agent/internal/logx"
+)
+
+// Workspace paths, relative to Options.WorkspaceDir, where the test
+// generator leaves its reproduction test manifest, run log and the git diff
+// that adds the test.
+const (
+	reproManifestPath = ".verify_agent/repro.json"
+	reproLogPath      = ".verify_agent/repro.log"
+	reproDiffPath     = ".verify_agent/repro.diff"
+)
+
+// TestArtifact is the reproduction test Task 3 left on its branch, read back
+// from the workspace rather than scraped from the agent's reply.
+type TestArtifact struct {
+	// Path is the test file as the agent reported it, relative to the
+	// workspace when possible.
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	Command string `json:"command,omitempty"`
+	// ExitCode is the exit status of Command; nil when the agent did not
+	// report one.
+	ExitCode *int   `json:"exit_code,omitempty"`
+	LogPath  string `json:"log_path,omitempty"`
+	Log      string `json:"log,omitempty"`
+	// Diff is the git diff adding the test to the project, which may extend
+	// an existing file; empty when the agent recorded none.
+	Diff string `json:"diff,omitempty"`
+}
+
+// reproManifest is the JSON the test generator writes to reproManifestPath.
+type reproManifest struct {
+	TestPath string `json:"test_path"`
+	Command  string `json:"command"`
+	ExitCode *int   `json:"exit_code"`
+}
+
+func parseReproManifest(content string) (reproManifest, error) {
+	var m reproManifest
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &m); err != nil {
+		return m, fmt.Errorf("parse %s: %w", reproManifestPath, err)
+	}
+	m.TestPath = strings.TrimSpace(m.TestPath)
+	m.Command = strings.TrimSpace(m.Command)
+	if m.TestPath == "" {
+		return m, fmt.Errorf("%s has no test_path", reproManifestPath)
+	}
+	return m, nil
+}
+
+// collectTestArtifact reads the manifest, test file, run log and diff from
+// branchID. The log and diff are optional; a missing manifest or test file is
+// an error.
+func (r *Runner) collectTestArtifact(branchID string) (*TestArtifact, error) {
+	manifestContent, err := r.readArtifact(branchID, r.workspacePath(reproManifestPath))
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := parseReproManifest(manifestContent)
+	if err != nil {
+		return nil, err
+	}
+	content, err := r.readArtifact(branchID, r.workspacePath(manifest.TestPath))
+	if err != nil {
+		return nil, err
+	}
+	artifact := &TestArtifact{
+		Path:     r.relativeToWorkspace(manifest.TestPath),
+		Content:  content,
+		Command:  manifest.Command,
+		ExitCode: manifest.ExitCode,
+	}
+	if log, err := r.readArtifact(branchID, r.workspacePath(reproLogPath)); err != nil {
+		logx.Warningf("Reproduction test run log unavailable on branch %s: %v", branchID, err)
+	} else {
+		artifact.LogPath = reproLogPath
+		artifact.Log = log
+	}
+	if diff, err := r.readArtifact(branchID, r.workspacePath(reproDiffPath)); err != nil {
+		logx.Warningf("Reproduction test diff unavailable on branch %s: %v", branchID, err)
+	} else {
+		artifact.Diff = strings.TrimSpace(diff)
+	}
+	return artifact, nil
+}
+
+func (r *Runner) readArtifact(branchID, path string) (string, error) {
+	data, err := r.callTool("read_artifact", map[string]any{"branch_id": branchID, "path": path})
+	if err != nil {
+		return "", err
+	}
+	content, ok := data["content"].(string)
+	if !ok {
+		return "", fmt.Errorf("read_artifact %s returned no content", path)
+	}
+	return content, nil
+}
+
+// workspacePath resolves a workspace-relative path.
+func (r *Runner) workspacePath(path string) string {
+	if filepath.IsAbs(path) || r.opts.WorkspaceDir == "" {
+		return path
+	}
+	return filepath.Join(r.opts.WorkspaceDir, path)
+}
+
+func (r *Runner) relativeToWorkspace(path string) string {
+	if r.opts.WorkspaceDir == "" || !filepath.IsAbs(path) {
+		return filepath.ToSlash(filepath.Clean(path))
+	}
+	rel, err := filepath.Rel(r.opts.WorkspaceDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,29 @@
+package verify
+
+import "testing"
+
+func TestParseReproManifest(t *testing.T) {
+	m, err := parseReproManifest(`{"test_path": " pkg/repro_test.go ", "command": "go test ./pkg", "exit_code": 1}`)
+	if err != nil || m.TestPath != "pkg/repro_test.go" || m.Command != "go test ./pkg" || m.ExitCode == nil || *m.ExitCode != 1 {
+		t.Fatalf("unexpected manifest %+v (%v)", m, err)
+	}
+	if m, err := parseReproManifest(`{"command": "go test"}`); err == nil {
+		t.Fatalf("a manifest without test_path must be rejected, got %+v", m)
+	}
+	if _, err := parseReproManifest("not json"); err == nil {
+		t.Fatalf("expected a parse error")
+	}
+}
+
+func TestArtifactPathsResolveAgainstWorkspace(t *testing.T) {
+	r := &Runner{opts: Options{WorkspaceDir: "/workspace"}}
+	if got := r.workspacePath(reproManifestPath); got != "/workspace/.verify_agent/repro.json" {
+		t.Fatalf("unexpected manifest path %q", got)
+	}
+	if got := r.relativeToWorkspace("/workspace/pkg/repro_test.go"); got != "pkg/repro_test.go" {
+		t.Fatalf("unexpected relative path %q", got)
+	}
+	if got := r.relativeToWorkspace("/elsewhere/repro_test.go"); got != "/elsewhere/repro_test.go" {
+		t.Fatalf("paths outside the workspace must stay absolute, got %q", got)
+	}
+}
@@ -0,0 +1,229 @@
+package verify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"verify_agent/internal/logx"
+)
+
+// assertionParallelism bounds how many assertions of a compound claim are
+// verified at once.
+const assertionParallelism = 3
+
+// AssertionOutcome is the verification of one assertion of a compound claim.
+type AssertionOutcome struct {
+	ID          string               `json:"id"`
+	Assertion   *FormalizedAssertion `json:"assertion"`
+	Status      string               `json:"status"`
+	Summary     string               `json:"summary"`
+	Error       string               `json:"error,omitempty"`
+	Task2Result *Task2Result         `json:"task2_result,omitempty"`
+	Task3Result *Task3Result         `json:"task3_result,omitempty"`
+	Lineage     []TaskLineage        `json:"lineage,omitempty"`
+}
+
+// runAssertions verifies every assertion of a compound claim independently
+// and aggregates the outcomes into result. It fails only when every
+// assertion failed.
+func (r *Runner) runAssertions(result *Result, task1 *Task1Result) error {
+	assertions := task1.FormalizedAssertions
+	logx.Infof("Bug claim has %d assertions; verifying them independently", len(assertions))
+	outcomes := make([]AssertionOutcome, len(assertions))
+	sem := make(chan struct{}, assertionParallelism)
+	var wg sync.WaitGroup
+	for i := range assertions {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			var lineage []TaskLineage
+			outcome, err := r.verifyAssertion(&lineage, task1, &assertions[i])
+			if err != nil {
+				outcome = &AssertionOutcome{Status: statusError, Error: err.Error(), Summary: err.Error()}
+			}
+			outcome.ID, outcome.Assertion, outcome.Lineage = assertions[i].ID, &assertions[i], lineage
+			outcomes[i] = *outcome
+		}(i)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, o := range outcomes {
+		if o.Status == statusError {
+			failed++
+		}
+	}
+	if failed == len(outcomes) {
+		return fmt.Errorf("every assertion failed: %s", outcomes[0].Error)
+	}
+	aggregateAssertions(result, outcomes)
+	return nil
+}
+
+// aggregateAssertions sets result from the per-assertion outcomes. The claim
+// is confirmed when any failure mode is, wrong when every one is, and
+// cannot_disprove otherwise. The task results are those of the first
+// assertion with the deciding status.
+func aggregateAssertions(result *Result, outcomes []AssertionOutcome) {
+	result.Assertions = outcomes
+	counts := map[string]int{}
+	for _, o := range outcomes {
+		counts[o.Status]++
+	}
+	switch {
+	case counts[statusBugConfirmed] > 0:
+		result.Status = statusBugConfirmed
+	case counts[statusBugWrong] == len(outcomes):
+		result.Status = statusBugWrong
+	case counts[statusProtocolError] == len(outcomes):
+		result.Status = statusProtocolError
+	default:
+		result.Status = statusCannotDisprove
+	}
+	for _, o := range outcomes {
+		if o.Status == result.Status || (result.Status == statusCannotDisprove && o.Status != statusBugWrong) {
+			result.Task2Result, result.Task3Result = o.Task2Result, o.Task3Result
+			break
+		}
+	}
+
+	parts := make([]string, 0, len(outcomes))
+	for _, o := range outcomes {
+		parts = append(parts, fmt.Sprintf("%s (%s): %s", o.ID, o.Status, truncateString(o.Summary, 200)))
+	}
+	result.Summary = fmt.Sprintf("Compound claim with %d assertions: %d bug_confirmed, %d bug_wrong, %d cannot_disprove, %d protocol_error, %d error. %s",
+		len(outcomes), counts[statusBugConfirmed], counts[statusBugWrong], counts[statusCannotDisprove], counts[statusProtocolError], counts[statusError],
+		strings.Join(parts, "; "))
+}
+
+// verifyAssertion runs reachability and, when the state is reachable, test
+// generation for one assertion and decides its verdict under the run's
+// hypothesis. The branch each task forked from is appended to lineage.
+func (r *Runner) verifyAssertion(lineage *[]TaskLineage, task1 *Task1Result, assertion *FormalizedAssertion) (*AssertionOutcome, error) {
+	outcome := &AssertionOutcome{}
+	label := ""
+	if len(task1.FormalizedAssertions) > 1 {
+		label = fmt.Sprintf(" [%s]", assertion.ID)
+	}
+
+	// Task 2: Reachability Analysis
+	logx.Infof("Task 2%s: Analyzing reachability", label)
+	parent, chained := r.forkPoint(lineage, "task2", r.opts.Chain.Task2, task1.BranchID)
+	task2Result, err := r.runTask2(parent, assertion, r.handoff(chained, "Task 1", r.opts.Chain.Task3))
+	if err != nil {
+		return nil, fmt.Errorf("task 2%s failed: %w", label, err)
+	}
+	outcome.Task2Result = task2Result
+	if task2Result.Status == taskProtocolError {
+		outcome.Status = statusProtocolError
+		outcome.Summary = fmt.Sprintf("Task 2%s did not produce a valid result file: %s", label, task2Result.ProtocolError)
+		return outcome, nil
+	}
+
+	// Check if Task 2 found the state unreachable
+	if task2Result.Status == "UNREACHABLE" || task2Result.Status == "INVALID" {
+		outcome.Status = statusBugWrong
+		if r.opts.IsFalsePositive {
+			outcome.Summary = fmt.Sprintf("Bug claim confirmed as FALSE POSITIVE (unreachable): %s", task2Result.Reason)
+		} else {
+			outcome.Summary = fmt.Sprintf("Bug state is unreachable: %s", task2Result.Reason)
+		}
+		return outcome, nil
+	}
+
+	// Task 3: Test Generator
+	logx.Infof("Task 3%s: Generating test case", label)
+	parent, chained = r.forkPoint(lineage, "task3", r.opts.Chain.Task3, task2Result.BranchID)
+	task3Result, err := r.runTask3(parent, assertion, reachabilityBrief(task2Result), r.handoff(chained, "Task 2", false))
+	if err != nil {
+		return nil, fmt.Errorf("task 3%s failed: %w", label, err)
+	}
+	outcome.Task3Result = task3Result
+	if task3Result.Status == taskProtocolError {
+		outcome.Status = statusProtocolError
+		outcome.Summary = fmt.Sprintf("Task 3%s did not produce a valid result file: %s", label, task3Result.ProtocolError)
+		return outcome, nil
+	}
+
+	// Determine final result based on IsFalsePositive assumption
+	if r.opts.IsFalsePositive {
+		// We assume the bug is FALSE. We've tried to prove it wrong.
+		// If we found evidence it's wrong (refuted), report bug_wrong.
+		// If we cannot disprove it despite our assumption, we still conclude it's wrong
+		// (because our assumption is that it's false, and we haven't found strong evidence otherwise).
+		if task3Result.Status == "BUG_REFUTED" {
+			outcome.Status = statusBugWrong
+			summaryText := task3Result.Judgment
+			if summaryText == "" {
+				summaryText = task3Result.Analysis
+			}
+			if summaryText == "" {
+				summaryText = "Bug claim refuted by test"
+			}
+			outcome.Summary = fmt.Sprintf("Bug claim confirmed as FALSE POSITIVE: %s", summaryText)
+		} else if task3Result.Status == "BUG_CONFIRMED" {
+			// If test actually confirms the bug despite our false positive assumption,
+			// this proves the assumption was WRONG - the bug is actually REAL
+			// We must report bug_confirmed because the evidence is irrefutable
+			outcome.Status = statusBugConfirmed
+			summaryText := task3Result.Judgment
+			if summaryText == "" {
+				summaryText = task3Result.Analysis
+			}
+			if summaryText == "" {
+				summaryText = "Bug claim confirmed by test"
+			}
+			outcome.Summary = fmt.Sprintf("ASSUMPTION WAS WRONG: Bug was assumed FALSE POSITIVE but test CONFIRMED it is REAL. %s", summaryText)
+		} else {
+			// TEST_INCONCLUSIVE - we couldn't disprove it through testing,
+			// but based on our assumption that it's false, we conclude it's likely false
+			outcome.Status = statusBugWrong
+			outcome.Summary = "Bug claim is likely FALSE POSITIVE: Cannot disprove through test, but assumption and evidence suggest it is not a real bug"
+		}
+	} else {
+		// We assume the bug is REAL. We're trying to confirm it.
+		// If test confirms it, report bug_confirmed.
+		// If test refutes it, report bug_wrong.
+		// If test is inconclusive, report cannot_disprove (we assume it's real but can't prove).
+		if task3Result.Status == "BUG_CONFIRMED" {
+			outcome.Status = statusBugConfirmed
+			summaryText := task3Result.Judgment
+			if summaryText == "" {
+				summaryText = task3Result.Analysis
+			}
+			if summaryText == "" {
+				summaryText = "Bug claim confirmed by test"
+			}
+			outcome.Summary = fmt.Sprintf("Bug claim CONFIRMED as REAL: %s", summaryText)
+		} else if task3Result.Status == "BUG_REFUTED" {
+			outcome.Status = statusBugWrong
+			summaryText := task3Result.Judgment
+			if summaryText == "" {
+				summaryText = task3Result.Analysis
+			}
+			if summaryText == "" {
+				summaryText = "Bug claim refuted by test"
+			}
+			outcome.Summary = fmt.Sprintf("Bug claim refuted: %s", summaryText)
+		} else {
+			// TEST_INCONCLUSIVE - we couldn't confirm it through testing
+			// Since we assume it's real, we still lean towards it being real
+			outcome.Status = statusBugConfirmed
+			outcome.Summary = "Bug claim assumed REAL: Test was inconclusive, but assumption and evidence suggest it is a real bug"
+		}
+	}
+	return outcome, nil
+}
+
+// reachabilityBrief is what Task 3 learns of the reachability analysis.
+func reachabilityBrief(t *Task2Result) string {
+	var sb strings.Builder
+	sb.WriteString(t.ReachabilityAnalysis)
+	if t.Evidence != "" {
+		fmt.Fprintf(&sb, "\n\nEvidence:\n%s", t.Evidence)
+	}
+	return strings.TrimSpace(sb.String())
+}
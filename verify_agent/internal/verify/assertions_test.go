@@ -0,0 +1,34 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAggregateAssertionsKeepsPerAssertionOutcomes(t *testing.T) {
+	confirmedTest := &Task3Result{Status: "BUG_CONFIRMED"}
+	outcomes := []AssertionOutcome{
+		{ID: "A1", Status: statusBugWrong, Summary: "close is guarded"},
+		{ID: "A2", Status: statusBugConfirmed, Summary: "leak reproduced", Task3Result: confirmedTest},
+		{ID: "A3", Status: statusError, Summary: "task 2 [A3] failed"},
+	}
+	result := &Result{}
+	aggregateAssertions(result, outcomes)
+	if result.Status != statusBugConfirmed || result.Task3Result != confirmedTest || len(result.Assertions) != 3 {
+		t.Fatalf("unexpected aggregate %+v", result)
+	}
+	if !strings.Contains(result.Summary, "1 bug_confirmed, 1 bug_wrong, 0 cannot_disprove, 0 protocol_error, 1 error") || !strings.Contains(result.Summary, "A1 (bug_wrong): close is guarded") {
+		t.Fatalf("summary must list every assertion: %s", result.Summary)
+	}
+
+	wrong := &Result{}
+	aggregateAssertions(wrong, []AssertionOutcome{{ID: "A1", Status: statusBugWrong}, {ID: "A2", Status: statusBugWrong}})
+	if wrong.Status != statusBugWrong {
+		t.Fatalf("all refuted assertions must make the claim wrong, got %s", wrong.Status)
+	}
+	mixed := &Result{}
+	aggregateAssertions(mixed, []AssertionOutcome{{ID: "A1", Status: statusBugWrong}, {ID: "A2", Status: statusCannotDisprove}})
+	if mixed.Status != statusCannotDisprove {
+		t.Fatalf("an undecided assertion must leave the claim undecided, got %s", mixed.Status)
+	}
+}
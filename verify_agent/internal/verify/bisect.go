@@ -0,0 +1,138 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"verify_agent/internal/logx"
+)
+
+// bisectResultPath is the workspace file, relative to Options.WorkspaceDir,
+// where the bisect branch records the first bad commit.
+const bisectResultPath = ".verify_agent/bisect.json"
+
+// Bisect statuses.
+const (
+	bisectFound    = "found"
+	bisectNotFound = "not_found"
+	bisectError    = "error"
+)
+
+// BisectResult names the commit that introduced a confirmed bug.
+type BisectResult struct {
+	// Status is found, not_found or error.
+	Status         string `json:"status"`
+	BranchID       string `json:"branch_id,omitempty"`
+	FirstBadCommit string `json:"first_bad_commit,omitempty"`
+	Author         string `json:"author,omitempty"`
+	Date           string `json:"date,omitempty"`
+	Subject        string `json:"subject,omitempty"`
+	PullRequest    string `json:"pull_request,omitempty"`
+	GoodRef        string `json:"good_ref,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// bisectRecord is the JSON the bisect agent writes to bisectResultPath.
+type bisectRecord struct {
+	Status         string `json:"status"`
+	FirstBadCommit string `json:"first_bad_commit"`
+	Author         string `json:"author"`
+	Date           string `json:"date"`
+	Subject        string `json:"subject"`
+	PullRequest    string `json:"pull_request"`
+	GoodRef        string `json:"good_ref"`
+	Reason         string `json:"reason"`
+}
+
+// testConfirmed reports whether result carries a test that confirmed the bug,
+// which the bisect and fix follow-ups start from.
+func testConfirmed(result *Result) bool {
+	if result == nil || result.Status != statusBugConfirmed {
+		return false
+	}
+	t3 := result.Task3Result
+	return t3 != nil && t3.Status == "BUG_CONFIRMED" && t3.BranchID != ""
+}
+
+// runBisect launches a follow-up branch from Task 3's branch that bisects the
+// history with the reproduction test and attaches the outcome to result.
+// Bisect failures are recorded in result.Bisect, never returned.
+func (r *Runner) runBisect(result *Result) {
+	t3 := result.Task3Result
+	data := BisectPromptData{
+		BugDescription: r.opts.BugDescription,
+		TestCase:       t3.TestCase,
+		GoodRef:        r.opts.BisectGoodRef,
+		ResultPath:     r.workspacePath(bisectResultPath),
+	}
+	if a := t3.Artifact; a != nil {
+		data.TestPath, data.TestCommand = a.Path, a.Command
+	}
+	logx.Infof("Bisecting the history from branch %s with the reproduction test", t3.BranchID)
+	parent, _ := r.forkPoint(&result.Lineage, "bisect", true, t3.BranchID)
+	res, err := r.bisect(parent, renderPrompt(tmplBisect, data))
+	if err != nil {
+		logx.Warningf("Bisect failed: %v", err)
+		res = &BisectResult{Status: bisectError, Error: err.Error()}
+	}
+	result.Bisect = res
+	r.attachBranchRange(result)
+}
+
+func (r *Runner) bisect(parentBranchID, prompt string) (*BisectResult, error) {
+	data, err := r.executeAgent("codex", prompt, parentBranchID)
+	if err != nil {
+		return nil, err
+	}
+	branchID := stringField(data, "branch_id")
+	if branchID == "" {
+		return nil, fmt.Errorf("bisect agent reported no branch")
+	}
+	response := strings.TrimSpace(stringField(data, "response"))
+
+	// Prefer the recorded file; fall back to JSON quoted in the reply.
+	content, err := r.readArtifact(branchID, r.workspacePath(bisectResultPath))
+	if err != nil {
+		logx.Warningf("Bisect result file unavailable on branch %s: %v", branchID, err)
+		content = extractJSONBlock(response)
+	}
+	res, err := parseBisectRecord(content)
+	if err != nil {
+		return nil, err
+	}
+	res.BranchID = branchID
+	return res, nil
+}
+
+func parseBisectRecord(content string) (*BisectResult, error) {
+	var rec bisectRecord
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &rec); err != nil {
+		return nil, fmt.Errorf("parse bisect result: %w", err)
+	}
+	res := &BisectResult{
+		FirstBadCommit: strings.TrimSpace(rec.FirstBadCommit),
+		Author:         strings.TrimSpace(rec.Author),
+		Date:           strings.TrimSpace(rec.Date),
+		Subject:        strings.TrimSpace(rec.Subject),
+		PullRequest:    strings.TrimSpace(rec.PullRequest),
+		GoodRef:        strings.TrimSpace(rec.GoodRef),
+		Reason:         strings.TrimSpace(rec.Reason),
+	}
+	switch strings.ToUpper(strings.TrimSpace(rec.Status)) {
+	case "FOUND":
+		if res.FirstBadCommit == "" {
+			return nil, fmt.Errorf("bisect reported FOUND without a first_bad_commit")
+		}
+		res.Status = bisectFound
+	case "NOT_FOUND":
+		res.Status = bisectNotFound
+	default:
+		if res.FirstBadCommit == "" {
+			return nil, fmt.Errorf("bisect result has unknown status %q", rec.Status)
+		}
+		res.Status = bisectFound
+	}
+	return res, nil
+}
@@ -0,0 +1,62 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBisectRecord(t *testing.T) {
+	res, err := parseBisectRecord(`{"status": "FOUND", "first_bad_commit": "abc123", "author": "Dev <dev@example.com>", "subject": "Cache entries lazily (#42)", "pull_request": "#42", "good_ref": "v1.2.0"}`)
+	if err != nil || res.Status != bisectFound || res.FirstBadCommit != "abc123" || res.PullRequest != "#42" || res.GoodRef != "v1.2.0" {
+		t.Fatalf("unexpected bisect result %+v (%v)", res, err)
+	}
+	if res, err := parseBisectRecord(`{"status": "NOT_FOUND", "reason": "test fails on every tag"}`); err != nil || res.Status != bisectNotFound || res.Reason == "" {
+		t.Fatalf("unexpected not-found result %+v (%v)", res, err)
+	}
+	if _, err := parseBisectRecord(`{"status": "FOUND"}`); err == nil {
+		t.Fatalf("FOUND without a commit must be rejected")
+	}
+}
+
+func TestFollowUpsOnlyForTestConfirmedBugs(t *testing.T) {
+	confirmed := &Result{Status: statusBugConfirmed, Task3Result: &Task3Result{BranchID: "t3", Status: "BUG_CONFIRMED"}}
+	if !testConfirmed(confirmed) {
+		t.Fatalf("a test-confirmed bug must be bisected")
+	}
+	protocolError := &Result{Status: statusProtocolError, Task3Result: &Task3Result{BranchID: "t3", Status: taskProtocolError}}
+	inconclusive := &Result{Status: statusBugConfirmed, Task3Result: &Task3Result{BranchID: "t3", Status: "TEST_INCONCLUSIVE"}}
+	for _, res := range []*Result{protocolError, inconclusive, {Status: statusBugWrong}, nil} {
+		if testConfirmed(res) {
+			t.Fatalf("no failing test to bisect with in %+v", res)
+		}
+	}
+}
+
+func TestBisectPromptUsesArtifactOrQuotedTest(t *testing.T) {
+	withFile := renderPrompt(tmplBisect, BisectPromptData{BugDescription: "leak", TestPath: "pkg/repro_test.go", TestCommand: "go test ./pkg -run TestRepro", ResultPath: "/w/.verify_agent/bisect.json"})
+	if !strings.Contains(withFile, "`pkg/repro_test.go`") || !strings.Contains(withFile, "walk back through the history") || !strings.Contains(withFile, "/w/.verify_agent/bisect.json") {
+		t.Fatalf("unexpected prompt:\n%s", withFile)
+	}
+	quoted := renderPrompt(tmplBisect, BisectPromptData{BugDescription: "leak", TestCase: "func TestRepro(t *testing.T) {}", GoodRef: "v1.0.0"})
+	if !strings.Contains(quoted, "func TestRepro") || !strings.Contains(quoted, "use `v1.0.0`") {
+		t.Fatalf("unexpected prompt:\n%s", quoted)
+	}
+}
+
+func TestRunBisectChainsFromTask3InLineage(t *testing.T) {
+	client := &fakeAgentClient{results: []string{`{"status": "FOUND", "first_bad_commit": "abc123"}`}}
+	r := newFakeRunner(t, client)
+	result := &Result{Status: statusBugConfirmed, Task3Result: &Task3Result{BranchID: "t3", Status: "BUG_CONFIRMED", TestCase: "func TestRepro(t *testing.T) {}"}}
+	r.runBisect(result)
+
+	if result.Bisect == nil || result.Bisect.Status != bisectFound || result.Bisect.BranchID != "b1" {
+		t.Fatalf("unexpected bisect result %+v", result.Bisect)
+	}
+	if len(client.parents) != 1 || client.parents[0] != "t3" {
+		t.Fatalf("bisect must fork from the Task 3 branch, got parents %v", client.parents)
+	}
+	want := TaskLineage{Task: "bisect", Mode: lineageChained, ParentBranchID: "t3"}
+	if len(result.Lineage) != 1 || result.Lineage[0] != want {
+		t.Fatalf("expected lineage %+v, got %+v", want, result.Lineage)
+	}
+}
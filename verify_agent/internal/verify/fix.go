@@ -0,0 +1,171 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"verify_agent/internal/logx"
+)
+
+// Files the fix branch leaves in the workspace, relative to
+// Options.WorkspaceDir: the outcome of the attempt and the candidate patch.
+const (
+	fixResultPath = ".verify_agent/fix.json"
+	fixDiffPath   = ".verify_agent/fix.diff"
+)
+
+// defaultFixAgent is the builder asked for a fix when Options.FixAgent is
+// empty.
+const defaultFixAgent = "codex"
+
+// Fix statuses.
+const (
+	fixFixed    = "fixed"
+	fixNotFixed = "not_fixed"
+	fixError    = "error"
+)
+
+// FixResult is the candidate patch Task 4 proposes for a confirmed bug.
+type FixResult struct {
+	// Status is fixed, not_fixed or error. A fix counts only when the
+	// reproduction test and the existing suite both passed on its branch.
+	Status       string   `json:"status"`
+	Agent        string   `json:"agent,omitempty"`
+	BranchID     string   `json:"branch_id,omitempty"`
+	Summary      string   `json:"summary,omitempty"`
+	FilesChanged []string `json:"files_changed,omitempty"`
+	// Diff is the fix alone, without the reproduction test.
+	Diff      string   `json:"diff,omitempty"`
+	ReproTest *TestRun `json:"repro_test,omitempty"`
+	Suite     *TestRun `json:"suite,omitempty"`
+	Reason    string   `json:"reason,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// TestRun is one test command run on the fix branch.
+type TestRun struct {
+	Command  string `json:"command"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Output   string `json:"output,omitempty"`
+}
+
+// Passed reports whether the command exited zero.
+func (t *TestRun) Passed() bool {
+	return t != nil && t.ExitCode != nil && *t.ExitCode == 0
+}
+
+// fixRecord is the JSON the fix agent writes to fixResultPath.
+type fixRecord struct {
+	Status        string   `json:"status"`
+	Summary       string   `json:"summary"`
+	FilesChanged  []string `json:"files_changed"`
+	ReproCommand  string   `json:"repro_command"`
+	ReproExitCode *int     `json:"repro_exit_code"`
+	ReproOutput   string   `json:"repro_output"`
+	SuiteCommand  string   `json:"suite_command"`
+	SuiteExitCode *int     `json:"suite_exit_code"`
+	SuiteOutput   string   `json:"suite_output"`
+	Reason        string   `json:"reason"`
+}
+
+// runFix launches Task 4: a builder branch forked from Task 3's branch that
+// writes a minimal fix making the reproduction test pass without breaking the
+// existing suite. Fix failures are recorded in result.Fix, never returned.
+func (r *Runner) runFix(result *Result) {
+	t3 := result.Task3Result
+	agent := r.opts.FixAgent
+	if agent == "" {
+		agent = defaultFixAgent
+	}
+	data := FixPromptData{
+		BugDescription:      r.opts.BugDescription,
+		FormalizedAssertion: t3.FormalizedAssertion,
+		TestCase:            t3.TestCase,
+		ResultPath:          r.workspacePath(fixResultPath),
+		DiffPath:            r.workspacePath(fixDiffPath),
+	}
+	if a := t3.Artifact; a != nil {
+		data.TestPath, data.TestCommand = a.Path, a.Command
+	}
+	logx.Infof("Task 4: Asking %s for a fix from branch %s", agent, t3.BranchID)
+	parent, _ := r.forkPoint(&result.Lineage, "task4", true, t3.BranchID)
+	res, err := r.fix(agent, parent, renderPrompt(tmplFix, data))
+	if err != nil {
+		logx.Warningf("Task 4 failed: %v", err)
+		res = &FixResult{Status: fixError, Error: err.Error()}
+	}
+	res.Agent = agent
+	result.Fix = res
+	r.attachBranchRange(result)
+}
+
+func (r *Runner) fix(agent, parentBranchID, prompt string) (*FixResult, error) {
+	data, err := r.executeAgent(agent, prompt, parentBranchID)
+	if err != nil {
+		return nil, err
+	}
+	branchID := stringField(data, "branch_id")
+	if branchID == "" {
+		return nil, fmt.Errorf("fix agent reported no branch")
+	}
+	content, err := r.readArtifact(branchID, r.workspacePath(fixResultPath))
+	if err != nil {
+		return nil, fmt.Errorf("fix result unavailable on branch %s: %w", branchID, err)
+	}
+	diff, err := r.readArtifact(branchID, r.workspacePath(fixDiffPath))
+	if err != nil {
+		logx.Warningf("Fix diff unavailable on branch %s: %v", branchID, err)
+		diff = ""
+	}
+	res, err := parseFixRecord(content, diff)
+	if err != nil {
+		return nil, err
+	}
+	res.BranchID = branchID
+	return res, nil
+}
+
+// parseFixRecord builds the fix outcome from the result file and the diff.
+// A FIXED claim is downgraded to not_fixed unless the diff is present and
+// both recorded test runs exited zero.
+func parseFixRecord(content, diff string) (*FixResult, error) {
+	var rec fixRecord
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &rec); err != nil {
+		return nil, fmt.Errorf("parse fix result: %w", err)
+	}
+	res := &FixResult{
+		Summary: strings.TrimSpace(rec.Summary),
+		Reason:  strings.TrimSpace(rec.Reason),
+		Diff:    strings.TrimSpace(diff),
+	}
+	for _, f := range rec.FilesChanged {
+		if f = strings.TrimSpace(f); f != "" {
+			res.FilesChanged = append(res.FilesChanged, f)
+		}
+	}
+	if cmd := strings.TrimSpace(rec.ReproCommand); cmd != "" || rec.ReproExitCode != nil {
+		res.ReproTest = &TestRun{Command: cmd, ExitCode: rec.ReproExitCode, Output: strings.TrimSpace(rec.ReproOutput)}
+	}
+	if cmd := strings.TrimSpace(rec.SuiteCommand); cmd != "" || rec.SuiteExitCode != nil {
+		res.Suite = &TestRun{Command: cmd, ExitCode: rec.SuiteExitCode, Output: strings.TrimSpace(rec.SuiteOutput)}
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(rec.Status)) {
+	case "FIXED":
+		res.Status = fixFixed
+		switch {
+		case res.Diff == "":
+			res.Status, res.Reason = fixNotFixed, "reported FIXED without a diff"
+		case !res.ReproTest.Passed():
+			res.Status, res.Reason = fixNotFixed, "reported FIXED but the reproduction test did not pass"
+		case !res.Suite.Passed():
+			res.Status, res.Reason = fixNotFixed, "reported FIXED but the existing test suite did not pass"
+		}
+	case "NOT_FIXED":
+		res.Status = fixNotFixed
+	default:
+		return nil, fmt.Errorf("fix result has unknown status %q", rec.Status)
+	}
+	return res, nil
+}
@@ -0,0 +1,47 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFixRecord(t *testing.T) {
+	diff := "diff --git a/pkg/store.go b/pkg/store.go\n"
+	res, err := parseFixRecord(`{"status": "FIXED", "summary": "init the map", "files_changed": [" pkg/store.go ", ""], "repro_command": "go test ./pkg -run TestRepro", "repro_exit_code": 0, "suite_command": "go test ./pkg", "suite_exit_code": 0}`, diff)
+	if err != nil || res.Status != fixFixed || res.Diff != strings.TrimSpace(diff) || len(res.FilesChanged) != 1 || res.FilesChanged[0] != "pkg/store.go" {
+		t.Fatalf("unexpected fix result %+v (%v)", res, err)
+	}
+	if !res.ReproTest.Passed() || !res.Suite.Passed() {
+		t.Fatalf("both runs exited 0: %+v %+v", res.ReproTest, res.Suite)
+	}
+
+	if _, err := parseFixRecord(`{"status": "DONE"}`, diff); err == nil {
+		t.Fatalf("an unknown status must be rejected")
+	}
+	if res, err := parseFixRecord(`{"status": "NOT_FIXED", "reason": "needs an API change"}`, ""); err != nil || res.Status != fixNotFixed || res.Reason == "" {
+		t.Fatalf("unexpected not-fixed result %+v (%v)", res, err)
+	}
+}
+
+func TestParseFixRecordDowngradesUnverifiedFixes(t *testing.T) {
+	cases := map[string]struct{ content, diff string }{
+		"reported FIXED without a diff":                           {`{"status": "FIXED", "repro_exit_code": 0, "suite_exit_code": 0}`, ""},
+		"reported FIXED but the reproduction test did not pass":   {`{"status": "FIXED", "repro_exit_code": 1, "suite_exit_code": 0}`, "diff"},
+		"reported FIXED but the existing test suite did not pass": {`{"status": "FIXED", "repro_exit_code": 0}`, "diff"},
+	}
+	for reason, tc := range cases {
+		res, err := parseFixRecord(tc.content, tc.diff)
+		if err != nil || res.Status != fixNotFixed || res.Reason != reason {
+			t.Errorf("parseFixRecord(%s) = %+v, %v; want not_fixed with %q", tc.content, res, err, reason)
+		}
+	}
+}
+
+func TestFixPromptDiffsAgainstReproductionState(t *testing.T) {
+	prompt := renderPrompt(tmplFix, FixPromptData{BugDescription: "nil map write", TestPath: "pkg/repro_test.go", TestCommand: "go test ./pkg -run TestRepro", ResultPath: "/w/.verify_agent/fix.json", DiffPath: "/w/.verify_agent/fix.diff"})
+	for _, want := range []string{"`go test ./pkg -run TestRepro`", "git write-tree", "git diff --cached <tree id>", "/w/.verify_agent/fix.diff", "/w/.verify_agent/fix.json"} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("fix prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+}
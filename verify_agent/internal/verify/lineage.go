@@ -0,0 +1,105 @@
+package verify
+
+import (
+	"fmt"
+	"strings"
+
+	"verify_agent/internal/logx"
+)
+
+// verifyStatePath is the workspace file, relative to Options.WorkspaceDir,
+// through which chained tasks hand the formalized assertion and reachability
+// trace to their successor.
+const verifyStatePath = ".verify_agent/verify_state.json"
+
+// Lineage modes reported in Result.LineageMode and TaskLineage.Mode.
+const (
+	lineageParent  = "parent"
+	lineageChained = "chained"
+	lineageMixed   = "mixed"
+)
+
+// TaskChain selects which tasks fork from their predecessor's branch instead
+// of the original parent. A chained task sees the notes and helper files its
+// predecessor left in the workspace, plus verify_state.json.
+type TaskChain struct {
+	Task2 bool // Task 2 forks from Task 1's branch
+	Task3 bool // Task 3 forks from Task 2's branch
+}
+
+// ParseTaskChain parses a --chain-tasks value: "none" (or empty), "all", or a
+// comma-separated list of the tasks to chain, e.g. "3" or "2,3".
+func ParseTaskChain(s string) (TaskChain, error) {
+	var chain TaskChain
+	for _, part := range strings.Split(strings.ToLower(s), ",") {
+		switch strings.TrimPrefix(strings.TrimSpace(part), "task") {
+		case "", "none":
+		case "all":
+			chain.Task2, chain.Task3 = true, true
+		case "2":
+			chain.Task2 = true
+		case "3":
+			chain.Task3 = true
+		default:
+			return TaskChain{}, fmt.Errorf("unknown chained task %q (expected none, all, 2 or 3)", strings.TrimSpace(part))
+		}
+	}
+	return chain, nil
+}
+
+// Mode summarizes the chain as parent, chained or mixed.
+func (c TaskChain) Mode() string {
+	switch {
+	case c.Task2 && c.Task3:
+		return lineageChained
+	case c.Task2 || c.Task3:
+		return lineageMixed
+	default:
+		return lineageParent
+	}
+}
+
+// TaskLineage records the branch a task forked from.
+type TaskLineage struct {
+	Task string `json:"task"`
+	// Mode is parent or chained.
+	Mode           string `json:"mode"`
+	ParentBranchID string `json:"parent_branch_id"`
+}
+
+// StateHandoff tells a task prompt how it takes part in a chain.
+type StateHandoff struct {
+	StatePath string
+	// ContinueFrom names the predecessor whose branch the task forked from;
+	// empty when it forked from the original parent.
+	ContinueFrom string
+	// WriteState asks the task to record what it established in StatePath
+	// for a chained successor.
+	WriteState bool
+}
+
+// forkPoint picks the branch a task forks from, records the choice and
+// reports whether the task is chained. A chained task whose predecessor
+// reported no branch falls back to the parent.
+func (r *Runner) forkPoint(lineage *[]TaskLineage, task string, chained bool, predecessorBranch string) (string, bool) {
+	entry := TaskLineage{Task: task, Mode: lineageParent, ParentBranchID: r.opts.ParentBranchID}
+	if chained {
+		if predecessorBranch != "" {
+			entry.Mode, entry.ParentBranchID = lineageChained, predecessorBranch
+		} else {
+			logx.Warningf("%s is chained but its predecessor reported no branch; forking from %s", task, r.opts.ParentBranchID)
+		}
+	}
+	*lineage = append(*lineage, entry)
+	return entry.ParentBranchID, entry.Mode == lineageChained
+}
+
+// handoff builds the state hand-off of a task that continues from
+// predecessor when chained.
+func (r *Runner) handoff(chained bool, predecessor string, writeState bool) StateHandoff {
+	h := StateHandoff{StatePath: r.workspacePath(verifyStatePath), WriteState: writeState}
+	if chained {
+		h.ContinueFrom = predecessor
+	}
+	return h
+}
@@ -0,0 +1,58 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTaskChain(t *testing.T) {
+	cases := map[string]TaskChain{
+		"":       {},
+		"none":   {},
+		"all":    {Task2: true, Task3: true},
+		"3":      {Task3: true},
+		"2, 3":   {Task2: true, Task3: true},
+		"task2":  {Task2: true},
+		"Task3,": {Task3: true},
+	}
+	for in, want := range cases {
+		got, err := ParseTaskChain(in)
+		if err != nil || got != want {
+			t.Errorf("ParseTaskChain(%q) = %+v, %v; want %+v", in, got, err, want)
+		}
+	}
+	if _, err := ParseTaskChain("1"); err == nil {
+		t.Fatalf("task 1 has no predecessor and must be rejected")
+	}
+	if (TaskChain{}).Mode() != lineageParent || (TaskChain{Task3: true}).Mode() != lineageMixed || (TaskChain{Task2: true, Task3: true}).Mode() != lineageChained {
+		t.Fatalf("unexpected lineage modes")
+	}
+}
+
+func TestForkPointFallsBackToParent(t *testing.T) {
+	r := &Runner{opts: Options{ParentBranchID: "root", WorkspaceDir: "/workspace"}}
+	result := &Result{}
+	if branch, chained := r.forkPoint(&result.Lineage, "task2", true, "t1-branch"); branch != "t1-branch" || !chained {
+		t.Fatalf("a chained task must fork from its predecessor, got %s %v", branch, chained)
+	}
+	if branch, chained := r.forkPoint(&result.Lineage, "task3", true, ""); branch != "root" || chained {
+		t.Fatalf("a chained task without a predecessor branch must fork from the parent, got %s %v", branch, chained)
+	}
+	if len(result.Lineage) != 2 || result.Lineage[0].Mode != lineageChained || result.Lineage[1].Mode != lineageParent {
+		t.Fatalf("unexpected lineage %+v", result.Lineage)
+	}
+}
+
+func TestStateHandoffPrompt(t *testing.T) {
+	r := &Runner{opts: Options{WorkspaceDir: "/workspace"}}
+	chained := buildReachabilityPrompt("assertion", "", false, "", r.handoff(true, "Task 1", true))
+	for _, want := range []string{"CONTINUING FROM Task 1", "/workspace/.verify_agent/verify_state.json", "SHARED STATE FOR THE NEXT TASK"} {
+		if !strings.Contains(chained, want) {
+			t.Fatalf("chained prompt is missing %q", want)
+		}
+	}
+	plain := buildReachabilityPrompt("assertion", "", false, "", r.handoff(false, "Task 1", false))
+	if strings.Contains(plain, "verify_state.json") {
+		t.Fatalf("an unchained task must not mention the shared state")
+	}
+}
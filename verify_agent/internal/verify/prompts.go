@@ -0,0 +1,141 @@
+package verify
+
+import (
+	"embed"
+	"fmt"
+
+	"verify_agent/internal/logx"
+	"verify_agent/internal/prompts"
+)
+
+//go:embed templates/*.tmpl
+var promptFS embed.FS
+
+// Template names. Each file lives in templates/ and can be overridden via
+// ConfigurePrompts.
+const (
+	tmplFormalization = "formalization.tmpl"
+	tmplReachability  = "reachability.tmpl"
+	tmplTestGenerator = "test_generator.tmpl"
+	tmplBisect        = "bisect.tmpl"
+	tmplRepair        = "repair.tmpl"
+	tmplFix           = "fix.tmpl"
+)
+
+// FormalizationPromptData feeds formalization.tmpl (Task 1).
+type FormalizationPromptData struct {
+	BugDescription  string
+	CodeContext     string
+	IsFalsePositive bool
+	// ResultPath is where the agent writes its verdict file.
+	ResultPath string
+	StateHandoff
+}
+
+// ReachabilityPromptData feeds reachability.tmpl (Task 2).
+type ReachabilityPromptData struct {
+	FormalizedAssertion string
+	CodeContext         string
+	IsFalsePositive     bool
+	ResultPath          string
+	StateHandoff
+}
+
+// TestGeneratorPromptData feeds test_generator.tmpl (Task 3).
+type TestGeneratorPromptData struct {
+	FormalizedAssertion  string
+	ReachabilityAnalysis string
+	CodeContext          string
+	IsFalsePositive      bool
+	// ManifestPath, LogPath and DiffPath are where the agent records the
+	// test it wrote, the output of running it and the diff adding it.
+	ManifestPath string
+	LogPath      string
+	DiffPath     string
+	ResultPath   string
+	StateHandoff
+}
+
+// BisectPromptData feeds bisect.tmpl, the follow-up of a confirmed bug.
+// TestPath and TestCommand come from the Task 3 artifact; without one the
+// prompt quotes TestCase.
+type BisectPromptData struct {
+	BugDescription string
+	TestPath       string
+	TestCommand    string
+	TestCase       string
+	GoodRef        string
+	ResultPath     string
+}
+
+// FixPromptData feeds fix.tmpl (Task 4), the fix for a confirmed bug.
+// TestPath and TestCommand come from the Task 3 artifact; without one the
+// prompt quotes TestCase.
+type FixPromptData struct {
+	BugDescription      string
+	FormalizedAssertion string
+	TestPath            string
+	TestCommand         string
+	TestCase            string
+	ResultPath          string
+	DiffPath            string
+}
+
+// RepairPromptData feeds repair.tmpl, the follow-up of a task whose result
+// file is missing or invalid.
+type RepairPromptData struct {
+	Task       string
+	ResultPath string
+	Schema     string
+	Problem    string
+	Response   string
+}
+
+func promptSamples() map[string]any {
+	return map[string]any{
+		tmplFormalization: FormalizationPromptData{BugDescription: "bug", CodeContext: "code", IsFalsePositive: true, ResultPath: "task1_result.json", StateHandoff: StateHandoff{StatePath: "state.json", WriteState: true}},
+		tmplReachability:  ReachabilityPromptData{FormalizedAssertion: "assertion", CodeContext: "code", ResultPath: "task2_result.json", StateHandoff: StateHandoff{StatePath: "state.json", ContinueFrom: "Task 1", WriteState: true}},
+		tmplBisect:        BisectPromptData{BugDescription: "bug", TestPath: "repro_test.go", TestCommand: "go test", GoodRef: "v1.0", ResultPath: "bisect.json"},
+		tmplFix:           FixPromptData{BugDescription: "bug", FormalizedAssertion: "assertion", TestPath: "repro_test.go", TestCommand: "go test", ResultPath: "fix.json", DiffPath: "fix.diff"},
+		tmplRepair:        RepairPromptData{Task: "Task 2", ResultPath: "task2_result.json", Schema: "{}", Problem: "missing", Response: "reply"},
+		tmplTestGenerator: TestGeneratorPromptData{FormalizedAssertion: "assertion", ReachabilityAnalysis: "analysis", CodeContext: "code", ManifestPath: "repro.json", LogPath: "repro.log", DiffPath: "repro.diff", ResultPath: "task3_result.json", StateHandoff: StateHandoff{StatePath: "state.json", ContinueFrom: "Task 2"}},
+	}
+}
+
+var (
+	defaultPromptSet = prompts.MustLoad(promptFS, promptSamples())
+	activePromptSet  = defaultPromptSet
+)
+
+// ConfigurePrompts validates the templates in dir (overriding the embedded
+// defaults by file name) and makes them active. An empty dir restores the
+// defaults. Call it once at startup, before any Runner is used.
+func ConfigurePrompts(dir string) error {
+	set, err := prompts.Load(promptFS, dir, promptSamples())
+	if err != nil {
+		return err
+	}
+	activePromptSet = set
+	return nil
+}
+
+// PromptVersions reports the source and version of each active template.
+func PromptVersions() map[string]string {
+	return activePromptSet.Versions()
+}
+
+// renderPrompt executes an active template. Templates are validated when
+// loaded, so a render failure falls back to the embedded default rather than
+// sending an empty prompt.
+func renderPrompt(name string, data any) string {
+	out, err := activePromptSet.Render(name, data)
+	if err == nil {
+		return out
+	}
+	logx.Errorf("Rendering prompt template %s failed, using embedded default: %v", name, err)
+	out, err = defaultPromptSet.Render(name, data)
+	if err != nil {
+		panic(fmt.Sprintf("embedded prompt template %s failed to render: %v", name, err))
+	}
+	return out
+}
@@ -0,0 +1,206 @@
+package verify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"verify_agent/internal/logx"
+)
+
+// Result files each task writes, relative to Options.WorkspaceDir. The
+// runner decides on these files alone; the agent's reply is kept for
+// reference only.
+const (
+	task1ResultPath = ".verify_agent/task1_result.json"
+	task2ResultPath = ".verify_agent/task2_result.json"
+	task3ResultPath = ".verify_agent/task3_result.json"
+)
+
+// protocolRepairs bounds the repair branches run for a task whose result
+// file is missing or invalid.
+const protocolRepairs = 1
+
+// taskProtocolError is the status of a task that produced no valid result
+// file, even after repair.
+const taskProtocolError = "PROTOCOL_ERROR"
+
+// verdictSpec describes the result file of one task.
+type verdictSpec struct {
+	task   string
+	path   string
+	schema string
+}
+
+var (
+	task1Spec = verdictSpec{
+		task: "Task 1 (bug claim formalization)",
+		path: task1ResultPath,
+		schema: `{"status": "VALID | INVALID", "bug_claim": string, "judgment": string, "reason": string (required when INVALID), ` +
+			`"assertions": [{"id": string, "precondition": string, "path": string, "postcondition": string}] (at least one when VALID), "analysis": string}`,
+	}
+	task2Spec = verdictSpec{
+		task: "Task 2 (reachability analysis)",
+		path: task2ResultPath,
+		schema: `{"status": "REACHABLE | UNREACHABLE | INVALID", "judgment": string, "reason": string (required unless REACHABLE), ` +
+			`"reachability_analysis": string, "evidence": string}`,
+	}
+	task3Spec = verdictSpec{
+		task: "Task 3 (test generation)",
+		path: task3ResultPath,
+		schema: `{"status": "BUG_CONFIRMED | BUG_REFUTED | TEST_INCONCLUSIVE", "judgment": string, "test_case": string (required unless TEST_INCONCLUSIVE), ` +
+			`"test_execution": string (required unless TEST_INCONCLUSIVE), "analysis": string, "report": string}`,
+	}
+)
+
+// task1Verdict is the result file of Task 1.
+type task1Verdict struct {
+	Status     string                `json:"status"`
+	BugClaim   string                `json:"bug_claim"`
+	Judgment   string                `json:"judgment"`
+	Reason     string                `json:"reason"`
+	Assertions []FormalizedAssertion `json:"assertions"`
+	Analysis   string                `json:"analysis"`
+}
+
+func (v *task1Verdict) validate() error {
+	if err := checkStatus(&v.Status, "VALID", "INVALID"); err != nil {
+		return err
+	}
+	switch v.Status {
+	case "VALID":
+		if len(v.Assertions) == 0 {
+			return errors.New("status VALID requires at least one assertion")
+		}
+		seen := map[string]bool{}
+		for i := range v.Assertions {
+			a := &v.Assertions[i]
+			if strings.TrimSpace(a.Precondition) == "" || strings.TrimSpace(a.Postcondition) == "" {
+				return fmt.Errorf("assertion %d needs a precondition and a postcondition", i+1)
+			}
+			// Assertions without an id, or with a duplicate one, are numbered A1, A2, ...
+			if a.ID = strings.TrimSpace(a.ID); a.ID == "" || seen[a.ID] {
+				a.ID = fmt.Sprintf("A%d", i+1)
+			}
+			seen[a.ID] = true
+		}
+	case "INVALID":
+		if strings.TrimSpace(v.Reason) == "" {
+			return errors.New("status INVALID requires a reason")
+		}
+	}
+	return nil
+}
+
+// task2Verdict is the result file of Task 2.
+type task2Verdict struct {
+	Status               string `json:"status"`
+	Judgment             string `json:"judgment"`
+	Reason               string `json:"reason"`
+	ReachabilityAnalysis string `json:"reachability_analysis"`
+	Evidence             string `json:"evidence"`
+}
+
+func (v *task2Verdict) validate() error {
+	if err := checkStatus(&v.Status, "REACHABLE", "UNREACHABLE", "INVALID"); err != nil {
+		return err
+	}
+	if v.Status != "REACHABLE" && strings.TrimSpace(v.Reason) == "" {
+		return fmt.Errorf("status %s requires a reason", v.Status)
+	}
+	return nil
+}
+
+// task3Verdict is the result file of Task 3.
+type task3Verdict struct {
+	Status        string `json:"status"`
+	Judgment      string `json:"judgment"`
+	TestCase      string `json:"test_case"`
+	TestExecution string `json:"test_execution"`
+	Analysis      string `json:"analysis"`
+	Report        string `json:"report"`
+}
+
+func (v *task3Verdict) validate() error {
+	if err := checkStatus(&v.Status, "BUG_CONFIRMED", "BUG_REFUTED", "TEST_INCONCLUSIVE"); err != nil {
+		return err
+	}
+	if v.Status == "TEST_INCONCLUSIVE" {
+		return nil
+	}
+	if strings.TrimSpace(v.TestCase) == "" {
+		return fmt.Errorf("status %s requires the test_case", v.Status)
+	}
+	if strings.TrimSpace(v.TestExecution) == "" {
+		return fmt.Errorf("status %s requires the test_execution output", v.Status)
+	}
+	return nil
+}
+
+// checkStatus normalizes *status and rejects values outside valid.
+func checkStatus(status *string, valid ...string) error {
+	*status = strings.ToUpper(strings.TrimSpace(*status))
+	for _, s := range valid {
+		if *status == s {
+			return nil
+		}
+	}
+	if *status == "" {
+		return fmt.Errorf("status is missing (want %s)", strings.Join(valid, ", "))
+	}
+	return fmt.Errorf("status %q is not one of %s", *status, strings.Join(valid, ", "))
+}
+
+// decodeVerdict parses a result file into v and validates it.
+func decodeVerdict(content string, v interface{ validate() error }) error {
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), v); err != nil {
+		return fmt.Errorf("result file is not valid JSON: %w", err)
+	}
+	return v.validate()
+}
+
+// readVerdict loads the result file spec describes from branchID with
+// decode. A missing or invalid file gets up to protocolRepairs repair
+// branches, each forked from the latest one. It returns the branch the
+// verdict was read from, the number of repairs and the remaining protocol
+// problem, if any.
+func (r *Runner) readVerdict(spec verdictSpec, branchID, response string, decode func(content string) error) (string, int, error) {
+	load := func(branchID string) error {
+		if branchID == "" {
+			return errors.New("the agent reported no branch to read the result file from")
+		}
+		content, err := r.readArtifact(branchID, r.workspacePath(spec.path))
+		if err != nil {
+			return fmt.Errorf("result file %s unavailable: %w", spec.path, err)
+		}
+		return decode(content)
+	}
+
+	problem := load(branchID)
+	repairs := 0
+	for problem != nil && branchID != "" && repairs < protocolRepairs {
+		repairs++
+		logx.Warningf("%s result file on branch %s is unusable (%v); running repair %d", spec.task, branchID, problem, repairs)
+		prompt := renderPrompt(tmplRepair, RepairPromptData{
+			Task:       spec.task,
+			ResultPath: r.workspacePath(spec.path),
+			Schema:     spec.schema,
+			Problem:    problem.Error(),
+			Response:   truncateString(response, 4000),
+		})
+		data, err := r.executeAgent("codex", prompt, branchID)
+		if err != nil {
+			problem = fmt.Errorf("repair failed: %w", err)
+			break
+		}
+		if repaired := stringField(data, "branch_id"); repaired != "" {
+			branchID = repaired
+		}
+		problem = load(branchID)
+	}
+	if problem != nil {
+		logx.Errorf("%s did not produce a valid result file: %v", spec.task, problem)
+	}
+	return branchID, repairs, problem
+}
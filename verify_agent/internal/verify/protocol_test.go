@@ -0,0 +1,139 @@
+package verify
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	b "verify_agent/internal/brain"
+	t "verify_agent/internal/tools"
+)
+
+// fakeAgentClient serves scripted agent runs: the nth run creates branch
+// "b<n>", and reading any file from it returns results[n-1].
+type fakeAgentClient struct {
+	mu      sync.Mutex
+	results []string
+	prompts []string
+	parents []string
+}
+
+func (c *fakeAgentClient) ParallelExplore(projectName, parentBranchID string, prompts []string, agent string, numBranches int) (map[string]any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prompts = append(c.prompts, prompts[0])
+	c.parents = append(c.parents, parentBranchID)
+	return map[string]any{"branch_id": fmt.Sprintf("b%d", len(c.prompts))}, nil
+}
+
+func (c *fakeAgentClient) GetBranch(branchID string) (map[string]any, error) {
+	return map[string]any{"id": branchID, "status": "succeed"}, nil
+}
+
+func (c *fakeAgentClient) BranchReadFile(branchID, filePath string) (map[string]any, error) {
+	n, _ := strconv.Atoi(strings.TrimPrefix(branchID, "b"))
+	if n < 1 || n > len(c.results) {
+		return nil, fmt.Errorf("%s not found on %s", filePath, branchID)
+	}
+	return map[string]any{"content": c.results[n-1]}, nil
+}
+
+func (c *fakeAgentClient) BranchOutput(branchID string, fullOutput bool) (map[string]any, error) {
+	return map[string]any{"output": "Wrote the result file."}, nil
+}
+
+func newFakeRunner(tb testing.TB, client *fakeAgentClient) *Runner {
+	tb.Helper()
+	handler := t.NewToolHandler(client, "proj", "root", "/workspace")
+	r, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{BugDescription: "nil map write", ProjectName: "proj", ParentBranchID: "root", WorkspaceDir: "/workspace"})
+	if err != nil {
+		tb.Fatalf("NewRunner: %v", err)
+	}
+	return r
+}
+
+func TestDecodeTask1VerdictNumbersAssertions(t *testing.T) {
+	var v task1Verdict
+	err := decodeVerdict(`{"status": " valid ", "assertions": [{"id": "race", "precondition": "concurrent Close", "postcondition": "double free"}, {"id": "race", "precondition": "error path", "postcondition": "leak"}, {"precondition": "x", "postcondition": "y"}]}`, &v)
+	if err != nil || v.Status != "VALID" || len(v.Assertions) != 3 {
+		t.Fatalf("unexpected verdict %+v (%v)", v, err)
+	}
+	if v.Assertions[0].ID != "race" || v.Assertions[1].ID != "A2" || v.Assertions[2].ID != "A3" {
+		t.Fatalf("duplicate and missing ids must be renumbered, got %q %q %q", v.Assertions[0].ID, v.Assertions[1].ID, v.Assertions[2].ID)
+	}
+}
+
+func TestDecodeVerdictRejectsIncompleteFiles(t *testing.T) {
+	cases := []struct {
+		content string
+		v       interface{ validate() error }
+		want    string
+	}{
+		{"## STATUS: VALID", &task1Verdict{}, "not valid JSON"},
+		{`{"judgment": "looks real"}`, &task1Verdict{}, "status is missing"},
+		{`{"status": "VALID", "assertions": []}`, &task1Verdict{}, "at least one assertion"},
+		{`{"status": "INVALID"}`, &task1Verdict{}, "requires a reason"},
+		{`{"status": "MAYBE"}`, &task2Verdict{}, `status "MAYBE" is not one of`},
+		{`{"status": "UNREACHABLE"}`, &task2Verdict{}, "requires a reason"},
+		{`{"status": "BUG_CONFIRMED", "test_case": "func TestX(t *testing.T) {}"}`, &task3Verdict{}, "test_execution"},
+		{`{"status": "BUG_REFUTED", "test_execution": "ok"}`, &task3Verdict{}, "requires the test_case"},
+	}
+	for _, tc := range cases {
+		err := decodeVerdict(tc.content, tc.v)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("decodeVerdict(%s) = %v; want an error containing %q", tc.content, err, tc.want)
+		}
+	}
+
+	var v task3Verdict
+	if err := decodeVerdict(`{"status": "TEST_INCONCLUSIVE", "judgment": "no harness for this package"}`, &v); err != nil {
+		t.Fatalf("an inconclusive test needs no test case or execution output: %v", err)
+	}
+}
+
+func TestPromptsNameResultFiles(t *testing.T) {
+	r := &Runner{opts: Options{WorkspaceDir: "/workspace"}}
+	prompt := buildReachabilityPrompt("assertion", "", false, r.workspacePath(task2ResultPath), r.handoff(false, "Task 1", false))
+	if !strings.Contains(prompt, "/workspace/.verify_agent/task2_result.json") || strings.Contains(prompt, "# STATUS:") {
+		t.Fatalf("the prompt must ask for the result file instead of a status heading:\n%s", prompt)
+	}
+	repair := renderPrompt(tmplRepair, RepairPromptData{Task: task2Spec.task, ResultPath: "/workspace/" + task2ResultPath, Schema: task2Spec.schema, Problem: "status is missing"})
+	for _, want := range []string{"Task 2 (reachability analysis)", "status is missing", "REACHABLE | UNREACHABLE | INVALID"} {
+		if !strings.Contains(repair, want) {
+			t.Fatalf("repair prompt is missing %q:\n%s", want, repair)
+		}
+	}
+}
+
+func TestRunRepairsInvalidResultFile(t *testing.T) {
+	client := &fakeAgentClient{results: []string{
+		"## STATUS: INVALID",
+		`{"status": "INVALID", "reason": "the map is always initialized"}`,
+	}}
+	res, err := newFakeRunner(t, client).Run()
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Status != statusBugWrong || res.Task1Result.Repairs != 1 || res.Task1Result.BranchID != "b2" {
+		t.Fatalf("expected the repaired verdict to decide the run, got status=%q task1=%+v", res.Status, res.Task1Result)
+	}
+	if len(client.prompts) != 2 || client.parents[1] != "b1" || !strings.Contains(client.prompts[1], "not valid JSON") {
+		t.Fatalf("expected one repair forked from b1 naming the problem, got parents %v", client.parents)
+	}
+}
+
+func TestRunReportsProtocolErrorAfterFailedRepair(t *testing.T) {
+	client := &fakeAgentClient{results: []string{`{"judgment": "looks real"}`, `{"judgment": "still looks real"}`}}
+	res, err := newFakeRunner(t, client).Run()
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Status != statusProtocolError || res.Task1Result.Status != taskProtocolError || !strings.Contains(res.Summary, "status is missing") {
+		t.Fatalf("expected a protocol error, got status=%q summary=%q", res.Status, res.Summary)
+	}
+	if len(client.prompts) != 1+protocolRepairs || res.Task2Result != nil {
+		t.Fatalf("expected %d repair(s) and no Task 2, got %d runs", protocolRepairs, len(client.prompts))
+	}
+}
@@ -0,0 +1,223 @@
+package verify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"verify_agent/internal/logx"
+)
+
+// Hypotheses a verification can start from.
+const (
+	HypothesisReal          = "real"
+	HypothesisFalsePositive = "false-positive"
+	HypothesisBoth          = "both"
+)
+
+// Confidence levels of a reconciled verdict.
+const (
+	confidenceHigh   = "high"
+	confidenceMedium = "medium"
+	confidenceLow    = "low"
+)
+
+// Disagreement is one point where the real-bug and false-positive runs
+// reached different conclusions.
+type Disagreement struct {
+	// Aspect is verdict, formalization, formalized_assertion, reachability or
+	// test.
+	Aspect        string `json:"aspect"`
+	RealBug       string `json:"real_bug"`
+	FalsePositive string `json:"false_positive"`
+}
+
+// evidence is what one run established beyond its own prior.
+type evidence struct {
+	// verdict is "confirmed", "refuted" or "inconclusive".
+	verdict string
+	detail  string
+}
+
+// runBoth runs the real-bug and false-positive pipelines in parallel from the
+// same parent branch and reconciles their outcomes.
+func (r *Runner) runBoth() (*Result, error) {
+	logx.Infof("Running real-bug and false-positive hypotheses in parallel from %s", r.opts.ParentBranchID)
+	hypotheses := [2]string{HypothesisReal, HypothesisFalsePositive}
+	var results [2]*Result
+	var errs [2]error
+	var wg sync.WaitGroup
+	for i, hypothesis := range hypotheses {
+		run := *r
+		run.opts.Hypothesis = hypothesis
+		run.opts.IsFalsePositive = hypothesis == HypothesisFalsePositive
+		wg.Add(1)
+		go func(i int, run *Runner) {
+			defer wg.Done()
+			results[i], errs[i] = run.runHypothesis()
+		}(i, &run)
+	}
+	wg.Wait()
+	if errs[0] != nil && errs[1] != nil {
+		return nil, fmt.Errorf("real-bug hypothesis: %v; false-positive hypothesis: %w", errs[0], errs[1])
+	}
+
+	result := reconcile(results[0], results[1], errs[0], errs[1])
+	result.BugDescription = r.opts.BugDescription
+	result.PromptTemplates = PromptVersions()
+	result.LineageMode = r.opts.Chain.Mode()
+	r.attachBranchRange(result)
+	return result, nil
+}
+
+// reconcile combines the two runs into a calibrated verdict. A run's
+// conclusion counts as evidence only when a task recorded it in a valid
+// result file; inconclusive tests and protocol errors do not. The verdict is:
+//
+//   - bug_confirmed when some run produced evidence of the bug and none
+//     refuted it;
+//   - bug_wrong when some run refuted it and none confirmed it;
+//   - cannot_disprove when the runs contradict each other or neither got past
+//     its prior.
+//
+// Confidence is high when both runs agree, medium when one run's evidence
+// stands against the other's inconclusive result, and low otherwise. A failed
+// run leaves the other's verdict at low confidence.
+func reconcile(realBug, falsePositive *Result, realErr, fpErr error) *Result {
+	result := &Result{
+		Hypothesis:       HypothesisBoth,
+		RealBugRun:       realBug,
+		FalsePositiveRun: falsePositive,
+	}
+	if realErr != nil || fpErr != nil {
+		survivor, failed, failedErr := falsePositive, "real-bug", realErr
+		if fpErr != nil {
+			survivor, failed, failedErr = realBug, "false-positive", fpErr
+		}
+		result.Status = survivor.Status
+		result.Confidence = confidenceLow
+		result.Task1Result, result.Task2Result, result.Task3Result = survivor.Task1Result, survivor.Task2Result, survivor.Task3Result
+		result.Summary = fmt.Sprintf("Only the %s hypothesis could be checked (%s run failed: %v): %s", survivor.Hypothesis, failed, failedErr, survivor.Summary)
+		return result
+	}
+
+	re, fp := evidenceOf(realBug), evidenceOf(falsePositive)
+	confirmed := re.verdict == "confirmed" || fp.verdict == "confirmed"
+	refuted := re.verdict == "refuted" || fp.verdict == "refuted"
+	primary := realBug
+	switch {
+	case confirmed && refuted:
+		result.Status, result.Confidence = statusCannotDisprove, confidenceLow
+	case confirmed:
+		result.Status = statusBugConfirmed
+		result.Confidence = agreement(re, fp, "confirmed")
+		if fp.verdict == "confirmed" {
+			// Confirmed against its own prior: the stronger run.
+			primary = falsePositive
+		}
+	case refuted:
+		result.Status = statusBugWrong
+		result.Confidence = agreement(re, fp, "refuted")
+		if re.verdict != "refuted" {
+			primary = falsePositive
+		}
+	default:
+		result.Status, result.Confidence = statusCannotDisprove, confidenceLow
+	}
+	result.Task1Result, result.Task2Result, result.Task3Result = primary.Task1Result, primary.Task2Result, primary.Task3Result
+	result.Disagreements = compareRuns(realBug, falsePositive)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Dual-hypothesis verdict: %s (%s confidence). Real-bug run: %s (%s). False-positive run: %s (%s).",
+		result.Status, result.Confidence, realBug.Status, re.detail, falsePositive.Status, fp.detail)
+	if len(result.Disagreements) > 0 {
+		parts := make([]string, 0, len(result.Disagreements))
+		for _, d := range result.Disagreements {
+			parts = append(parts, fmt.Sprintf("%s (real-bug: %s; false-positive: %s)", d.Aspect, d.RealBug, d.FalsePositive))
+		}
+		fmt.Fprintf(&sb, " Disagreements: %s.", strings.Join(parts, "; "))
+	} else {
+		sb.WriteString(" The runs agree on every task.")
+	}
+	result.Summary = sb.String()
+	return result
+}
+
+// evidenceOf reads the deciding task of a run. A task without a valid result
+// file decides nothing.
+func evidenceOf(res *Result) evidence {
+	if t := res.Task3Result; t != nil {
+		switch t.Status {
+		case "BUG_CONFIRMED":
+			return evidence{verdict: "confirmed", detail: "test BUG_CONFIRMED"}
+		case "BUG_REFUTED":
+			return evidence{verdict: "refuted", detail: "test BUG_REFUTED"}
+		}
+		return evidence{verdict: "inconclusive", detail: "test " + t.Status}
+	}
+	if t := res.Task2Result; t != nil && (t.Status == "UNREACHABLE" || t.Status == "INVALID") {
+		return evidence{verdict: "refuted", detail: "reachability " + t.Status}
+	}
+	if t := res.Task1Result; t != nil && t.Status == "INVALID" {
+		return evidence{verdict: "refuted", detail: "formalization INVALID"}
+	}
+	return evidence{verdict: "inconclusive", detail: "no task decided"}
+}
+
+func agreement(re, fp evidence, verdict string) string {
+	if re.verdict == verdict && fp.verdict == verdict {
+		return confidenceHigh
+	}
+	return confidenceMedium
+}
+
+// compareRuns lists the tasks on which the two runs differ.
+func compareRuns(realBug, falsePositive *Result) []Disagreement {
+	var out []Disagreement
+	add := func(aspect, a, b string) {
+		if a != b {
+			out = append(out, Disagreement{Aspect: aspect, RealBug: a, FalsePositive: b})
+		}
+	}
+	add("verdict", realBug.Status, falsePositive.Status)
+
+	add("formalization", task1Status(realBug.Task1Result), task1Status(falsePositive.Task1Result))
+	if a, b := realBug.Task1Result, falsePositive.Task1Result; a != nil && b != nil && a.FormalizedAssertion != nil && b.FormalizedAssertion != nil {
+		ra, rb := formatAssertion(a.FormalizedAssertion), formatAssertion(b.FormalizedAssertion)
+		if normalizeAssertion(ra) != normalizeAssertion(rb) {
+			out = append(out, Disagreement{Aspect: "formalized_assertion", RealBug: ra, FalsePositive: rb})
+		}
+	}
+	add("reachability", task2Status(realBug.Task2Result), task2Status(falsePositive.Task2Result))
+	add("test", task3Status(realBug.Task3Result), task3Status(falsePositive.Task3Result))
+	return out
+}
+
+func task1Status(t *Task1Result) string {
+	if t == nil {
+		return "not run"
+	}
+	return t.Status
+}
+
+func task2Status(t *Task2Result) string {
+	if t == nil {
+		return "not run"
+	}
+	return t.Status
+}
+
+func task3Status(t *Task3Result) string {
+	if t == nil {
+		return "not run"
+	}
+	return t.Status
+}
+
+func formatAssertion(a *FormalizedAssertion) string {
+	return fmt.Sprintf("Precondition: %s\nPath: %s\nPostcondition: %s", a.Precondition, a.Path, a.Postcondition)
+}
+
+func normalizeAssertion(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
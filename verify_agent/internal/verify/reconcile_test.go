@@ -0,0 +1,73 @@
+package verify
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func confirmedRun(hypothesis string) *Result {
+	return &Result{
+		Hypothesis:  hypothesis,
+		Status:      statusBugConfirmed,
+		Task1Result: &Task1Result{Status: "VALID", FormalizedAssertion: &FormalizedAssertion{Precondition: "p", Path: "a -> b", Postcondition: "q"}},
+		Task2Result: &Task2Result{Status: "REACHABLE"},
+		Task3Result: &Task3Result{Status: "BUG_CONFIRMED"},
+	}
+}
+
+func refutedRun(hypothesis string) *Result {
+	return &Result{
+		Hypothesis:  hypothesis,
+		Status:      statusBugWrong,
+		Task1Result: &Task1Result{Status: "VALID", FormalizedAssertion: &FormalizedAssertion{Precondition: "P", Path: "a  -> b", Postcondition: "Q"}},
+		Task2Result: &Task2Result{Status: "UNREACHABLE"},
+	}
+}
+
+func TestReconcileAgreementIsHighConfidence(t *testing.T) {
+	res := reconcile(confirmedRun(HypothesisReal), confirmedRun(HypothesisFalsePositive), nil, nil)
+	if res.Status != statusBugConfirmed || res.Confidence != confidenceHigh || len(res.Disagreements) != 0 {
+		t.Fatalf("unexpected reconciliation %+v", res)
+	}
+	if !strings.Contains(res.Summary, "agree on every task") || res.Task3Result == nil {
+		t.Fatalf("unexpected summary or tasks: %s", res.Summary)
+	}
+}
+
+func TestReconcileProtocolErrorYieldsToRefute(t *testing.T) {
+	realBug, falsePositive := confirmedRun(HypothesisReal), refutedRun(HypothesisFalsePositive)
+	realBug.Status = statusProtocolError
+	realBug.Task3Result = &Task3Result{Status: taskProtocolError, ProtocolError: "result file missing"}
+	res := reconcile(realBug, falsePositive, nil, nil)
+	if res.Status != statusBugWrong || res.Confidence != confidenceMedium {
+		t.Fatalf("expected bug_wrong at medium confidence, got %s/%s", res.Status, res.Confidence)
+	}
+	if res.Task2Result != falsePositive.Task2Result {
+		t.Fatalf("the refuting run must provide the task results")
+	}
+	aspects := map[string]bool{}
+	for _, d := range res.Disagreements {
+		aspects[d.Aspect] = true
+	}
+	if !aspects["verdict"] || !aspects["reachability"] || !aspects["test"] || aspects["formalized_assertion"] {
+		t.Fatalf("unexpected disagreements %+v", res.Disagreements)
+	}
+}
+
+func TestReconcileContradictionCannotDisprove(t *testing.T) {
+	res := reconcile(confirmedRun(HypothesisReal), refutedRun(HypothesisFalsePositive), nil, nil)
+	if res.Status != statusCannotDisprove || res.Confidence != confidenceLow {
+		t.Fatalf("expected cannot_disprove at low confidence, got %s/%s", res.Status, res.Confidence)
+	}
+	if !strings.Contains(res.Summary, "Disagreements: verdict (real-bug: bug_confirmed; false-positive: bug_wrong)") {
+		t.Fatalf("summary must spell out the disagreement: %s", res.Summary)
+	}
+}
+
+func TestReconcileFailedRunKeepsSurvivorAtLowConfidence(t *testing.T) {
+	res := reconcile(nil, refutedRun(HypothesisFalsePositive), errors.New("mcp down"), nil)
+	if res.Status != statusBugWrong || res.Confidence != confidenceLow || !strings.Contains(res.Summary, "real-bug run failed: mcp down") {
+		t.Fatalf("unexpected reconciliation %+v", res)
+	}
+}
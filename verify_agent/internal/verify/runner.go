@@ -10,6 +10,7 @@ import (
 
 	b "verify_agent/internal/brain"
 	"verify_agent/internal/logx"
+	"verify_agent/internal/skills"
 	"verify_agent/internal/streaming"
 	t "verify_agent/internal/tools"
 )
@@ -19,6 +20,9 @@ const (
 	statusBugConfirmed   = "bug_confirmed"
 	statusCannotDisprove = "cannot_disprove"
 	statusError          = "error"
+	// statusProtocolError marks a run a task of which produced no valid
+	// result file; see protocol.go.
+	statusProtocolError = "protocol_error"
 )
 
 // Options configures the verify workflow.
@@ -29,48 +33,107 @@ type Options struct {
 	WorkspaceDir    string
 	CodeContext     string // Optional: additional code context
 	IsFalsePositive bool   // If true, treat bug as false positive (虚假报警); if false, verify as real bug
+	// Hypothesis is HypothesisReal, HypothesisFalsePositive or
+	// HypothesisBoth; empty derives it from IsFalsePositive.
+	Hypothesis string
+	// Skills are the skill packs enabled for this run; each task injects
+	// the ones it declares in phaseSkills.
+	Skills *skills.Set
+	// Chain forks the selected tasks from their predecessor's branch; the
+	// zero value forks every task from ParentBranchID.
+	Chain TaskChain
+	// Bisect runs a git bisect follow-up from Task 3's branch when a test
+	// confirmed the bug. BisectGoodRef is a revision known to pass; empty
+	// lets the agent search the history for one.
+	Bisect        bool
+	BisectGoodRef string
+	// Fix runs Task 4 from Task 3's branch when a test confirmed the bug:
+	// FixAgent (a builder, codex by default) writes a candidate patch.
+	Fix      bool
+	FixAgent string
+}
+
+// phaseSkills declares the skill packs each task accepts.
+var phaseSkills = map[string][]string{
+	"formalization":   {"re2"},
+	"reachability":    {"re2"},
+	"test_generation": {"re2"},
 }
 
 // Result captures the verification outcome.
 type Result struct {
-	BugDescription string       `json:"bug_description"`
-	Status         string       `json:"status"`
-	Summary        string       `json:"summary"`
-	Task1Result    *Task1Result `json:"task1_result,omitempty"`
-	Task2Result    *Task2Result `json:"task2_result,omitempty"`
-	Task3Result    *Task3Result `json:"task3_result,omitempty"`
-	StartBranchID  string       `json:"start_branch_id,omitempty"`
-	LatestBranchID string       `json:"latest_branch_id,omitempty"`
+	BugDescription  string            `json:"bug_description"`
+	Status          string            `json:"status"`
+	Summary         string            `json:"summary"`
+	Task1Result     *Task1Result      `json:"task1_result,omitempty"`
+	Task2Result     *Task2Result      `json:"task2_result,omitempty"`
+	Task3Result     *Task3Result      `json:"task3_result,omitempty"`
+	StartBranchID   string            `json:"start_branch_id,omitempty"`
+	LatestBranchID  string            `json:"latest_branch_id,omitempty"`
+	PromptTemplates map[string]string `json:"prompt_templates,omitempty"`
+	Skills          []skills.Usage    `json:"skills,omitempty"`
+	// Hypothesis is the assumption the pipeline started from. In
+	// HypothesisBoth mode the two runs are attached and reconciled into
+	// Status, Confidence and Disagreements; see reconcile.go.
+	Hypothesis       string         `json:"hypothesis,omitempty"`
+	Confidence       string         `json:"confidence,omitempty"`
+	Disagreements    []Disagreement `json:"disagreements,omitempty"`
+	RealBugRun       *Result        `json:"real_bug_run,omitempty"`
+	FalsePositiveRun *Result        `json:"false_positive_run,omitempty"`
+	// LineageMode is parent, chained or mixed; Lineage lists the branch each
+	// task forked from.
+	LineageMode string        `json:"lineage_mode,omitempty"`
+	Lineage     []TaskLineage `json:"lineage,omitempty"`
+	// Assertions holds the per-assertion outcomes of a compound claim; Status
+	// aggregates them and the task results are those of the deciding one.
+	Assertions []AssertionOutcome `json:"assertions,omitempty"`
+	// Bisect names the commit that introduced a confirmed bug when
+	// Options.Bisect is set.
+	Bisect *BisectResult `json:"bisect,omitempty"`
+	// Fix is the candidate patch of Task 4 when Options.Fix is set.
+	Fix *FixResult `json:"fix,omitempty"`
 }
 
 // Task1Result represents the output of Task 1: Bug Claim Formalization
 type Task1Result struct {
 	BranchID            string               `json:"branch_id"`
-	Status              string               `json:"status"` // VALID or INVALID
+	Status              string               `json:"status"` // VALID, INVALID or PROTOCOL_ERROR
 	BugClaim            string               `json:"bug_claim,omitempty"`
 	Judgment            string               `json:"judgment,omitempty"`
 	FormalizedAssertion *FormalizedAssertion `json:"formalized_assertion,omitempty"`
-	Response            string               `json:"response"`
-	Reason              string               `json:"reason,omitempty"`
-	Analysis            string               `json:"analysis,omitempty"`
+	// FormalizedAssertions lists every failure mode of a compound claim;
+	// it is set only when there is more than one, FormalizedAssertion
+	// being the first.
+	FormalizedAssertions []FormalizedAssertion `json:"formalized_assertions,omitempty"`
+	Response             string                `json:"response"`
+	Reason               string                `json:"reason,omitempty"`
+	Analysis             string                `json:"analysis,omitempty"`
+	// ProtocolError explains why Status is PROTOCOL_ERROR; Repairs counts
+	// the repair branches run for the result file.
+	ProtocolError string `json:"protocol_error,omitempty"`
+	Repairs       int    `json:"repairs,omitempty"`
 }
 
 // Task2Result represents the output of Task 2: Reachability Analysis
 type Task2Result struct {
 	BranchID             string `json:"branch_id"`
-	Status               string `json:"status"` // REACHABLE, UNREACHABLE, or INVALID
+	Status               string `json:"status"` // REACHABLE, UNREACHABLE, INVALID or PROTOCOL_ERROR
 	FormalizedAssertion  string `json:"formalized_assertion,omitempty"`
 	Judgment             string `json:"judgment,omitempty"`
 	Response             string `json:"response"`
 	Reason               string `json:"reason,omitempty"`
 	Evidence             string `json:"evidence,omitempty"`
 	ReachabilityAnalysis string `json:"reachability_analysis,omitempty"`
+	// ProtocolError explains why Status is PROTOCOL_ERROR; Repairs counts
+	// the repair branches run for the result file.
+	ProtocolError string `json:"protocol_error,omitempty"`
+	Repairs       int    `json:"repairs,omitempty"`
 }
 
 // Task3Result represents the output of Task 3: Test Generator
 type Task3Result struct {
 	BranchID            string `json:"branch_id"`
-	Status              string `json:"status"` // BUG_CONFIRMED, BUG_REFUTED, or TEST_INCONCLUSIVE
+	Status              string `json:"status"` // BUG_CONFIRMED, BUG_REFUTED, TEST_INCONCLUSIVE or PROTOCOL_ERROR
 	BugClaim            string `json:"bug_claim,omitempty"`
 	FormalizedAssertion string `json:"formalized_assertion,omitempty"`
 	Judgment            string `json:"judgment,omitempty"`
@@ -78,6 +141,13 @@ type Task3Result struct {
 	TestCase            string `json:"test_case,omitempty"`
 	TestExecution       string `json:"test_execution,omitempty"`
 	Analysis            string `json:"analysis,omitempty"`
+	// ProtocolError explains why Status is PROTOCOL_ERROR; Repairs counts
+	// the repair branches run for the result file.
+	ProtocolError string `json:"protocol_error,omitempty"`
+	Repairs       int    `json:"repairs,omitempty"`
+	// Artifact is the test file and run log read back from the branch; nil
+	// when the agent did not leave them.
+	Artifact *TestArtifact `json:"artifact,omitempty"`
 }
 
 // Runner executes the three-phase bug verification workflow.
@@ -101,6 +171,8 @@ func NewRunner(brain *b.LLMBrain, handler *t.ToolHandler, streamer *streaming.JS
 	opts.ProjectName = strings.TrimSpace(opts.ProjectName)
 	opts.ParentBranchID = strings.TrimSpace(opts.ParentBranchID)
 	opts.WorkspaceDir = strings.TrimSpace(opts.WorkspaceDir)
+	opts.BisectGoodRef = strings.TrimSpace(opts.BisectGoodRef)
+	opts.FixAgent = strings.TrimSpace(opts.FixAgent)
 	if opts.BugDescription == "" {
 		return nil, errors.New("bug description is required")
 	}
@@ -110,6 +182,22 @@ func NewRunner(brain *b.LLMBrain, handler *t.ToolHandler, streamer *streaming.JS
 	if opts.ParentBranchID == "" {
 		return nil, errors.New("parent branch id is required")
 	}
+	switch opts.Hypothesis = strings.TrimSpace(opts.Hypothesis); opts.Hypothesis {
+	case "":
+		opts.Hypothesis = HypothesisReal
+		if opts.IsFalsePositive {
+			opts.Hypothesis = HypothesisFalsePositive
+		}
+	case HypothesisReal:
+		if opts.IsFalsePositive {
+			return nil, errors.New("hypothesis real conflicts with the false-positive assumption")
+		}
+	case HypothesisFalsePositive:
+		opts.IsFalsePositive = true
+	case HypothesisBoth:
+	default:
+		return nil, fmt.Errorf("unknown hypothesis %q (want %s, %s or %s)", opts.Hypothesis, HypothesisReal, HypothesisFalsePositive, HypothesisBoth)
+	}
 	return &Runner{
 		brain:    brain,
 		handler:  handler,
@@ -119,18 +207,46 @@ func NewRunner(brain *b.LLMBrain, handler *t.ToolHandler, streamer *streaming.JS
 	}, nil
 }
 
-// Run executes the three-task workflow and returns the structured result.
+// Run executes the three-task workflow and returns the structured result. In
+// HypothesisBoth mode it runs the workflow once per hypothesis and reconciles
+// the two.
 func (r *Runner) Run() (*Result, error) {
-	logx.Infof("Starting bug verification workflow for bug: %s", r.opts.BugDescription)
-	parent := r.opts.ParentBranchID
+	var (
+		result *Result
+		err    error
+	)
+	if r.opts.Hypothesis == HypothesisBoth {
+		result, err = r.runBoth()
+	} else {
+		result, err = r.runHypothesis()
+	}
+	if err == nil && r.opts.Bisect && testConfirmed(result) {
+		r.runBisect(result)
+	}
+	if err == nil && r.opts.Fix && testConfirmed(result) {
+		r.runFix(result)
+	}
+	if result != nil {
+		result.Skills = r.opts.Skills.Used()
+	}
+	return result, err
+}
+
+// runHypothesis runs the three tasks under the runner's single hypothesis.
+func (r *Runner) runHypothesis() (*Result, error) {
+	logx.Infof("Starting bug verification workflow (%s hypothesis, %s lineage) for bug: %s", r.opts.Hypothesis, r.opts.Chain.Mode(), r.opts.BugDescription)
 
 	result := &Result{
-		BugDescription: r.opts.BugDescription,
+		BugDescription:  r.opts.BugDescription,
+		PromptTemplates: PromptVersions(),
+		Hypothesis:      r.opts.Hypothesis,
+		LineageMode:     r.opts.Chain.Mode(),
 	}
 
 	// Task 1: Bug Claim Formalization
 	logx.Infof("Task 1: Formalizing bug claim")
-	task1Result, err := r.runTask1(parent)
+	parent, _ := r.forkPoint(&result.Lineage, "task1", false, "")
+	task1Result, err := r.runTask1(parent, r.handoff(false, "", r.opts.Chain.Task2))
 	if err != nil {
 		return nil, fmt.Errorf("task 1 failed: %w", err)
 	}
@@ -148,270 +264,140 @@ func (r *Runner) Run() (*Result, error) {
 		return result, nil
 	}
 
-	if task1Result.FormalizedAssertion == nil {
-		// This should not happen if status is VALID and parsing succeeded
-		// But if it does, treat as INVALID
-		result.Status = statusBugWrong
-		reason := task1Result.Reason
-		if reason == "" {
-			reason = "Task 1 reported VALID status but did not produce a valid formalized assertion"
-		}
-		if r.opts.IsFalsePositive {
-			result.Summary = fmt.Sprintf("Bug claim confirmed as FALSE POSITIVE (parsing failed): %s", reason)
-		} else {
-			result.Summary = fmt.Sprintf("Bug claim is invalid (parsing failed): %s", reason)
-		}
+	if task1Result.Status == taskProtocolError {
+		result.Status = statusProtocolError
+		result.Summary = fmt.Sprintf("Task 1 did not produce a valid result file: %s", task1Result.ProtocolError)
 		r.attachBranchRange(result)
 		return result, nil
 	}
 
-	// Task 2: Reachability Analysis
-	logx.Infof("Task 2: Analyzing reachability")
-	task2Result, err := r.runTask2(parent, task1Result.FormalizedAssertion, task1Result.Response)
-	if err != nil {
-		return nil, fmt.Errorf("task 2 failed: %w", err)
-	}
-	result.Task2Result = task2Result
-
-	// Check if Task 2 found the state unreachable
-	if task2Result.Status == "UNREACHABLE" || task2Result.Status == "INVALID" {
-		result.Status = statusBugWrong
-		if r.opts.IsFalsePositive {
-			result.Summary = fmt.Sprintf("Bug claim confirmed as FALSE POSITIVE (unreachable): %s", task2Result.Reason)
-		} else {
-			result.Summary = fmt.Sprintf("Bug state is unreachable: %s", task2Result.Reason)
+	if len(task1Result.FormalizedAssertions) > 1 {
+		if err := r.runAssertions(result, task1Result); err != nil {
+			return nil, err
 		}
 		r.attachBranchRange(result)
 		return result, nil
 	}
 
-	// Task 3: Test Generator
-	logx.Infof("Task 3: Generating test case")
-	task3Result, err := r.runTask3(parent, task1Result.FormalizedAssertion, task2Result.Response)
+	outcome, err := r.verifyAssertion(&result.Lineage, task1Result, task1Result.FormalizedAssertion)
 	if err != nil {
-		return nil, fmt.Errorf("task 3 failed: %w", err)
-	}
-	result.Task3Result = task3Result
-
-	// Determine final result based on IsFalsePositive assumption
-	if r.opts.IsFalsePositive {
-		// We assume the bug is FALSE. We've tried to prove it wrong.
-		// If we found evidence it's wrong (refuted), report bug_wrong.
-		// If we cannot disprove it despite our assumption, we still conclude it's wrong
-		// (because our assumption is that it's false, and we haven't found strong evidence otherwise).
-		if task3Result.Status == "BUG_REFUTED" {
-			result.Status = statusBugWrong
-			summaryText := task3Result.Judgment
-			if summaryText == "" {
-				summaryText = task3Result.Analysis
-			}
-			if summaryText == "" {
-				summaryText = "Bug claim refuted by test"
-			}
-			result.Summary = fmt.Sprintf("Bug claim confirmed as FALSE POSITIVE: %s", summaryText)
-		} else if task3Result.Status == "BUG_CONFIRMED" {
-			// If test actually confirms the bug despite our false positive assumption,
-			// this proves the assumption was WRONG - the bug is actually REAL
-			// We must report bug_confirmed because the evidence is irrefutable
-			result.Status = statusBugConfirmed
-			summaryText := task3Result.Judgment
-			if summaryText == "" {
-				summaryText = task3Result.Analysis
-			}
-			if summaryText == "" {
-				summaryText = "Bug claim confirmed by test"
-			}
-			result.Summary = fmt.Sprintf("ASSUMPTION WAS WRONG: Bug was assumed FALSE POSITIVE but test CONFIRMED it is REAL. %s", summaryText)
-		} else {
-			// TEST_INCONCLUSIVE - we couldn't disprove it through testing,
-			// but based on our assumption that it's false, we conclude it's likely false
-			result.Status = statusBugWrong
-			result.Summary = "Bug claim is likely FALSE POSITIVE: Cannot disprove through test, but assumption and evidence suggest it is not a real bug"
-		}
-	} else {
-		// We assume the bug is REAL. We're trying to confirm it.
-		// If test confirms it, report bug_confirmed.
-		// If test refutes it, report bug_wrong.
-		// If test is inconclusive, report cannot_disprove (we assume it's real but can't prove).
-		if task3Result.Status == "BUG_CONFIRMED" {
-			result.Status = statusBugConfirmed
-			summaryText := task3Result.Judgment
-			if summaryText == "" {
-				summaryText = task3Result.Analysis
-			}
-			if summaryText == "" {
-				summaryText = "Bug claim confirmed by test"
-			}
-			result.Summary = fmt.Sprintf("Bug claim CONFIRMED as REAL: %s", summaryText)
-		} else if task3Result.Status == "BUG_REFUTED" {
-			result.Status = statusBugWrong
-			summaryText := task3Result.Judgment
-			if summaryText == "" {
-				summaryText = task3Result.Analysis
-			}
-			if summaryText == "" {
-				summaryText = "Bug claim refuted by test"
-			}
-			result.Summary = fmt.Sprintf("Bug claim refuted: %s", summaryText)
-		} else {
-			// TEST_INCONCLUSIVE - we couldn't confirm it through testing
-			// Since we assume it's real, we still lean towards it being real
-			result.Status = statusBugConfirmed
-			result.Summary = "Bug claim assumed REAL: Test was inconclusive, but assumption and evidence suggest it is a real bug"
-		}
+		return nil, err
 	}
+	result.Task2Result, result.Task3Result = outcome.Task2Result, outcome.Task3Result
+	result.Status, result.Summary = outcome.Status, outcome.Summary
 	r.attachBranchRange(result)
 	return result, nil
 }
 
-func (r *Runner) runTask1(parentBranchID string) (*Task1Result, error) {
-	prompt := buildFormalizationPrompt(r.opts.BugDescription, r.opts.CodeContext, r.opts.IsFalsePositive)
+func (r *Runner) runTask1(parentBranchID string, handoff StateHandoff) (*Task1Result, error) {
+	prompt := r.withSkills("formalization", buildFormalizationPrompt(r.opts.BugDescription, r.opts.CodeContext, r.opts.IsFalsePositive, r.workspacePath(task1ResultPath), handoff))
 	data, err := r.executeAgent("codex", prompt, parentBranchID)
 	if err != nil {
 		return nil, err
 	}
-	branchID := stringField(data, "branch_id")
 	response := strings.TrimSpace(stringField(data, "response"))
 
-	status := extractStatus(response, []string{"VALID", "INVALID"})
-	if status == "" {
-		// Default based on IsFalsePositive assumption
-		// If IsFalsePositive=true, assume INVALID (prove it's false)
-		// If IsFalsePositive=false, assume VALID (prove it's real)
-		if r.opts.IsFalsePositive {
-			status = "INVALID"
-		} else {
-			status = "VALID"
-		}
-	}
-
-	result := &Task1Result{
-		BranchID: branchID,
-		Status:   status,
-		Response: response,
+	var v task1Verdict
+	branchID, repairs, problem := r.readVerdict(task1Spec, stringField(data, "branch_id"), response, func(content string) error {
+		v = task1Verdict{}
+		return decodeVerdict(content, &v)
+	})
+	result := &Task1Result{BranchID: branchID, Response: response, Repairs: repairs}
+	if problem != nil {
+		result.Status, result.ProtocolError = taskProtocolError, problem.Error()
+		return result, nil
 	}
 
-	// Extract bug claim and judgment (should be before analysis)
-	result.BugClaim = extractBugClaim(response)
-	result.Judgment = extractJudgment(response)
-
-	if status == "VALID" {
-		// Try to parse the formalized assertion
-		assertion, err := parseFormalizedAssertion(response)
-		if err != nil {
-			logx.Warningf("Failed to parse formalized assertion: %v. Response preview: %s", err, truncateString(response, 500))
-			// If we can't parse the assertion, treat it as INVALID
-			result.Status = "INVALID"
-			result.Reason = fmt.Sprintf("Failed to parse formalized assertion: %v. The response may not contain valid JSON.", err)
-			// Try to extract reason from response as fallback
-			if extractedReason := extractReason(response); extractedReason != "" {
-				result.Reason = extractedReason
-			}
-		} else {
-			result.FormalizedAssertion = &assertion
+	result.Status = v.Status
+	result.BugClaim = strings.TrimSpace(v.BugClaim)
+	result.Judgment = strings.TrimSpace(v.Judgment)
+	result.Reason = strings.TrimSpace(v.Reason)
+	result.Analysis = strings.TrimSpace(v.Analysis)
+	if v.Status == "VALID" {
+		result.FormalizedAssertion = &v.Assertions[0]
+		if len(v.Assertions) > 1 {
+			result.FormalizedAssertions = v.Assertions
 		}
-	} else {
-		// Extract reason from response
-		result.Reason = extractReason(response)
 	}
-
-	// Extract analysis (should be after bug claim and judgment)
-	result.Analysis = extractSection(response, "Analysis")
-
 	return result, nil
 }
 
-func (r *Runner) runTask2(parentBranchID string, assertion *FormalizedAssertion, task1Response string) (*Task2Result, error) {
-	// Format the formalized assertion as a string
-	assertionStr := fmt.Sprintf("Precondition: %s\nPath: %s\nPostcondition: %s",
-		assertion.Precondition, assertion.Path, assertion.Postcondition)
+func (r *Runner) runTask2(parentBranchID string, assertion *FormalizedAssertion, handoff StateHandoff) (*Task2Result, error) {
+	assertionStr := formatAssertion(assertion)
 
-	prompt := buildReachabilityPrompt(assertionStr, r.opts.CodeContext, r.opts.IsFalsePositive)
+	prompt := r.withSkills("reachability", buildReachabilityPrompt(assertionStr, r.opts.CodeContext, r.opts.IsFalsePositive, r.workspacePath(task2ResultPath), handoff))
 	data, err := r.executeAgent("codex", prompt, parentBranchID)
 	if err != nil {
 		return nil, err
 	}
-	branchID := stringField(data, "branch_id")
 	response := strings.TrimSpace(stringField(data, "response"))
 
-	status := extractStatus(response, []string{"REACHABLE", "UNREACHABLE", "INVALID"})
-	if status == "" {
-		// Default based on IsFalsePositive assumption
-		// If IsFalsePositive=true, assume UNREACHABLE (prove it's false)
-		// If IsFalsePositive=false, assume REACHABLE (prove it's real)
-		if r.opts.IsFalsePositive {
-			status = "UNREACHABLE"
-		} else {
-			status = "REACHABLE"
-		}
-	}
-
-	result := &Task2Result{
-		BranchID: branchID,
-		Status:   status,
-		Response: response,
-	}
-
-	// Extract formalized assertion and judgment (should be before analysis)
-	result.FormalizedAssertion = extractSection(response, "Formalized Assertion")
-	result.Judgment = extractJudgment(response)
-
-	if status == "UNREACHABLE" || status == "INVALID" {
-		result.Reason = extractReason(response)
-		result.Evidence = extractEvidence(response)
-	} else {
-		// Extract reachability analysis
-		result.ReachabilityAnalysis = extractSection(response, "Reachability Analysis")
-		result.Evidence = extractEvidence(response)
+	var v task2Verdict
+	branchID, repairs, problem := r.readVerdict(task2Spec, stringField(data, "branch_id"), response, func(content string) error {
+		v = task2Verdict{}
+		return decodeVerdict(content, &v)
+	})
+	result := &Task2Result{BranchID: branchID, FormalizedAssertion: assertionStr, Response: response, Repairs: repairs}
+	if problem != nil {
+		result.Status, result.ProtocolError = taskProtocolError, problem.Error()
+		return result, nil
 	}
 
+	result.Status = v.Status
+	result.Judgment = strings.TrimSpace(v.Judgment)
+	result.Reason = strings.TrimSpace(v.Reason)
+	result.Evidence = strings.TrimSpace(v.Evidence)
+	result.ReachabilityAnalysis = strings.TrimSpace(v.ReachabilityAnalysis)
 	return result, nil
 }
 
-func (r *Runner) runTask3(parentBranchID string, assertion *FormalizedAssertion, task2Response string) (*Task3Result, error) {
-	// Format the formalized assertion as a string
-	assertionStr := fmt.Sprintf("Precondition: %s\nPath: %s\nPostcondition: %s",
-		assertion.Precondition, assertion.Path, assertion.Postcondition)
+func (r *Runner) runTask3(parentBranchID string, assertion *FormalizedAssertion, reachabilityAnalysis string, handoff StateHandoff) (*Task3Result, error) {
+	assertionStr := formatAssertion(assertion)
 
-	prompt := buildTestGeneratorPrompt(assertionStr, task2Response, r.opts.CodeContext, r.opts.IsFalsePositive)
+	prompt := r.withSkills("test_generation", buildTestGeneratorPrompt(assertionStr, reachabilityAnalysis, r.opts.CodeContext, r.opts.IsFalsePositive, r.workspacePath(reproManifestPath), r.workspacePath(reproLogPath), r.workspacePath(reproDiffPath), r.workspacePath(task3ResultPath), handoff))
 	data, err := r.executeAgent("codex", prompt, parentBranchID)
 	if err != nil {
 		return nil, err
 	}
-	branchID := stringField(data, "branch_id")
 	response := strings.TrimSpace(stringField(data, "response"))
 
-	status := extractStatus(response, []string{"BUG_CONFIRMED", "BUG_REFUTED", "TEST_INCONCLUSIVE"})
-	if status == "" {
-		// Default based on IsFalsePositive assumption
-		// If IsFalsePositive=true, assume BUG_REFUTED (prove it's false)
-		// If IsFalsePositive=false, assume BUG_CONFIRMED (prove it's real)
-		if r.opts.IsFalsePositive {
-			status = "BUG_REFUTED"
-		} else {
-			status = "BUG_CONFIRMED"
-		}
-	}
-
-	result := &Task3Result{
-		BranchID: branchID,
-		Status:   status,
-		Response: response,
+	var v task3Verdict
+	branchID, repairs, problem := r.readVerdict(task3Spec, stringField(data, "branch_id"), response, func(content string) error {
+		v = task3Verdict{}
+		return decodeVerdict(content, &v)
+	})
+	result := &Task3Result{BranchID: branchID, BugClaim: r.opts.BugDescription, FormalizedAssertion: assertionStr, Response: response, Repairs: repairs}
+	if problem != nil {
+		result.Status, result.ProtocolError = taskProtocolError, problem.Error()
+		return result, nil
 	}
 
-	// Extract bug claim, formalized assertion, and judgment (should be before analysis)
-	result.BugClaim = extractBugClaim(response)
-	result.FormalizedAssertion = extractSection(response, "Formalized Assertion")
-	result.Judgment = extractJudgment(response)
+	result.Status = v.Status
+	result.Judgment = strings.TrimSpace(v.Judgment)
+	result.TestCase = strings.TrimSpace(v.TestCase)
+	result.TestExecution = strings.TrimSpace(v.TestExecution)
+	result.Analysis = strings.TrimSpace(v.Analysis)
 
-	// Extract test case, execution, and analysis
-	result.TestCase = extractSection(response, "Test Case")
-	result.TestExecution = extractSection(response, "Test Execution")
-	result.Analysis = extractSection(response, "Analysis")
+	// Prefer the files on the branch over the test quoted in the result file.
+	artifact, err := r.collectTestArtifact(branchID)
+	if err != nil {
+		logx.Warningf("Task 3 reproduction test artifact unavailable on branch %s: %v", branchID, err)
+	} else {
+		result.Artifact = artifact
+		result.TestCase = artifact.Content
+		if artifact.Log != "" {
+			result.TestExecution = artifact.Log
+		}
+	}
 
 	return result, nil
 }
 
+// withSkills injects the enabled skills phase declares into prompt.
+func (r *Runner) withSkills(phase, prompt string) string {
+	return r.opts.Skills.Apply(phase, phaseSkills[phase], prompt)
+}
+
 func (r *Runner) executeAgent(agent, prompt, parentBranchID string) (map[string]any, error) {
 	args := map[string]any{
 		"agent":            agent,
@@ -505,86 +491,6 @@ func extractError(resp map[string]any) string {
 	return "unknown error"
 }
 
-func extractBugClaim(response string) string {
-	lines := strings.Split(response, "\n")
-	inBugClaim := false
-	var bugClaim strings.Builder
-	for _, line := range lines {
-		if strings.Contains(line, "## Bug Claim") {
-			inBugClaim = true
-			continue
-		}
-		if inBugClaim {
-			if strings.HasPrefix(line, "##") || strings.HasPrefix(line, "# STATUS:") {
-				break
-			}
-			bugClaim.WriteString(line)
-			bugClaim.WriteString("\n")
-		}
-	}
-	return strings.TrimSpace(bugClaim.String())
-}
-
-func extractJudgment(response string) string {
-	lines := strings.Split(response, "\n")
-	inJudgment := false
-	var judgment strings.Builder
-	for _, line := range lines {
-		if strings.Contains(line, "## Judgment") {
-			inJudgment = true
-			continue
-		}
-		if inJudgment {
-			if strings.HasPrefix(line, "##") {
-				break
-			}
-			judgment.WriteString(line)
-			judgment.WriteString("\n")
-		}
-	}
-	return strings.TrimSpace(judgment.String())
-}
-
-func extractReason(response string) string {
-	lines := strings.Split(response, "\n")
-	inReason := false
-	var reason strings.Builder
-	for _, line := range lines {
-		if strings.Contains(line, "## Reason") {
-			inReason = true
-			continue
-		}
-		if inReason {
-			if strings.HasPrefix(line, "##") {
-				break
-			}
-			reason.WriteString(line)
-			reason.WriteString("\n")
-		}
-	}
-	return strings.TrimSpace(reason.String())
-}
-
-func extractEvidence(response string) string {
-	lines := strings.Split(response, "\n")
-	inEvidence := false
-	var evidence strings.Builder
-	for _, line := range lines {
-		if strings.Contains(line, "## Evidence") {
-			inEvidence = true
-			continue
-		}
-		if inEvidence {
-			if strings.HasPrefix(line, "##") {
-				break
-			}
-			evidence.WriteString(line)
-			evidence.WriteString("\n")
-		}
-	}
-	return strings.TrimSpace(evidence.String())
-}
-
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -592,24 +498,19 @@ func truncateString(s string, maxLen int) string {
 	return s[:maxLen] + "..."
 }
 
-func extractSection(response string, sectionName string) string {
-	lines := strings.Split(response, "\n")
-	inSection := false
-	var section strings.Builder
-	for _, line := range lines {
-		if strings.Contains(line, "## "+sectionName) {
-			inSection = true
-			continue
-		}
-		if inSection {
-			if strings.HasPrefix(line, "##") {
-				break
-			}
-			section.WriteString(line)
-			section.WriteString("\n")
-		}
+// EmitCompleted emits the thread.completed event of a finished run. The
+// final report is result itself, so stream consumers decode the same Result
+// verify-agent prints.
+func EmitCompleted(streamer *streaming.JSONStreamer, result *Result) {
+	if result == nil {
+		return
+	}
+	status := "completed"
+	switch result.Status {
+	case statusBugWrong, statusBugConfirmed, statusCannotDisprove, statusProtocolError, statusError:
+		status = result.Status
 	}
-	return strings.TrimSpace(section.String())
+	streamer.EmitThreadCompleted(status, result.Summary, result)
 }
 
 type eventHelper struct {
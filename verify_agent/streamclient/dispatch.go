@@ -0,0 +1,115 @@
+package streamclient
+
+import (
+	"errors"
+	"io"
+)
+
+// Handler receives dispatched events. Nil callbacks are skipped, so a
+// consumer sets only the ones it needs. The result callbacks run after
+// OnThreadCompleted with the decoded final report of their agent.
+type Handler struct {
+	OnThreadStarted    func(*ThreadStarted)
+	OnTurnStarted      func(*TurnStarted)
+	OnAssistantMessage func(*AssistantMessage)
+	OnTurnCompleted    func(*TurnCompleted)
+	OnItemStarted      func(*ItemStarted)
+	OnItemCompleted    func(*ItemCompleted)
+	OnThreadCompleted  func(*ThreadCompleted)
+	OnError            func(*Error)
+	// OnUnknown receives event types added by a newer minor version.
+	OnUnknown func(*Unknown)
+
+	OnVerifyResult func(*ThreadCompleted, *VerifyReport)
+	OnReviewResult func(*ThreadCompleted, *ReviewReport)
+	OnDevResult    func(*ThreadCompleted, *DevReport)
+}
+
+// Dispatch passes ev to its callback. It fails only when a final report
+// that a result callback asked for cannot be decoded.
+func (h *Handler) Dispatch(ev Event) error {
+	switch ev := ev.(type) {
+	case *ThreadStarted:
+		if h.OnThreadStarted != nil {
+			h.OnThreadStarted(ev)
+		}
+	case *TurnStarted:
+		if h.OnTurnStarted != nil {
+			h.OnTurnStarted(ev)
+		}
+	case *AssistantMessage:
+		if h.OnAssistantMessage != nil {
+			h.OnAssistantMessage(ev)
+		}
+	case *TurnCompleted:
+		if h.OnTurnCompleted != nil {
+			h.OnTurnCompleted(ev)
+		}
+	case *ItemStarted:
+		if h.OnItemStarted != nil {
+			h.OnItemStarted(ev)
+		}
+	case *ItemCompleted:
+		if h.OnItemCompleted != nil {
+			h.OnItemCompleted(ev)
+		}
+	case *Error:
+		if h.OnError != nil {
+			h.OnError(ev)
+		}
+	case *Unknown:
+		if h.OnUnknown != nil {
+			h.OnUnknown(ev)
+		}
+	case *ThreadCompleted:
+		if h.OnThreadCompleted != nil {
+			h.OnThreadCompleted(ev)
+		}
+		return h.dispatchResult(ev)
+	}
+	return nil
+}
+
+// dispatchResult decodes the final report of ev for the result callback of
+// its agent, if one is set.
+func (h *Handler) dispatchResult(ev *ThreadCompleted) error {
+	switch {
+	case ev.Agent == AgentVerify && h.OnVerifyResult != nil:
+		var report VerifyReport
+		if ok, err := DecodeReport(ev, &report); !ok {
+			return err
+		}
+		h.OnVerifyResult(ev, &report)
+	case ev.Agent == AgentReview && h.OnReviewResult != nil:
+		var report ReviewReport
+		if ok, err := DecodeReport(ev, &report); !ok {
+			return err
+		}
+		h.OnReviewResult(ev, &report)
+	case (ev.Agent == AgentDev || ev.Agent == AgentDevV2) && h.OnDevResult != nil:
+		var report DevReport
+		if ok, err := DecodeReport(ev, &report); !ok {
+			return err
+		}
+		h.OnDevResult(ev, &report)
+	}
+	return nil
+}
+
+// Consume reads r to the end and dispatches every event to h. It stops at
+// the first invalid event or failing dispatch.
+func Consume(r io.Reader, h *Handler) error {
+	events := NewReader(r)
+	for {
+		ev, err := events.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := h.Dispatch(ev); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package streamclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"verify_agent/internal/skills"
+	"verify_agent/internal/verify"
+)
+
+// VerifyReport is the final report of a verify-agent run.
+type VerifyReport = verify.Result
+
+// ReviewReport is the final report of a review-agent run.
+type ReviewReport struct {
+	Task            string            `json:"task"`
+	Status          string            `json:"status"`
+	Summary         string            `json:"summary"`
+	Issues          []ReviewIssue     `json:"issues"`
+	PromptTemplates map[string]string `json:"prompt_templates,omitempty"`
+	Skills          []skills.Usage    `json:"skills,omitempty"`
+	GitHubReview    *GitHubReview     `json:"github_review,omitempty"`
+}
+
+// ReviewIssue is one issue a review reported, with its final status.
+type ReviewIssue struct {
+	IssueText          string `json:"issue_text"`
+	Priority           string `json:"priority,omitempty"`
+	Confidence         string `json:"confidence,omitempty"`
+	Status             string `json:"status,omitempty"`
+	ExchangeRounds     int    `json:"exchange_rounds,omitempty"`
+	VerdictExplanation string `json:"verdict_explanation,omitempty"`
+	CarriedFrom        string `json:"carried_from,omitempty"`
+	// FormalVerification is the verify-agent outcome when the review ran
+	// with --formal-verify.
+	FormalVerification json.RawMessage `json:"formal_verification,omitempty"`
+}
+
+// GitHubReview is the pull request review a review-agent run published.
+type GitHubReview struct {
+	PullRequest string `json:"pull_request"`
+	ReviewID    int64  `json:"review_id,omitempty"`
+	Created     int    `json:"created"`
+	Updated     int    `json:"updated"`
+	Unanchored  int    `json:"unanchored"`
+	Resolved    int    `json:"resolved,omitempty"`
+}
+
+// DevReport is the final report of a dev-agent or dev-agent-v2 run.
+type DevReport struct {
+	Task            string            `json:"task"`
+	Status          string            `json:"status"`
+	Summary         string            `json:"summary"`
+	IsFinished      bool              `json:"is_finished,omitempty"`
+	StartBranchID   string            `json:"start_branch_id,omitempty"`
+	LatestBranchID  string            `json:"latest_branch_id,omitempty"`
+	Instructions    string            `json:"instructions,omitempty"`
+	DryRun          bool              `json:"dry_run,omitempty"`
+	PromptTemplates map[string]string `json:"prompt_templates,omitempty"`
+	PublishReport   json.RawMessage   `json:"publish_report,omitempty"`
+}
+
+// DecodeReport decodes the final report of ev into v. It reports false when
+// the event carries no report.
+func DecodeReport(ev *ThreadCompleted, v any) (bool, error) {
+	if len(ev.FinalReport) == 0 {
+		return false, nil
+	}
+	if err := json.Unmarshal(ev.FinalReport, v); err != nil {
+		return false, fmt.Errorf("decode %s final report: %w", ev.Agent, err)
+	}
+	return true, nil
+}
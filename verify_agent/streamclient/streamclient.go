@@ -0,0 +1,183 @@
+// Package streamclient reads the NDJSON events the agents emit with
+// --stream-json or --stream-sinks: it parses each line into a typed event,
+// validates it against the event schema and dispatches it, decoding the
+// final report of verify-agent, review-agent and dev-agent runs.
+package streamclient
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"verify_agent/internal/streaming"
+)
+
+// SchemaVersion is the schema version this package reads. Streams of another
+// major version are rejected.
+const SchemaVersion = streaming.SchemaVersion
+
+// Event types.
+const (
+	EventThreadStarted    = streaming.EventThreadStarted
+	EventTurnStarted      = streaming.EventTurnStarted
+	EventAssistantMessage = streaming.EventAssistantMessage
+	EventTurnCompleted    = streaming.EventTurnCompleted
+	EventItemStarted      = streaming.EventItemStarted
+	EventItemCompleted    = streaming.EventItemCompleted
+	EventThreadCompleted  = streaming.EventThreadCompleted
+	EventError            = streaming.EventError
+)
+
+// Agents named in the envelope.
+const (
+	AgentDev          = streaming.AgentDev
+	AgentDevV2        = streaming.AgentDevV2
+	AgentReview       = streaming.AgentReview
+	AgentVerify       = streaming.AgentVerify
+	AgentLogicAnalyst = streaming.AgentLogicAnalyst
+)
+
+// Typed events; see streaming/events.go for the fields.
+type (
+	Event            = streaming.Event
+	Envelope         = streaming.Envelope
+	ThreadStarted    = streaming.ThreadStarted
+	TurnStarted      = streaming.TurnStarted
+	AssistantMessage = streaming.AssistantMessage
+	TurnCompleted    = streaming.TurnCompleted
+	ItemStarted      = streaming.ItemStarted
+	ItemCompleted    = streaming.ItemCompleted
+	ThreadCompleted  = streaming.ThreadCompleted
+	Error            = streaming.Error
+)
+
+// Unknown is an event of a type this version does not know, as a newer minor
+// version may add. Raw is the whole line.
+type Unknown struct {
+	Envelope
+	Raw json.RawMessage `json:"-"`
+}
+
+// Schema returns the JSON Schema of one event line.
+func Schema() []byte {
+	return append([]byte(nil), streaming.Schema...)
+}
+
+// Parse decodes and validates one event line.
+func Parse(line []byte) (Event, error) {
+	var env Envelope
+	if err := json.Unmarshal(line, &env); err != nil {
+		return nil, fmt.Errorf("parse event: %w", err)
+	}
+	if err := checkEnvelope(&env); err != nil {
+		return nil, err
+	}
+	ev := streaming.NewEvent(env.Type)
+	if ev == nil {
+		return &Unknown{Envelope: env, Raw: append(json.RawMessage(nil), line...)}, nil
+	}
+	if err := json.Unmarshal(line, ev); err != nil {
+		return nil, fmt.Errorf("parse %s event %d: %w", env.Type, env.Sequence, err)
+	}
+	if err := Validate(ev); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+func checkEnvelope(env *Envelope) error {
+	if env.Type == "" {
+		return errors.New("event has no type")
+	}
+	if env.SchemaVersion == "" {
+		return fmt.Errorf("%s event has no schema_version (stream predates schema %s)", env.Type, SchemaVersion)
+	}
+	if major(env.SchemaVersion) != major(SchemaVersion) {
+		return fmt.Errorf("%s event has schema_version %s; this client reads %s.x", env.Type, env.SchemaVersion, major(SchemaVersion))
+	}
+	if env.Sequence < 1 {
+		return fmt.Errorf("%s event has sequence %d", env.Type, env.Sequence)
+	}
+	if env.ThreadID == "" {
+		return fmt.Errorf("%s event %d has no thread_id", env.Type, env.Sequence)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, env.Timestamp); err != nil {
+		return fmt.Errorf("%s event %d has timestamp %q: %w", env.Type, env.Sequence, env.Timestamp, err)
+	}
+	return nil
+}
+
+func major(version string) string {
+	m, _, _ := strings.Cut(version, ".")
+	return m
+}
+
+// Validate checks the fields the schema requires of ev beyond its type.
+func Validate(ev Event) error {
+	var problem string
+	switch ev := ev.(type) {
+	case *ItemStarted:
+		if ev.ItemID == "" {
+			problem = "item_id is empty"
+		}
+	case *ItemCompleted:
+		switch {
+		case ev.ItemID == "":
+			problem = "item_id is empty"
+		case ev.DurationMS < 0:
+			problem = "duration_ms is negative"
+		}
+	case *ThreadCompleted:
+		switch {
+		case ev.Status == "":
+			problem = "status is empty"
+		case len(ev.FinalReport) > 0 && !bytes.HasPrefix(bytes.TrimSpace(ev.FinalReport), []byte("{")):
+			problem = "final_report is not an object"
+		}
+	case *Error:
+		if ev.Scope == "" {
+			problem = "scope is empty"
+		}
+	}
+	if problem != "" {
+		h := ev.Header()
+		return fmt.Errorf("%s event %d: %s", h.Type, h.Sequence, problem)
+	}
+	return nil
+}
+
+// Reader reads events from an NDJSON stream.
+type Reader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// NewReader returns a Reader for r. Lines may be of any length.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next event, skipping blank lines, and io.EOF at the end
+// of the stream. Errors name the offending line.
+func (r *Reader) Next() (Event, error) {
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return nil, err
+		}
+		r.line++
+		if line = bytes.TrimSpace(line); len(line) == 0 {
+			continue
+		}
+		ev, perr := Parse(line)
+		if perr != nil {
+			return nil, fmt.Errorf("line %d: %w", r.line, perr)
+		}
+		return ev, nil
+	}
+}
@@ -0,0 +1,107 @@
+package streamclient
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"verify_agent/internal/streaming"
+	"verify_agent/internal/verify"
+)
+
+func TestConsumeDispatchesTypedEventsAndVerifyResult(t *testing.T) {
+	var buf bytes.Buffer
+	s := streaming.NewJSONStreamer(true, &buf)
+	s.SetAgent(streaming.AgentVerify)
+	s.EmitThreadStarted("bug", "proj", "parent", true)
+	s.EmitItemStarted("item_1", "workflow", "task1", nil)
+	s.EmitItemCompleted("item_1", "success", time.Second, "b1", "VALID")
+	s.EmitError("task2", "timeout", map[string]any{"item_id": "item_2"})
+	verify.EmitCompleted(s, &verify.Result{
+		BugDescription:   "bug",
+		Status:           "bug_confirmed",
+		Summary:          "confirmed",
+		StartBranchID:    "parent",
+		LatestBranchID:   "b3",
+		Task3Result:      &verify.Task3Result{BranchID: "b3", Status: "BUG_CONFIRMED"},
+		RealBugRun:       &verify.Result{Status: "bug_confirmed", LatestBranchID: "b3"},
+		FalsePositiveRun: &verify.Result{Status: "cannot_disprove"},
+	})
+	buf.WriteString("\n" + `{"type":"plan.updated","schema_version":"1.1","timestamp":"2026-01-02T03:04:05Z","sequence":6,"thread_id":"t"}` + "\n")
+
+	var got []string
+	var report *VerifyReport
+	h := &Handler{
+		OnThreadStarted: func(ev *ThreadStarted) { got = append(got, ev.Type+":"+ev.Task) },
+		OnItemCompleted: func(ev *ItemCompleted) { got = append(got, ev.Type+":"+ev.BranchID) },
+		OnError:         func(ev *Error) { got = append(got, ev.Type+":"+ev.ItemID) },
+		OnThreadCompleted: func(ev *ThreadCompleted) {
+			got = append(got, ev.Type+":"+ev.Agent)
+		},
+		OnVerifyResult: func(_ *ThreadCompleted, r *VerifyReport) { report = r },
+		OnReviewResult: func(*ThreadCompleted, *ReviewReport) { t.Errorf("a verify run must not dispatch a review result") },
+		OnUnknown:      func(ev *Unknown) { got = append(got, ev.Type+":unknown") },
+	}
+	if err := Consume(&buf, h); err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	want := "thread.started:bug item.completed:b1 error:item_2 thread.completed:verify-agent plan.updated:unknown"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("dispatched %v, want %s", got, want)
+	}
+	if report == nil || report.Status != "bug_confirmed" || report.Task3Result == nil || report.Task3Result.BranchID != "b3" {
+		t.Fatalf("unexpected verify report %+v", report)
+	}
+	if report.StartBranchID != "parent" || report.LatestBranchID != "b3" {
+		t.Fatalf("branch range not streamed: %+v", report)
+	}
+	if report.RealBugRun == nil || report.RealBugRun.LatestBranchID != "b3" || report.FalsePositiveRun == nil || report.FalsePositiveRun.Status != "cannot_disprove" {
+		t.Fatalf("reconciled runs not streamed: %+v", report)
+	}
+}
+
+func TestDispatchDecodesReviewResult(t *testing.T) {
+	var buf bytes.Buffer
+	s := streaming.NewJSONStreamer(true, &buf)
+	s.SetAgent(streaming.AgentReview)
+	s.EmitThreadCompleted("completed", "1 issue", map[string]any{
+		"status":        "issues_found",
+		"issues":        []map[string]any{{"issue_text": "nil deref", "status": "confirmed", "priority": "P1"}},
+		"github_review": map[string]any{"pull_request": "o/r#1", "created": 1},
+	})
+	ev, err := NewReader(&buf).Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	var report *ReviewReport
+	if err := (&Handler{OnReviewResult: func(_ *ThreadCompleted, r *ReviewReport) { report = r }}).Dispatch(ev); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if report == nil || len(report.Issues) != 1 || report.Issues[0].Priority != "P1" || report.GitHubReview.Created != 1 {
+		t.Fatalf("unexpected review report %+v", report)
+	}
+}
+
+func TestParseRejectsInvalidEvents(t *testing.T) {
+	const env = `"schema_version":"1.0","timestamp":"2026-01-02T03:04:05Z","sequence":3,"thread_id":"t"`
+	for name, tc := range map[string]struct{ line, want string }{
+		"unversioned":   {`{"type":"thread.started","timestamp":"2026-01-02T03:04:05Z","sequence":1,"thread_id":"t"}`, "no schema_version"},
+		"major version": {`{"type":"error","schema_version":"2.0","timestamp":"2026-01-02T03:04:05Z","sequence":1,"thread_id":"t"}`, "this client reads 1.x"},
+		"no sequence":   {`{"type":"error","schema_version":"1.0","timestamp":"2026-01-02T03:04:05Z","thread_id":"t"}`, "sequence 0"},
+		"bad timestamp": {`{"type":"error","schema_version":"1.0","timestamp":"yesterday","sequence":1,"thread_id":"t"}`, "timestamp"},
+		"wrong type":    {`{"type":"turn.started",` + env + `,"iteration":"one"}`, "parse turn.started event 3"},
+		"no item id":    {`{"type":"item.completed",` + env + `,"status":"success","duration_ms":1}`, "item.completed event 3: item_id is empty"},
+		"no status":     {`{"type":"thread.completed",` + env + `}`, "status is empty"},
+		"report array":  {`{"type":"thread.completed",` + env + `,"status":"completed","final_report":[1]}`, "final_report is not an object"},
+	} {
+		if _, err := Parse([]byte(tc.line)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got %v, want an error containing %q", name, err, tc.want)
+		}
+	}
+
+	_, err := NewReader(strings.NewReader("\n{\"type\":\"error\"}\n")).Next()
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2: ") {
+		t.Fatalf("reader errors must name the line, got %v", err)
+	}
+}
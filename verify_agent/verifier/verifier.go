@@ -0,0 +1,138 @@
+// Package verifier exposes the formal bug verification workflow (formalize →
+// reachability → test generation) as a library, so other agents can verify a
+// bug claim without shelling out to verify-agent.
+package verifier
+
+import (
+	"errors"
+	"strings"
+
+	b "verify_agent/internal/brain"
+	"verify_agent/internal/config"
+	"verify_agent/internal/logx"
+	"verify_agent/internal/skills"
+	"verify_agent/internal/tools"
+	"verify_agent/internal/verify"
+)
+
+// Final statuses of a verification.
+const (
+	StatusBugConfirmed   = "bug_confirmed"
+	StatusBugWrong       = "bug_wrong"
+	StatusCannotDisprove = "cannot_disprove"
+	// StatusProtocolError means a task left no valid result file, even
+	// after a repair branch.
+	StatusProtocolError = "protocol_error"
+	StatusError         = "error"
+)
+
+// Task 3 statuses: whether the generated test reproduced the bug.
+const (
+	TestBugConfirmed = "BUG_CONFIRMED"
+	TestBugRefuted   = "BUG_REFUTED"
+	TestInconclusive = "TEST_INCONCLUSIVE"
+)
+
+// Config holds the Azure OpenAI and MCP settings of the workflow.
+type Config = config.AgentConfig
+
+// Result is the outcome of one verification, as printed by verify-agent.
+type Result = verify.Result
+
+// ConfigFromEnv reads Config from the same environment as verify-agent.
+func ConfigFromEnv() (Config, error) { return config.FromEnv() }
+
+// Quiet limits the workflow's logging to errors. Callers that stream NDJSON
+// on stdout must call it, since the workflow logs to stdout.
+func Quiet() { logx.SetLevel(logx.Error) }
+
+// Request describes one bug claim to verify.
+type Request struct {
+	Bug            string
+	ProjectName    string
+	ParentBranchID string
+	CodeContext    string
+	// IsFalsePositive makes the workflow try to refute the claim instead of
+	// confirming it.
+	IsFalsePositive bool
+	// Hypothesis is real, false-positive or both; empty derives it from
+	// IsFalsePositive.
+	Hypothesis string
+}
+
+// Verifier runs verifications against one MCP endpoint. It is safe for
+// concurrent use: every Verify builds its own tool handler.
+type Verifier struct {
+	conf          Config
+	explorationID string
+	agents        *tools.AgentRegistry
+
+	skills     []skills.Skill
+	skillNames []string
+}
+
+// New loads the agent registry named by conf and returns a Verifier.
+func New(conf Config, explorationID string) (*Verifier, error) {
+	agents, err := tools.LoadAgentRegistry(conf.AgentRegistryFile)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{conf: conf, explorationID: explorationID, agents: agents}, nil
+}
+
+// UseSkills enables the named skill packs found in dir (see verify-agent
+// --skills) for every later Verify.
+func (v *Verifier) UseSkills(dir string, names []string) error {
+	if len(names) == 0 {
+		v.skills, v.skillNames = nil, nil
+		return nil
+	}
+	if strings.TrimSpace(dir) == "" {
+		dir = skills.DefaultDir
+	}
+	available, err := skills.Discover(dir)
+	if err != nil {
+		return err
+	}
+	if _, err := skills.Select(available, names); err != nil {
+		return err
+	}
+	v.skills, v.skillNames = available, names
+	return nil
+}
+
+// Verify runs the three-task workflow on req, forking from req.ParentBranchID.
+func (v *Verifier) Verify(req Request) (*Result, error) {
+	if v == nil {
+		return nil, errors.New("verifier is nil")
+	}
+	conf := v.conf
+	if project := strings.TrimSpace(req.ProjectName); project != "" {
+		conf.ProjectName = project
+	}
+	// Each verification records only the skills its own tasks used.
+	var skillSet *skills.Set
+	if len(v.skillNames) > 0 {
+		var err error
+		if skillSet, err = skills.Select(v.skills, v.skillNames); err != nil {
+			return nil, err
+		}
+	}
+	brain := b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
+	handler := tools.NewToolHandlerWithConfig(tools.NewMCPClient(conf.MCPBaseURL, v.explorationID), &conf, req.ParentBranchID)
+	handler.SetAgentRegistry(v.agents)
+	runner, err := verify.NewRunner(brain, handler, nil, verify.Options{
+		BugDescription:  req.Bug,
+		ProjectName:     conf.ProjectName,
+		ParentBranchID:  req.ParentBranchID,
+		WorkspaceDir:    conf.WorkspaceDir,
+		CodeContext:     strings.TrimSpace(req.CodeContext),
+		IsFalsePositive: req.IsFalsePositive,
+		Hypothesis:      req.Hypothesis,
+		Skills:          skillSet,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runner.Run()
+}